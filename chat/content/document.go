@@ -0,0 +1,9 @@
+package content
+
+// Document представляет файл для скачивания (например, отчет в PDF).
+type Document struct {
+	Name    string // Имя файла с расширением.
+	MIME    string // Тип содержимого (например, application/pdf).
+	Data    []byte // Содержимое файла.
+	Caption string // Подпись (необязательно).
+}
@@ -0,0 +1,16 @@
+package content
+
+// Keyboard представляет клавиатуру с готовыми ответами. Нажатие кнопки
+// отправляет ее текст как обычное сообщение пользователя.
+type Keyboard struct {
+	Header      string     // Текст сообщения, транспорт подставит заглушку, если он пуст.
+	Buttons     [][]string // Строки кнопок.
+	OneTime     bool       // Скрыть клавиатуру после нажатия кнопки.
+	Resize      bool       // Подогнать высоту клавиатуры под количество кнопок.
+	Placeholder string     // Подсказка в поле ввода.
+}
+
+// RemoveKeyboard убирает клавиатуру с готовыми ответами.
+type RemoveKeyboard struct {
+	Text string // Текст сообщения, транспорт подставит заглушку, если он пуст.
+}
@@ -0,0 +1,8 @@
+package content
+
+// Notification представляет срабатывание задания планировщика. Приходит
+// обработчику вместо сообщения пользователя.
+type Notification struct {
+	Kind string // Вид уведомления (см. chat.JobKind).
+	Data string // Данные задания (например, код анализа).
+}
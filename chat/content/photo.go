@@ -0,0 +1,8 @@
+package content
+
+// Photo представляет фотографию, присланную пользователем.
+type Photo struct {
+	Data    []byte // Изображение.
+	MIME    string // Тип содержимого (например, image/jpeg).
+	Caption string // Подпись (необязательно).
+}
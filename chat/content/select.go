@@ -4,10 +4,12 @@ package content
 type SelectItem struct {
 	Caption string // Название.
 	Data    string // Данные которые передаются в обработчик.
+	URL     string // Ссылка; если задана, кнопка открывает её и не передает данные в обработчик.
 }
 
 // Select представляет список отобржаемых элементов.
 type Select struct {
-	Header string       // Заголовок.
-	Items  []SelectItem // Элементы списка.
+	Header  string       // Заголовок.
+	Items   []SelectItem // Элементы списка.
+	Columns int          // Количество элементов в строке (по умолчанию один).
 }
@@ -0,0 +1,7 @@
+package content
+
+// Voice представляет голосовое сообщение.
+type Voice struct {
+	Data    []byte // Аудио в формате OGG/Opus.
+	Caption string // Подпись (необязательно).
+}
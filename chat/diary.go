@@ -0,0 +1,101 @@
+package chat
+
+import (
+	"slices"
+	"time"
+)
+
+// DiaryDateLayout - формат даты дня в дневнике питания.
+const DiaryDateLayout = "2006-01-02"
+
+// FoodTag - особенность состава продукта, для которой генетический анализ
+// может давать рекомендации.
+type FoodTag string
+
+// Особенности состава продуктов.
+const (
+	FoodLactose      FoodTag = "lactose"
+	FoodCaffeine     FoodTag = "caffeine"
+	FoodSaturatedFat FoodTag = "saturated_fat"
+	FoodGluten       FoodTag = "gluten"
+	FoodSugar        FoodTag = "sugar"
+	FoodSalt         FoodTag = "salt"
+	FoodAlcohol      FoodTag = "alcohol"
+)
+
+// FoodTags перечисляет особенности состава, которые отмечает ИИ.
+var FoodTags = []FoodTag{
+	FoodLactose,
+	FoodCaffeine,
+	FoodSaturatedFat,
+	FoodGluten,
+	FoodSugar,
+	FoodSalt,
+	FoodAlcohol,
+}
+
+// FoodItem - продукт или блюдо, распознанное ИИ в описании приема пищи.
+type FoodItem struct {
+	Name     string    // Название.
+	Amount   string    // Количество в свободной форме (например, "200 г").
+	Calories int       // Оценка калорийности, ккал.
+	Tags     []FoodTag // Особенности состава.
+}
+
+// Meal - прием пищи в дневнике.
+type Meal struct {
+	Time     time.Time  // Время записи.
+	Text     string     // Описание пользователя или подпись к фото.
+	Photo    bool       // Прием пищи записан по фотографии.
+	Items    []FoodItem // Распознанные продукты.
+	Feedback string     // Комментарий ИИ с учетом генетического анализа.
+}
+
+// Diary - дневник питания за один день.
+type Diary struct {
+	Date  string // День в формате DiaryDateLayout.
+	Meals []Meal
+}
+
+// DiaryDate возвращает день дневника, к которому относится время t.
+func DiaryDate(t time.Time) string {
+	return t.Format(DiaryDateLayout)
+}
+
+// Add добавляет прием пищи и возвращает новый дневник.
+func (d Diary) Add(meal Meal) Diary {
+	d.Meals = append(slices.Clone(d.Meals), meal)
+	return d
+}
+
+// IsEmpty проверяет, есть ли в дневнике записи.
+func (d Diary) IsEmpty() bool {
+	return len(d.Meals) == 0
+}
+
+// Calories возвращает оценку калорийности всех приемов пищи за день.
+func (d Diary) Calories() int {
+	var total int
+	for _, meal := range d.Meals {
+		for _, item := range meal.Items {
+			total += item.Calories
+		}
+	}
+
+	return total
+}
+
+// TagCounts подсчитывает, сколько продуктов с каждой особенностью состава
+// съедено за день.
+func (d Diary) TagCounts() map[FoodTag]int {
+	counts := make(map[FoodTag]int)
+	for _, meal := range d.Meals {
+		for _, item := range meal.Items {
+			for _, tag := range item.Tags {
+				counts[tag]++
+			}
+		}
+	}
+
+	return counts
+}
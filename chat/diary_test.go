@@ -0,0 +1,44 @@
+package chat
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestDiary(t *testing.T) {
+	var diary Diary
+
+	breakfast := Meal{
+		Text: "Каша на молоке и кофе",
+		Items: []FoodItem{
+			{Name: "Овсяная каша на молоке", Amount: "250 г", Calories: 250, Tags: []FoodTag{FoodLactose}},
+			{Name: "Кофе с молоком", Amount: "1 чашка", Calories: 60, Tags: []FoodTag{FoodCaffeine, FoodLactose}},
+		},
+	}
+
+	added := diary.Add(breakfast)
+	if !diary.IsEmpty() {
+		t.Fatalf("Add changed the original diary: %+v", diary)
+	}
+
+	added = added.Add(Meal{Items: []FoodItem{{Name: "Яблоко", Calories: 80}}})
+
+	if calories := added.Calories(); calories != 390 {
+		t.Errorf("Calories = %d, want 390", calories)
+	}
+
+	want := map[FoodTag]int{FoodLactose: 2, FoodCaffeine: 1}
+	if counts := added.TagCounts(); !maps.Equal(counts, want) {
+		t.Errorf("TagCounts = %v, want %v", counts, want)
+	}
+}
+
+func TestDiaryDate(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	// The day is taken in the time zone of t.
+	if date := DiaryDate(time.Date(2025, 3, 1, 23, 30, 0, 0, time.UTC).In(moscow)); date != "2025-03-02" {
+		t.Errorf("DiaryDate = %s, want 2025-03-02", date)
+	}
+}
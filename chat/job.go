@@ -0,0 +1,182 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JobKind определяет вид уведомления, которое отправляет планировщик.
+type JobKind string
+
+// Виды уведомлений.
+const (
+	JobReminder JobKind = "reminder" // Напоминание о невыполненных пунктах плана.
+	JobCodelab  JobKind = "codelab"  // Новые результаты анализов (см. Job.Data).
+	JobTip      JobKind = "tip"      // Еженедельный совет по результатам анализа.
+)
+
+// JobKinds перечисляет виды уведомлений, от которых можно отказаться.
+var JobKinds = []JobKind{JobReminder, JobCodelab, JobTip}
+
+// Job - повторяющееся задание планировщика с расписанием в формате cron.
+//
+// Задание JobCodelab проверяет, появились ли у пользователя новые анализы
+// или изменился ли их статус.
+type Job struct {
+	ID        string    // Идентификатор задания, см. JobID.
+	ChatID    int64     // Чат, в который отправляется уведомление.
+	UserID    int64     // Пользователь, настройки которого учитываются.
+	Kind      JobKind   // Вид уведомления.
+	Transport string    // Транспорт чата, через который доставляется уведомление.
+	Spec      string    // Расписание cron.
+	Data      string    // Данные уведомления, передаются обработчику.
+	Next      time.Time // Время следующего выполнения.
+	Last      time.Time // Время последнего выполнения.
+}
+
+// JobID возвращает идентификатор задания. У чата есть не больше одного
+// задания каждого вида с одними данными.
+func JobID(chatID int64, kind JobKind, data string) string {
+	if data == "" {
+		return fmt.Sprintf("%d:%s", chatID, kind)
+	}
+
+	return fmt.Sprintf("%d:%s:%s", chatID, kind, data)
+}
+
+// Notifications - настройки уведомлений пользователя.
+type Notifications struct {
+	Disabled   []JobKind  // Виды уведомлений, от которых пользователь отказался.
+	QuietHours QuietHours // Время, когда уведомления откладываются.
+
+	// TimeZone - часовой пояс тихих часов в виде "UTC+3" (см. ParseTimeZone).
+	// Пустое значение соответствует часовому поясу планировщика.
+	TimeZone string
+}
+
+// ErrInvalidTimeZone возвращает ParseTimeZone для неверного часового пояса.
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// ParseTimeZone возвращает часовой пояс со смещением от UTC вида "UTC",
+// "UTC+3" или "UTC-3:30". Смещение может быть от -12 до +14 часов.
+func ParseTimeZone(s string) (*time.Location, error) {
+	offset, ok := strings.CutPrefix(strings.TrimSpace(s), "UTC")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimeZone, s)
+	}
+	if offset == "" {
+		return time.UTC, nil
+	}
+
+	sign := 1
+	switch offset[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimeZone, s)
+	}
+
+	hours, minutes, hasMinutes := strings.Cut(offset[1:], ":")
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimeZone, s)
+	}
+
+	m := 0
+	if hasMinutes {
+		if m, err = strconv.Atoi(minutes); err != nil || m < 0 || m > 59 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTimeZone, s)
+		}
+	}
+
+	seconds := sign * (h*3600 + m*60)
+	if h < 0 || seconds < -12*3600 || seconds > 14*3600 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimeZone, s)
+	}
+
+	return time.FixedZone(s, seconds), nil
+}
+
+// Location возвращает часовой пояс пользователя, def, если он не задан
+// или неверен.
+func (n Notifications) Location(def *time.Location) *time.Location {
+	if n.TimeZone == "" {
+		return def
+	}
+
+	loc, err := ParseTimeZone(n.TimeZone)
+	if err != nil {
+		return def
+	}
+
+	return loc
+}
+
+// Enabled проверяет, получает ли пользователь уведомления вида kind.
+func (n Notifications) Enabled(kind JobKind) bool {
+	return !slices.Contains(n.Disabled, kind)
+}
+
+// Toggle включает или отключает уведомления вида kind.
+func (n Notifications) Toggle(kind JobKind) Notifications {
+	if n.Enabled(kind) {
+		n.Disabled = append(slices.Clone(n.Disabled), kind)
+	} else {
+		n.Disabled = slices.DeleteFunc(slices.Clone(n.Disabled), func(k JobKind) bool {
+			return k == kind
+		})
+	}
+
+	return n
+}
+
+// QuietHours - интервал часов [From, To), в который уведомления не
+// отправляются. Интервал может переходить через полночь (например, с 22 до 8).
+// Одинаковые From и To означают, что тихих часов нет.
+type QuietHours struct {
+	From int
+	To   int
+}
+
+// IsEmpty проверяет, заданы ли тихие часы.
+func (q QuietHours) IsEmpty() bool {
+	return q.From == q.To
+}
+
+// Contains проверяет, попадает ли время t в тихие часы.
+func (q QuietHours) Contains(t time.Time) bool {
+	h := t.Hour()
+	switch {
+	case q.IsEmpty():
+		return false
+	case q.From < q.To:
+		return h >= q.From && h < q.To
+	default:
+		return h >= q.From || h < q.To
+	}
+}
+
+// End возвращает ближайшее окончание тихих часов после t. Если t не попадает
+// в тихие часы, возвращает t.
+func (q QuietHours) End(t time.Time) time.Time {
+	if !q.Contains(t) {
+		return t
+	}
+
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.To, 0, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return end
+}
+
+// String возвращает тихие часы в виде "22:00–08:00".
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:00–%02d:00", q.From, q.To)
+}
@@ -0,0 +1,81 @@
+package chat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuietHours(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2025, time.March, 12, hour, 30, 0, 0, time.UTC)
+	}
+
+	night := QuietHours{From: 22, To: 8}
+	for hour, want := range map[int]bool{21: false, 22: true, 23: true, 0: true, 7: true, 8: false, 12: false} {
+		if got := night.Contains(at(hour)); got != want {
+			t.Errorf("%v.Contains(%02d:30) = %v, want %v", night, hour, got, want)
+		}
+	}
+
+	if got, want := night.End(at(23)), time.Date(2025, time.March, 13, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("End(23:30) = %v, want %v", got, want)
+	}
+	if got, want := night.End(at(3)), time.Date(2025, time.March, 12, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("End(03:30) = %v, want %v", got, want)
+	}
+	if got := night.End(at(12)); !got.Equal(at(12)) {
+		t.Errorf("End(12:30) = %v, want the same time", got)
+	}
+
+	if (QuietHours{}).Contains(at(3)) {
+		t.Errorf("empty quiet hours contain 03:30")
+	}
+}
+
+func TestNotificationsToggle(t *testing.T) {
+	var n Notifications
+
+	if !n.Enabled(JobTip) {
+		t.Fatalf("notifications are disabled by default")
+	}
+
+	off := n.Toggle(JobTip)
+	if off.Enabled(JobTip) || !off.Enabled(JobReminder) {
+		t.Fatalf("Toggle(tip) = %+v, want only tips disabled", off)
+	}
+
+	if on := off.Toggle(JobTip); !on.Enabled(JobTip) || len(on.Disabled) != 0 {
+		t.Fatalf("Toggle(tip) twice = %+v, want all enabled", on)
+	}
+}
+
+func TestParseTimeZone(t *testing.T) {
+	for s, want := range map[string]int{
+		"UTC":       0,
+		"UTC+3":     3 * 3600,
+		"UTC-3:30":  -(3*3600 + 30*60),
+		"UTC+05:45": 5*3600 + 45*60,
+		"UTC+14":    14 * 3600,
+	} {
+		loc, err := ParseTimeZone(s)
+		if err != nil {
+			t.Errorf("ParseTimeZone(%q) error = %v", s, err)
+			continue
+		}
+
+		if _, offset := time.Date(2025, time.March, 12, 0, 0, 0, 0, loc).Zone(); offset != want {
+			t.Errorf("ParseTimeZone(%q) offset = %d, want %d", s, offset, want)
+		}
+	}
+
+	for _, s := range []string{"", "Europe/Moscow", "UTC3", "UTC+15", "UTC-13", "UTC+3:60", "UTC+-3"} {
+		if _, err := ParseTimeZone(s); !errors.Is(err, ErrInvalidTimeZone) {
+			t.Errorf("ParseTimeZone(%q) error = %v, want %v", s, err, ErrInvalidTimeZone)
+		}
+	}
+
+	if loc := (Notifications{}).Location(time.UTC); loc != time.UTC {
+		t.Errorf("default Location() = %v", loc)
+	}
+}
@@ -0,0 +1,162 @@
+package chat
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Metric определяет показатель самочувствия в журнале.
+type Metric string
+
+// Показатели самочувствия.
+const (
+	MetricSleep   Metric = "sleep"   // Продолжительность сна, часы.
+	MetricEnergy  Metric = "energy"  // Уровень энергии от 1 до 5.
+	MetricMood    Metric = "mood"    // Настроение от 1 до 5.
+	MetricWeight  Metric = "weight"  // Вес, кг.
+	MetricSymptom Metric = "symptom" // Симптом, см. Measurement.Symptom.
+)
+
+// Metrics перечисляет числовые показатели в порядке отметки самочувствия.
+var Metrics = []Metric{MetricSleep, MetricEnergy, MetricMood, MetricWeight}
+
+// Symptoms перечисляет симптомы, которые можно отметить кнопками.
+var Symptoms = []string{
+	"headache",
+	"fatigue",
+	"bloating",
+	"heartburn",
+	"palpitations",
+	"joint_pain",
+}
+
+// maxJournalAge ограничивает срок хранения записей журнала.
+const maxJournalAge = 365 * 24 * time.Hour
+
+// trendThresholds - изменения средних значений, которые считаются
+// заметными.
+var trendThresholds = map[Metric]float64{
+	MetricSleep:  0.5,
+	MetricEnergy: 0.5,
+	MetricMood:   0.5,
+	MetricWeight: 0.5,
+}
+
+// Measurement - запись в журнале самочувствия.
+type Measurement struct {
+	Time    time.Time // Время записи.
+	Metric  Metric    // Показатель.
+	Value   float64   // Значение числового показателя.
+	Symptom string    // Симптом для MetricSymptom.
+}
+
+// Journal - журнал самочувствия пользователя: записи в порядке времени.
+type Journal struct {
+	Measurements []Measurement
+}
+
+// Add добавляет записи и возвращает новый журнал. Записи старше года
+// удаляются.
+func (j Journal) Add(measurements ...Measurement) Journal {
+	result := append(slices.Clone(j.Measurements), measurements...)
+	slices.SortStableFunc(result, func(a, b Measurement) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	if len(result) > 0 {
+		cutoff := result[len(result)-1].Time.Add(-maxJournalAge)
+		result = slices.DeleteFunc(result, func(m Measurement) bool {
+			return m.Time.Before(cutoff)
+		})
+	}
+
+	return Journal{Measurements: result}
+}
+
+// IsEmpty проверяет, есть ли в журнале записи.
+func (j Journal) IsEmpty() bool {
+	return len(j.Measurements) == 0
+}
+
+// Series возвращает записи показателя за интервал [from, to).
+func (j Journal) Series(metric Metric, from, to time.Time) []Measurement {
+	var series []Measurement
+	for _, m := range j.Measurements {
+		if m.Metric == metric && !m.Time.Before(from) && m.Time.Before(to) {
+			series = append(series, m)
+		}
+	}
+
+	return series
+}
+
+// SymptomCounts подсчитывает, сколько раз отмечен каждый симптом за
+// интервал [from, to).
+func (j Journal) SymptomCounts(from, to time.Time) map[string]int {
+	counts := make(map[string]int)
+	for _, m := range j.Series(MetricSymptom, from, to) {
+		counts[m.Symptom]++
+	}
+
+	return counts
+}
+
+// Trend - изменение числового показателя за период по сравнению
+// с предыдущим периодом той же длины.
+type Trend struct {
+	Metric      Metric
+	Count       int     // Количество записей за период.
+	Average     float64 // Среднее значение за период.
+	Last        float64 // Последнее значение за период.
+	Previous    float64 // Среднее значение за предыдущий период.
+	HasPrevious bool    // Есть ли записи за предыдущий период.
+}
+
+// Trend вычисляет изменение показателя за period до now.
+func (j Journal) Trend(metric Metric, now time.Time, period time.Duration) Trend {
+	t := Trend{Metric: metric}
+
+	current := j.Series(metric, now.Add(-period), now.Add(time.Nanosecond))
+	if len(current) == 0 {
+		return t
+	}
+
+	t.Count = len(current)
+	t.Average = average(current)
+	t.Last = current[len(current)-1].Value
+
+	if previous := j.Series(metric, now.Add(-2*period), now.Add(-period)); len(previous) > 0 {
+		t.Previous = average(previous)
+		t.HasPrevious = true
+	}
+
+	return t
+}
+
+// Direction возвращает 1, если показатель заметно вырос, -1, если заметно
+// снизился, и 0, если изменился мало или сравнивать не с чем.
+func (t Trend) Direction() int {
+	if !t.HasPrevious {
+		return 0
+	}
+
+	switch change := t.Average - t.Previous; {
+	case change >= trendThresholds[t.Metric]:
+		return 1
+	case change <= -trendThresholds[t.Metric]:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// average возвращает среднее значение записей, округленное до десятых.
+func average(series []Measurement) float64 {
+	var sum float64
+	for _, m := range series {
+		sum += m.Value
+	}
+
+	return math.Round(sum/float64(len(series))*10) / 10
+}
@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestJournalAdd(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	var journal Journal
+	journal = journal.Add(
+		Measurement{Time: now.AddDate(0, 0, -1), Metric: MetricSleep, Value: 7},
+		Measurement{Time: now.AddDate(-2, 0, 0), Metric: MetricSleep, Value: 8},
+	)
+
+	added := journal.Add(Measurement{Time: now.AddDate(0, 0, -2), Metric: MetricMood, Value: 4})
+	if len(journal.Measurements) != 1 {
+		t.Fatalf("Add changed the original journal: %+v", journal)
+	}
+
+	// Measurements are sorted by time, the ones older than a year are removed.
+	if len(added.Measurements) != 2 || added.Measurements[0].Metric != MetricMood || added.Measurements[1].Metric != MetricSleep {
+		t.Errorf("Measurements = %+v, want mood and sleep", added.Measurements)
+	}
+}
+
+func TestJournalTrend(t *testing.T) {
+	var (
+		now  = time.Date(2025, 3, 15, 9, 0, 0, 0, time.UTC)
+		week = 7 * 24 * time.Hour
+		day  = func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	)
+
+	journal := Journal{}.Add(
+		Measurement{Time: day(10), Metric: MetricSleep, Value: 8},
+		Measurement{Time: day(9), Metric: MetricSleep, Value: 7},
+		Measurement{Time: day(2), Metric: MetricSleep, Value: 6},
+		Measurement{Time: day(0), Metric: MetricSleep, Value: 6.5},
+		Measurement{Time: day(1), Metric: MetricEnergy, Value: 3},
+		Measurement{Time: day(1), Metric: MetricSymptom, Symptom: "fatigue"},
+		Measurement{Time: day(0), Metric: MetricSymptom, Symptom: "fatigue"},
+		Measurement{Time: day(8), Metric: MetricSymptom, Symptom: "headache"},
+	)
+
+	sleep := journal.Trend(MetricSleep, now, week)
+	want := Trend{Metric: MetricSleep, Count: 2, Average: 6.3, Last: 6.5, Previous: 7.5, HasPrevious: true}
+	if sleep != want {
+		t.Errorf("Trend(sleep) = %+v, want %+v", sleep, want)
+	}
+	if d := sleep.Direction(); d != -1 {
+		t.Errorf("Direction(sleep) = %d, want -1", d)
+	}
+
+	// Without the previous period there is nothing to compare with.
+	if d := journal.Trend(MetricEnergy, now, week).Direction(); d != 0 {
+		t.Errorf("Direction(energy) = %d, want 0", d)
+	}
+	if trend := journal.Trend(MetricMood, now, week); trend.Count != 0 {
+		t.Errorf("Trend(mood) = %+v, want no measurements", trend)
+	}
+
+	wantCounts := map[string]int{"fatigue": 2}
+	if counts := journal.SymptomCounts(now.Add(-week), now.Add(time.Nanosecond)); !maps.Equal(counts, wantCounts) {
+		t.Errorf("SymptomCounts = %v, want %v", counts, wantCounts)
+	}
+}
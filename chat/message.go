@@ -16,6 +16,7 @@ type Message struct {
 	Sender    Role
 	Content   any
 	CreatedAt time.Time
+	Score     float64 // Оценка достоверности ответа валидатором (0–1), 0 без проверки.
 }
 
 // IsEmpty проверяет, является ли сообщение пустым.
@@ -0,0 +1,100 @@
+package chat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Task - пункт плана действий, составленный из чеклиста признака.
+type Task struct {
+	ID      string    // Идентификатор пункта, см. TaskID.
+	Codelab string    // Код анализа.
+	Feature string    // Название признака.
+	Text    string    // Текст пункта чеклиста.
+	Done    bool      // Пункт выполнен.
+	DoneAt  time.Time // Время выполнения.
+}
+
+// TaskID возвращает идентификатор пункта, который не меняется при повторной
+// загрузке анализа и помещается в данные кнопки.
+func TaskID(codelab, feature, text string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{codelab, feature, text}, "\x00")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Plan - план действий пользователя.
+type Plan struct {
+	Tasks []Task
+}
+
+// Add добавляет пункты, которых еще нет в плане, и возвращает новый план
+// и количество добавленных пунктов. Идентификаторы пунктов заполняются
+// автоматически.
+func (p Plan) Add(tasks ...Task) (Plan, int) {
+	result := Plan{Tasks: append([]Task{}, p.Tasks...)}
+
+	var added int
+	for _, task := range tasks {
+		task.ID = TaskID(task.Codelab, task.Feature, task.Text)
+		if _, ok := result.Task(task.ID); ok {
+			continue
+		}
+
+		result.Tasks = append(result.Tasks, task)
+		added++
+	}
+
+	return result, added
+}
+
+// Task возвращает пункт плана по идентификатору.
+func (p Plan) Task(id string) (Task, bool) {
+	for _, task := range p.Tasks {
+		if task.ID == id {
+			return task, true
+		}
+	}
+
+	return Task{}, false
+}
+
+// Toggle отмечает пункт выполненным или снимает отметку и возвращает новый
+// план. Если пункта нет, возвращает false.
+func (p Plan) Toggle(id string) (Plan, bool) {
+	result := Plan{Tasks: append([]Task{}, p.Tasks...)}
+
+	for i, task := range result.Tasks {
+		if task.ID != id {
+			continue
+		}
+
+		task.Done = !task.Done
+		task.DoneAt = time.Time{}
+		if task.Done {
+			task.DoneAt = Now().UTC()
+		}
+
+		result.Tasks[i] = task
+		return result, true
+	}
+
+	return p, false
+}
+
+// Progress возвращает количество выполненных и всех пунктов плана.
+func (p Plan) Progress() (done, total int) {
+	for _, task := range p.Tasks {
+		if task.Done {
+			done++
+		}
+	}
+
+	return done, len(p.Tasks)
+}
+
+// IsEmpty проверяет, есть ли в плане пункты.
+func (p Plan) IsEmpty() bool {
+	return len(p.Tasks) == 0
+}
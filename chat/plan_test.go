@@ -0,0 +1,58 @@
+package chat
+
+import "testing"
+
+func TestPlan(t *testing.T) {
+	var plan Plan
+
+	plan, added := plan.Add(
+		Task{Codelab: "WN0000T", Feature: "Кофеин", Text: "Не пить кофе после 14:00"},
+		Task{Codelab: "WN0000T", Feature: "Лактоза", Text: "Выбирать безлактозное молоко"},
+	)
+	if added != 2 {
+		t.Fatalf("Add added %d tasks, want 2", added)
+	}
+
+	// The same checklist item of the same codelab is not added twice.
+	plan, added = plan.Add(
+		Task{Codelab: "WN0000T", Feature: "Кофеин", Text: "Не пить кофе после 14:00"},
+		Task{Codelab: "DX0000T", Feature: "Кофеин", Text: "Не пить кофе после 14:00"},
+	)
+	if added != 1 {
+		t.Fatalf("Add added %d tasks, want 1", added)
+	}
+
+	if done, total := plan.Progress(); done != 0 || total != 3 {
+		t.Fatalf("Progress = %d, %d, want 0, 3", done, total)
+	}
+
+	id := TaskID("WN0000T", "Кофеин", "Не пить кофе после 14:00")
+
+	toggled, ok := plan.Toggle(id)
+	if !ok {
+		t.Fatalf("Toggle(%q) returned false", id)
+	}
+
+	task, _ := toggled.Task(id)
+	if !task.Done || task.DoneAt.IsZero() {
+		t.Fatalf("toggled task = %+v, want done with time", task)
+	}
+
+	if done, _ := toggled.Progress(); done != 1 {
+		t.Fatalf("Progress done = %d, want 1", done)
+	}
+
+	// The original plan is not modified.
+	if task, _ := plan.Task(id); task.Done {
+		t.Fatalf("Toggle modified the original plan")
+	}
+
+	toggled, _ = toggled.Toggle(id)
+	if task, _ := toggled.Task(id); task.Done || !task.DoneAt.IsZero() {
+		t.Fatalf("task toggled twice = %+v, want not done", task)
+	}
+
+	if _, ok := plan.Toggle("unknown"); ok {
+		t.Fatalf("Toggle of an unknown task returned true")
+	}
+}
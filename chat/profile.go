@@ -0,0 +1,245 @@
+package chat
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidProfileValue возвращается, если ответ на вопрос анкеты не прошел
+// проверку.
+var ErrInvalidProfileValue = errors.New("invalid profile value")
+
+// Sex определяет пол пользователя.
+type Sex string
+
+// Варианты пола.
+const (
+	SexMale   Sex = "male"
+	SexFemale Sex = "female"
+)
+
+// Activity определяет уровень физической активности.
+type Activity string
+
+// Уровни физической активности.
+const (
+	ActivityLow      Activity = "low"
+	ActivityModerate Activity = "moderate"
+	ActivityHigh     Activity = "high"
+)
+
+// Diet определяет тип питания.
+type Diet string
+
+// Типы питания.
+const (
+	DietOmnivore   Diet = "omnivore"
+	DietVegetarian Diet = "vegetarian"
+	DietVegan      Diet = "vegan"
+	DietOther      Diet = "other"
+)
+
+// Profile - анкета пользователя, которую ИИ учитывает в рекомендациях.
+// Нулевые значения означают, что пользователь не ответил на вопрос.
+type Profile struct {
+	Age        int      // Возраст, лет.
+	Sex        Sex      // Пол.
+	Height     int      // Рост, см.
+	Weight     float64  // Вес, кг.
+	Activity   Activity // Уровень физической активности.
+	Diet       Diet     // Тип питания.
+	Allergies  string   // Аллергии и непереносимости.
+	Goals      string   // Цели (например, снизить вес).
+	Conditions string   // Хронические заболевания.
+}
+
+// ProfileField определяет вопрос анкеты.
+type ProfileField string
+
+// Вопросы анкеты.
+const (
+	FieldAge        ProfileField = "age"
+	FieldSex        ProfileField = "sex"
+	FieldHeight     ProfileField = "height"
+	FieldWeight     ProfileField = "weight"
+	FieldActivity   ProfileField = "activity"
+	FieldDiet       ProfileField = "diet"
+	FieldAllergies  ProfileField = "allergies"
+	FieldGoals      ProfileField = "goals"
+	FieldConditions ProfileField = "conditions"
+)
+
+// ProfileFields перечисляет вопросы анкеты в порядке, в котором они задаются.
+var ProfileFields = []ProfileField{
+	FieldAge,
+	FieldSex,
+	FieldHeight,
+	FieldWeight,
+	FieldActivity,
+	FieldDiet,
+	FieldAllergies,
+	FieldGoals,
+	FieldConditions,
+}
+
+// maxProfileText ограничивает длину ответов в свободной форме.
+const maxProfileText = 500
+
+// profileNumber выделяет число в начале ответа, например "72,5 кг".
+var profileNumber = regexp.MustCompile(`^\d+(?:[.,]\d+)?`)
+
+// Options возвращает допустимые значения вопроса с выбором ответа, nil
+// для вопросов со свободным ответом.
+func (f ProfileField) Options() []string {
+	switch f {
+	case FieldSex:
+		return []string{string(SexMale), string(SexFemale)}
+	case FieldActivity:
+		return []string{string(ActivityLow), string(ActivityModerate), string(ActivityHigh)}
+	case FieldDiet:
+		return []string{string(DietOmnivore), string(DietVegetarian), string(DietVegan), string(DietOther)}
+	default:
+		return nil
+	}
+}
+
+// IsNumeric проверяет, ожидается ли в ответе число.
+func (f ProfileField) IsNumeric() bool {
+	return f == FieldAge || f == FieldHeight || f == FieldWeight
+}
+
+// Set проверяет ответ на вопрос анкеты и возвращает обновленную анкету.
+// Пустой ответ сбрасывает значение.
+func (p Profile) Set(field ProfileField, value string) (Profile, error) {
+	value = strings.TrimSpace(value)
+
+	var number float64
+	if value != "" && field.IsNumeric() {
+		n, err := strconv.ParseFloat(strings.ReplaceAll(profileNumber.FindString(value), ",", "."), 64)
+		if err != nil {
+			return p, ErrInvalidProfileValue
+		}
+		number = n
+	}
+
+	if options := field.Options(); value != "" && options != nil && !slices.Contains(options, value) {
+		return p, ErrInvalidProfileValue
+	}
+
+	if value != "" && !field.IsNumeric() && field.Options() == nil && utf8.RuneCountInString(value) > maxProfileText {
+		return p, ErrInvalidProfileValue
+	}
+
+	switch field {
+	case FieldAge:
+		if value != "" && (number < 1 || number > 120 || number != math.Trunc(number)) {
+			return p, ErrInvalidProfileValue
+		}
+		p.Age = int(number)
+
+	case FieldSex:
+		p.Sex = Sex(value)
+
+	case FieldHeight:
+		if value != "" && (number < 50 || number > 250) {
+			return p, ErrInvalidProfileValue
+		}
+		p.Height = int(math.Round(number))
+
+	case FieldWeight:
+		if value == "" {
+			p.Weight = 0
+			break
+		}
+
+		weight, err := ParseWeight(value)
+		if err != nil {
+			return p, err
+		}
+		p.Weight = weight
+
+	case FieldActivity:
+		p.Activity = Activity(value)
+
+	case FieldDiet:
+		p.Diet = Diet(value)
+
+	case FieldAllergies:
+		p.Allergies = value
+
+	case FieldGoals:
+		p.Goals = value
+
+	case FieldConditions:
+		p.Conditions = value
+
+	default:
+		return p, ErrInvalidProfileValue
+	}
+
+	return p, nil
+}
+
+// ParseWeight разбирает вес в килограммах (например, "72,5 кг") и округляет
+// его до десятых.
+func ParseWeight(s string) (float64, error) {
+	weight, err := strconv.ParseFloat(strings.ReplaceAll(profileNumber.FindString(strings.TrimSpace(s)), ",", "."), 64)
+	if err != nil || weight < 20 || weight > 350 {
+		return 0, ErrInvalidProfileValue
+	}
+
+	return math.Round(weight*10) / 10, nil
+}
+
+// Value возвращает ответ на вопрос анкеты, пустую строку, если ответа нет.
+// Для вопросов с выбором возвращается одно из значений Options.
+func (p Profile) Value(field ProfileField) string {
+	switch field {
+	case FieldAge:
+		if p.Age > 0 {
+			return strconv.Itoa(p.Age)
+		}
+	case FieldSex:
+		return string(p.Sex)
+	case FieldHeight:
+		if p.Height > 0 {
+			return strconv.Itoa(p.Height)
+		}
+	case FieldWeight:
+		if p.Weight > 0 {
+			return strconv.FormatFloat(p.Weight, 'f', -1, 64)
+		}
+	case FieldActivity:
+		return string(p.Activity)
+	case FieldDiet:
+		return string(p.Diet)
+	case FieldAllergies:
+		return p.Allergies
+	case FieldGoals:
+		return p.Goals
+	case FieldConditions:
+		return p.Conditions
+	}
+
+	return ""
+}
+
+// BMI возвращает индекс массы тела, 0 без роста или веса.
+func (p Profile) BMI() float64 {
+	if p.Height == 0 || p.Weight == 0 {
+		return 0
+	}
+
+	m := float64(p.Height) / 100
+	return math.Round(p.Weight/(m*m)*10) / 10
+}
+
+// IsEmpty проверяет, ответил ли пользователь хотя бы на один вопрос.
+func (p Profile) IsEmpty() bool {
+	return p == Profile{}
+}
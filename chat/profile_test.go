@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProfileSet(t *testing.T) {
+	tests := []struct {
+		field ProfileField
+		value string
+		want  string
+	}{
+		{FieldAge, "35", "35"},
+		{FieldAge, " 120 ", "120"},
+		{FieldHeight, "165 см", "165"},
+		{FieldHeight, "180.4", "180"},
+		{FieldWeight, "58,5", "58.5"},
+		{FieldWeight, "72.46 kg", "72.5"},
+		{FieldSex, "female", "female"},
+		{FieldActivity, "moderate", "moderate"},
+		{FieldDiet, "vegan", "vegan"},
+		{FieldAllergies, " лактоза ", "лактоза"},
+		{FieldGoals, "", ""},
+	}
+
+	for _, tt := range tests {
+		p, err := Profile{}.Set(tt.field, tt.value)
+		if err != nil {
+			t.Errorf("Set(%s, %q) error: %v", tt.field, tt.value, err)
+			continue
+		}
+
+		if got := p.Value(tt.field); got != tt.want {
+			t.Errorf("Set(%s, %q) = %q, want %q", tt.field, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestProfileSetInvalid(t *testing.T) {
+	tests := []struct {
+		field ProfileField
+		value string
+	}{
+		{FieldAge, "0"},
+		{FieldAge, "121"},
+		{FieldAge, "35.5"},
+		{FieldAge, "тридцать"},
+		{FieldHeight, "49"},
+		{FieldHeight, "2.5 м"},
+		{FieldWeight, "351"},
+		{FieldSex, "unknown"},
+		{FieldDiet, "Веганское"},
+		{FieldConditions, strings.Repeat("а", maxProfileText+1)},
+		{"unknown", "1"},
+	}
+
+	p := Profile{Age: 40, Height: 170}
+	for _, tt := range tests {
+		got, err := p.Set(tt.field, tt.value)
+		if !errors.Is(err, ErrInvalidProfileValue) {
+			t.Errorf("Set(%s, %q) error = %v, want ErrInvalidProfileValue", tt.field, tt.value, err)
+		}
+		if got != p {
+			t.Errorf("Set(%s, %q) changed the profile to %+v", tt.field, tt.value, got)
+		}
+	}
+}
+
+func TestProfileReset(t *testing.T) {
+	p, err := Profile{Age: 35, Allergies: "лактоза"}.Set(FieldAge, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Age != 0 || p.Allergies != "лактоза" {
+		t.Fatalf("profile after reset = %+v, want only allergies", p)
+	}
+
+	if p, _ = p.Set(FieldAllergies, ""); !p.IsEmpty() {
+		t.Fatalf("profile = %+v, want empty", p)
+	}
+}
+
+func TestProfileBMI(t *testing.T) {
+	if bmi := (Profile{Height: 165, Weight: 58.5}).BMI(); bmi != 21.5 {
+		t.Fatalf("BMI = %v, want 21.5", bmi)
+	}
+
+	if bmi := (Profile{Weight: 58.5}).BMI(); bmi != 0 {
+		t.Fatalf("BMI without height = %v, want 0", bmi)
+	}
+}
@@ -0,0 +1,37 @@
+package chat
+
+import "time"
+
+// RatingValue - оценка ответа ИИ пользователем.
+type RatingValue string
+
+// Оценки ответов.
+const (
+	RatingUp         RatingValue = "up"         // Полезный ответ.
+	RatingDown       RatingValue = "down"       // Бесполезный ответ.
+	RatingInaccurate RatingValue = "inaccurate" // Ответ содержит неточности.
+)
+
+// RatingValues перечисляет оценки в порядке кнопок.
+var RatingValues = []RatingValue{RatingUp, RatingDown, RatingInaccurate}
+
+// Rating - отзыв пользователя об ответе ИИ. Запись создается при отправке
+// ответа, оценка добавляется, когда пользователь нажимает кнопку.
+type Rating struct {
+	ID         string      // Идентификатор ответа ИИ в истории.
+	ChatID     int64       // Чат, в котором дан ответ.
+	UserID     int64       // Пользователь, получивший ответ.
+	Prompt     string      // Имя промпта, с которым получен ответ.
+	Model      string      // Модель, давшая ответ.
+	Score      float64     // Оценка достоверности валидатором, 0 без проверки.
+	QuestionID string      // Идентификатор сообщения пользователя.
+	Value      RatingValue // Оценка пользователя, пустая, если ответ не оценен.
+	Comment    string      // Пояснение к оценке RatingInaccurate.
+	CreatedAt  time.Time   // Время ответа.
+	RatedAt    time.Time   // Время оценки.
+}
+
+// IsRated проверяет, оценил ли пользователь ответ.
+func (r Rating) IsRated() bool {
+	return r.Value != ""
+}
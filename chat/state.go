@@ -0,0 +1,153 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTransition возвращается при недопустимом переходе между этапами диалога.
+var ErrInvalidTransition = errors.New("invalid state transition")
+
+// Stage определяет этап диалога в чате.
+type Stage int
+
+const (
+	StageOnboarding          Stage = iota // Новый чат, приветствие ещё не показано.
+	StageAwaitingCredentials              // Ожидаем email и пароль от пользователя.
+	StageChatting                         // Свободное общение без выбранного анализа.
+	StageCodelabSelected                  // Общение по выбранному анализу.
+)
+
+// String возвращает строковое представление этапа.
+func (s Stage) String() string {
+	switch s {
+	case StageOnboarding:
+		return "onboarding"
+	case StageAwaitingCredentials:
+		return "awaiting_credentials"
+	case StageChatting:
+		return "chatting"
+	case StageCodelabSelected:
+		return "codelab_selected"
+	default:
+		return "unknown"
+	}
+}
+
+// transitions описывает допустимые переходы между этапами.
+var transitions = map[Stage][]Stage{
+	StageOnboarding: {
+		StageOnboarding,
+		StageAwaitingCredentials,
+		StageChatting,
+	},
+	StageAwaitingCredentials: {
+		StageOnboarding,
+		StageAwaitingCredentials,
+		StageChatting,
+	},
+	StageChatting: {
+		StageOnboarding,
+		StageChatting,
+		StageCodelabSelected,
+	},
+	StageCodelabSelected: {
+		StageOnboarding,
+		StageChatting,
+		StageCodelabSelected,
+	},
+}
+
+// CanTransition проверяет, допустим ли переход из одного этапа в другой.
+func (s Stage) CanTransition(to Stage) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// State хранит состояние диалога в конкретном чате.
+type State struct {
+	Stage     Stage     // Текущий этап диалога.
+	Codelab   string    // Выбранный анализ (только для StageCodelabSelected).
+	UpdatedAt time.Time // Время последнего перехода.
+
+	// Input - вопрос бота, ответ на который ожидается следующим текстовым
+	// сообщением до InputUntil. Переход в другой этап отменяет вопрос.
+	Input      string
+	InputUntil time.Time
+}
+
+// AwaitInput запоминает вопрос, ответ на который ожидается в течение ttl.
+func (s State) AwaitInput(question string, ttl time.Duration) State {
+	s.Input = question
+	s.InputUntil = Now().Add(ttl).UTC()
+	return s
+}
+
+// PendingInput возвращает вопрос, ответ на который еще ожидается.
+func (s State) PendingInput() (string, bool) {
+	if s.Input == "" || !Now().Before(s.InputUntil) {
+		return "", false
+	}
+
+	return s.Input, true
+}
+
+// ClearInput отменяет ожидание ответа на вопрос.
+func (s State) ClearInput() State {
+	s.Input = ""
+	s.InputUntil = time.Time{}
+	return s
+}
+
+// transition выполняет переход в новый этап.
+func (s State) transition(to Stage, codelab string) (State, error) {
+	if !s.Stage.CanTransition(to) {
+		return s, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, s.Stage, to)
+	}
+
+	return State{
+		Stage:     to,
+		Codelab:   codelab,
+		UpdatedAt: Now().UTC(),
+	}, nil
+}
+
+// Reset возвращает чат к начальному этапу (например, после выхода из аккаунта).
+func (s State) Reset() State {
+	next, _ := s.transition(StageOnboarding, "")
+	return next
+}
+
+// AwaitCredentials переводит чат в ожидание email и пароля.
+func (s State) AwaitCredentials() (State, error) {
+	return s.transition(StageAwaitingCredentials, "")
+}
+
+// StartChatting переводит чат в свободное общение и сбрасывает выбранный анализ.
+func (s State) StartChatting() (State, error) {
+	return s.transition(StageChatting, "")
+}
+
+// SelectCodelab закрепляет анализ за чатом.
+func (s State) SelectCodelab(code string) (State, error) {
+	if code == "" {
+		return s, fmt.Errorf("%w: empty codelab", ErrInvalidTransition)
+	}
+
+	return s.transition(StageCodelabSelected, code)
+}
+
+// SelectedCodelab возвращает выбранный анализ, если он есть.
+func (s State) SelectedCodelab() (string, bool) {
+	if s.Stage != StageCodelabSelected || s.Codelab == "" {
+		return "", false
+	}
+
+	return s.Codelab, true
+}
@@ -0,0 +1,85 @@
+package chat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStateTransitions(t *testing.T) {
+	var state State
+
+	if state.Stage != StageOnboarding {
+		t.Fatalf("zero state stage = %v, want %v", state.Stage, StageOnboarding)
+	}
+
+	if _, err := state.SelectCodelab("WN0000T"); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("SelectCodelab from onboarding error = %v, want %v", err, ErrInvalidTransition)
+	}
+
+	state, err := state.AwaitCredentials()
+	if err != nil {
+		t.Fatalf("AwaitCredentials: %v", err)
+	}
+
+	state, err = state.StartChatting()
+	if err != nil {
+		t.Fatalf("StartChatting: %v", err)
+	}
+
+	if _, ok := state.SelectedCodelab(); ok {
+		t.Fatalf("SelectedCodelab while chatting returned ok")
+	}
+
+	state, err = state.SelectCodelab("WN0000T")
+	if err != nil {
+		t.Fatalf("SelectCodelab: %v", err)
+	}
+
+	if code, ok := state.SelectedCodelab(); !ok || code != "WN0000T" {
+		t.Fatalf("SelectedCodelab = %q, %v, want %q, true", code, ok, "WN0000T")
+	}
+
+	if _, err := state.AwaitCredentials(); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("AwaitCredentials from codelab_selected error = %v, want %v", err, ErrInvalidTransition)
+	}
+
+	state = state.Reset()
+	if state.Stage != StageOnboarding || state.Codelab != "" {
+		t.Fatalf("Reset = %+v, want onboarding without codelab", state)
+	}
+}
+
+func TestStateInput(t *testing.T) {
+	state, err := State{}.StartChatting()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := state.PendingInput(); ok {
+		t.Fatalf("new state has a pending input")
+	}
+
+	state = state.AwaitInput("profile:next:age", 10*time.Minute)
+	if question, ok := state.PendingInput(); !ok || question != "profile:next:age" {
+		t.Fatalf("PendingInput = %q, %v", question, ok)
+	}
+
+	if _, ok := state.ClearInput().PendingInput(); ok {
+		t.Errorf("cleared input is pending")
+	}
+
+	// A transition to another stage cancels the question.
+	selected, err := state.SelectCodelab("WN0000T")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := selected.PendingInput(); ok {
+		t.Errorf("input is pending after a transition")
+	}
+
+	state.InputUntil = time.Now().Add(-time.Second)
+	if _, ok := state.PendingInput(); ok {
+		t.Errorf("expired input is pending")
+	}
+}
@@ -12,8 +12,14 @@ import (
 
 // Имена бакетов для хранения в BoltDB.
 var (
-	chatBucket = []byte("chats")
-	userBucket = []byte("users")
+	chatBucket    = []byte("chats")
+	stateBucket   = []byte("states")
+	userBucket    = []byte("users")
+	planBucket    = []byte("plans")
+	diaryBucket   = []byte("diaries")
+	journalBucket = []byte("journals")
+	ratingBucket  = []byte("ratings")
+	jobBucket     = []byte("jobs")
 )
 
 // Bolt реализует хранение в BoltDB (bbolt).
@@ -37,11 +43,41 @@ func NewBolt(path string) (*Bolt, error) {
 			return err
 		}
 
+		_, err = tx.CreateBucketIfNotExists(stateBucket)
+		if err != nil {
+			return err
+		}
+
 		_, err = tx.CreateBucketIfNotExists(userBucket)
 		if err != nil {
 			return err
 		}
 
+		_, err = tx.CreateBucketIfNotExists(planBucket)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucketIfNotExists(diaryBucket)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucketIfNotExists(journalBucket)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucketIfNotExists(ratingBucket)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucketIfNotExists(jobBucket)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -118,6 +154,45 @@ func (b *Bolt) SaveChatHistory(
 	})
 }
 
+// GetChatState читает состояние диалога из BoltDB.
+func (b *Bolt) GetChatState(ctx context.Context, chatID int64) (chat.State, error) {
+	var state chat.State
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		var (
+			bucket = tx.Bucket(stateBucket)
+			key    = []byte(strconv.FormatInt(chatID, 10))
+			data   = bucket.Get(key)
+		)
+		if data == nil {
+			return nil
+		}
+
+		return json.Unmarshal(data, &state)
+	})
+
+	if err != nil {
+		return chat.State{}, err
+	}
+
+	return state, nil
+}
+
+// SaveChatState записывает состояние диалога в BoltDB.
+func (b *Bolt) SaveChatState(ctx context.Context, chatID int64, state chat.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		var (
+			bucket = tx.Bucket(stateBucket)
+			key    = []byte(strconv.FormatInt(chatID, 10))
+		)
+		return bucket.Put(key, data)
+	})
+}
+
 // GetUser возвращает пользователя из BoltDB по его ID.
 func (b *Bolt) GetUser(ctx context.Context, userID int64) (chat.User, error) {
 	var user chat.User
@@ -156,3 +231,219 @@ func (b *Bolt) SaveUser(ctx context.Context, user chat.User) error {
 		return bucket.Put(key, data)
 	})
 }
+
+// GetPlan читает план действий из BoltDB.
+func (b *Bolt) GetPlan(ctx context.Context, userID int64) (chat.Plan, error) {
+	var plan chat.Plan
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		var (
+			bucket = tx.Bucket(planBucket)
+			key    = []byte(strconv.FormatInt(userID, 10))
+			data   = bucket.Get(key)
+		)
+		if data == nil {
+			return nil
+		}
+
+		return json.Unmarshal(data, &plan)
+	})
+
+	if err != nil {
+		return chat.Plan{}, err
+	}
+
+	return plan, nil
+}
+
+// SavePlan записывает план действий в BoltDB.
+func (b *Bolt) SavePlan(ctx context.Context, userID int64, plan chat.Plan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		var (
+			bucket = tx.Bucket(planBucket)
+			key    = []byte(strconv.FormatInt(userID, 10))
+		)
+		return bucket.Put(key, data)
+	})
+}
+
+// GetDiary читает дневник питания за день из BoltDB.
+func (b *Bolt) GetDiary(ctx context.Context, userID int64, date string) (chat.Diary, error) {
+	diary := chat.Diary{Date: date}
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		var (
+			bucket = tx.Bucket(diaryBucket)
+			key    = []byte(diaryKey(userID, date))
+			data   = bucket.Get(key)
+		)
+		if data == nil {
+			return nil
+		}
+
+		return json.Unmarshal(data, &diary)
+	})
+
+	if err != nil {
+		return chat.Diary{}, err
+	}
+
+	return diary, nil
+}
+
+// SaveDiary записывает дневник питания за день в BoltDB.
+func (b *Bolt) SaveDiary(ctx context.Context, userID int64, diary chat.Diary) error {
+	data, err := json.Marshal(diary)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		var (
+			bucket = tx.Bucket(diaryBucket)
+			key    = []byte(diaryKey(userID, diary.Date))
+		)
+		return bucket.Put(key, data)
+	})
+}
+
+// diaryKey возвращает ключ дневника пользователя за день.
+func diaryKey(userID int64, date string) string {
+	return strconv.FormatInt(userID, 10) + ":" + date
+}
+
+// GetJournal читает журнал самочувствия из BoltDB.
+func (b *Bolt) GetJournal(ctx context.Context, userID int64) (chat.Journal, error) {
+	var journal chat.Journal
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		var (
+			bucket = tx.Bucket(journalBucket)
+			key    = []byte(strconv.FormatInt(userID, 10))
+			data   = bucket.Get(key)
+		)
+		if data == nil {
+			return nil
+		}
+
+		return json.Unmarshal(data, &journal)
+	})
+
+	if err != nil {
+		return chat.Journal{}, err
+	}
+
+	return journal, nil
+}
+
+// SaveJournal записывает журнал самочувствия в BoltDB.
+func (b *Bolt) SaveJournal(ctx context.Context, userID int64, journal chat.Journal) error {
+	data, err := json.Marshal(journal)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		var (
+			bucket = tx.Bucket(journalBucket)
+			key    = []byte(strconv.FormatInt(userID, 10))
+		)
+		return bucket.Put(key, data)
+	})
+}
+
+// GetRating читает оценку ответа из BoltDB.
+func (b *Bolt) GetRating(ctx context.Context, id string) (chat.Rating, error) {
+	var rating chat.Rating
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(ratingBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+
+		return json.Unmarshal(data, &rating)
+	})
+
+	if err != nil {
+		return chat.Rating{}, err
+	}
+
+	return rating, nil
+}
+
+// GetRatings читает все оценки ответов из BoltDB.
+func (b *Bolt) GetRatings(ctx context.Context) ([]chat.Rating, error) {
+	var ratings []chat.Rating
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(ratingBucket).ForEach(func(key, data []byte) error {
+			var rating chat.Rating
+			if err := json.Unmarshal(data, &rating); err != nil {
+				return err
+			}
+
+			ratings = append(ratings, rating)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// SaveRating записывает оценку ответа в BoltDB.
+func (b *Bolt) SaveRating(ctx context.Context, rating chat.Rating) error {
+	data, err := json.Marshal(rating)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(ratingBucket).Put([]byte(rating.ID), data)
+	})
+}
+
+// GetJobs читает все задания планировщика из BoltDB.
+func (b *Bolt) GetJobs(ctx context.Context) ([]chat.Job, error) {
+	var jobs []chat.Job
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobBucket).ForEach(func(key, data []byte) error {
+			var job chat.Job
+			if err := json.Unmarshal(data, &job); err != nil {
+				return err
+			}
+
+			jobs = append(jobs, job)
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// SaveJob записывает задание планировщика в BoltDB.
+func (b *Bolt) SaveJob(ctx context.Context, job chat.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobBucket).Put([]byte(job.ID), data)
+	})
+}
+
+// DeleteJob удаляет задание планировщика из BoltDB.
+func (b *Bolt) DeleteJob(ctx context.Context, id string) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobBucket).Delete([]byte(id))
+	})
+}
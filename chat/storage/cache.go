@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Имя бакета для кэша запросов.
+var cacheBucket = []byte("cache")
+
+// maxCleanupInterval ограничивает интервал удаления истекших записей.
+const maxCleanupInterval = time.Hour
+
+// cacheEntry представляет запись кэша с временем истечения.
+type cacheEntry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// BoltCache реализует кэш запросов в BoltDB с истечением записей по TTL.
+// Значения сериализуются в JSON, поэтому после чтения строки остаются
+// строками, а структуры превращаются в map[string]any. Истекшие записи
+// удаляются периодически, даже если их больше не читают.
+type BoltCache struct {
+	db    *bbolt.DB
+	ttl   time.Duration
+	owned bool
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewBoltCache открывает отдельную базу BoltDB для кэша.
+func NewBoltCache(path string, ttl time.Duration) (*BoltCache, error) {
+	db, err := bbolt.Open(path, 0644, &bbolt.Options{
+		Timeout: 10 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := newBoltCache(db, ttl)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	c.owned = true
+
+	return c, nil
+}
+
+// Cache возвращает кэш, использующий ту же базу, что и хранилище.
+func (b *Bolt) Cache(ttl time.Duration) (*BoltCache, error) {
+	return newBoltCache(b.db, ttl)
+}
+
+func newBoltCache(db *bbolt.DB, ttl time.Duration) (*BoltCache, error) {
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(cacheBucket)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	c := &BoltCache{db: db, ttl: ttl, stop: make(chan struct{}), done: make(chan struct{})}
+	if err := c.DeleteExpired(); err != nil {
+		return nil, err
+	}
+
+	go c.cleanup()
+
+	return c, nil
+}
+
+// cleanup удаляет истекшие записи, пока кэш не закрыт.
+func (c *BoltCache) cleanup() {
+	defer close(c.done)
+
+	if c.ttl <= 0 {
+		<-c.stop
+		return
+	}
+
+	ticker := time.NewTicker(min(c.ttl, maxCleanupInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.DeleteExpired(); err != nil {
+				log.Printf("cache: failed to delete expired entries: %v", err)
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Close останавливает удаление истекших записей и закрывает базу, если она
+// была открыта кэшем.
+func (c *BoltCache) Close() error {
+	c.once.Do(func() { close(c.stop) })
+	<-c.done
+
+	if !c.owned {
+		return nil
+	}
+
+	return c.db.Close()
+}
+
+// Add сохраняет значение в кэш. Всегда возвращает false, так как
+// кэш не вытесняет записи по размеру.
+func (c *BoltCache) Add(key string, value any) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("cache: failed to marshal value for key %q: %v", key, err)
+		return false
+	}
+
+	entry := cacheEntry{Value: data}
+	if c.ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(c.ttl).UTC()
+	}
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("cache: failed to marshal entry for key %q: %v", key, err)
+		return false
+	}
+
+	if err := c.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(cacheBucket).Put([]byte(key), raw)
+	}); err != nil {
+		log.Printf("cache: failed to write key %q: %v", key, err)
+	}
+
+	return false
+}
+
+// Get возвращает значение из кэша, если оно есть и не истекло.
+func (c *BoltCache) Get(key string) (any, bool) {
+	var (
+		entry cacheEntry
+		found bool
+	)
+	if err := c.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(cacheBucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+
+		found = true
+		return json.Unmarshal(data, &entry)
+	}); err != nil {
+		log.Printf("cache: failed to read key %q: %v", key, err)
+		return nil, false
+	}
+
+	if !found {
+		return nil, false
+	}
+
+	if entry.expired(time.Now()) {
+		c.Remove(key)
+		return nil, false
+	}
+
+	var value any
+	if err := json.Unmarshal(entry.Value, &value); err != nil {
+		log.Printf("cache: failed to unmarshal value for key %q: %v", key, err)
+		return nil, false
+	}
+
+	return value, true
+}
+
+// Remove удаляет значение из кэша.
+func (c *BoltCache) Remove(key string) bool {
+	var present bool
+	if err := c.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(cacheBucket)
+		present = bucket.Get([]byte(key)) != nil
+		return bucket.Delete([]byte(key))
+	}); err != nil {
+		log.Printf("cache: failed to delete key %q: %v", key, err)
+		return false
+	}
+
+	return present
+}
+
+// DeleteExpired удаляет все истекшие записи.
+func (c *BoltCache) DeleteExpired() error {
+	now := time.Now()
+
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		var (
+			bucket  = tx.Bucket(cacheBucket)
+			expired [][]byte
+		)
+
+		if err := bucket.ForEach(func(k, v []byte) error {
+			var entry cacheEntry
+			if err := json.Unmarshal(v, &entry); err != nil || entry.expired(now) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// expired проверяет, истекла ли запись к моменту now.
+func (e cacheEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
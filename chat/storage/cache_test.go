@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+func TestBoltCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+
+	c, err := NewBoltCache(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Add("question", "Что показывают мои анализы?")
+
+	// Переоткрываем базу, чтобы убедиться, что значение пережило перезапуск.
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = NewBoltCache(path, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	value, ok := c.Get("question")
+	if !ok {
+		t.Fatal("value not found after reopen")
+	}
+
+	if value != "Что показывают мои анализы?" {
+		t.Errorf("Get = %v, want original string", value)
+	}
+
+	if !c.Remove("question") {
+		t.Error("Remove returned false for present key")
+	}
+
+	if _, ok := c.Get("question"); ok {
+		t.Error("value found after Remove")
+	}
+}
+
+func TestBoltCacheExpiry(t *testing.T) {
+	c, err := NewBoltCache(filepath.Join(t.TempDir(), "cache.db"), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.Add("key", "value")
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("key"); ok {
+		t.Error("expired value returned")
+	}
+}
+
+func TestBoltCacheCleanup(t *testing.T) {
+	c, err := NewBoltCache(filepath.Join(t.TempDir(), "cache.db"), 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.Add("tg_select:1:2", "select")
+
+	// Запись удаляется без чтения.
+	deadline := time.Now().Add(time.Second)
+	for {
+		var keys int
+		if err := c.db.View(func(tx *bbolt.Tx) error {
+			keys = tx.Bucket(cacheBucket).Stats().KeyN
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if keys == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d expired entries are not deleted", keys)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 
 	"github.com/muzykantov/health-gpt/chat"
@@ -76,6 +77,40 @@ func (fs *FS) SaveChatHistory(
 	return os.WriteFile(fs.historyPath(chatID), data, 0644)
 }
 
+// GetChatState читает состояние диалога из файла.
+func (fs *FS) GetChatState(ctx context.Context, chatID int64) (chat.State, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	data, err := os.ReadFile(fs.statePath(chatID))
+	if os.IsNotExist(err) {
+		return chat.State{}, nil
+	}
+	if err != nil {
+		return chat.State{}, err
+	}
+
+	var state chat.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return chat.State{}, err
+	}
+
+	return state, nil
+}
+
+// SaveChatState записывает состояние диалога в файл.
+func (fs *FS) SaveChatState(ctx context.Context, chatID int64, state chat.State) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fs.statePath(chatID), data, 0644)
+}
+
 // GetUser возвращает пользователя из файла по его ID.
 func (fs *FS) GetUser(ctx context.Context, userID int64) (chat.User, error) {
 	fs.mu.RLock()
@@ -110,12 +145,273 @@ func (fs *FS) SaveUser(ctx context.Context, user chat.User) error {
 	return os.WriteFile(fs.userPath(user.ID), data, 0644)
 }
 
+// GetPlan читает план действий из файла.
+func (fs *FS) GetPlan(ctx context.Context, userID int64) (chat.Plan, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	data, err := os.ReadFile(fs.planPath(userID))
+	if os.IsNotExist(err) {
+		return chat.Plan{}, nil
+	}
+	if err != nil {
+		return chat.Plan{}, err
+	}
+
+	var plan chat.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return chat.Plan{}, err
+	}
+
+	return plan, nil
+}
+
+// SavePlan записывает план действий в файл.
+func (fs *FS) SavePlan(ctx context.Context, userID int64, plan chat.Plan) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := json.MarshalIndent(plan, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fs.planPath(userID), data, 0644)
+}
+
+// GetDiary читает дневник питания за день из файла.
+func (fs *FS) GetDiary(ctx context.Context, userID int64, date string) (chat.Diary, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	data, err := os.ReadFile(fs.diaryPath(userID, date))
+	if os.IsNotExist(err) {
+		return chat.Diary{Date: date}, nil
+	}
+	if err != nil {
+		return chat.Diary{}, err
+	}
+
+	var diary chat.Diary
+	if err := json.Unmarshal(data, &diary); err != nil {
+		return chat.Diary{}, err
+	}
+
+	return diary, nil
+}
+
+// SaveDiary записывает дневник питания за день в файл.
+func (fs *FS) SaveDiary(ctx context.Context, userID int64, diary chat.Diary) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := json.MarshalIndent(diary, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fs.diaryPath(userID, diary.Date), data, 0644)
+}
+
+// GetJournal читает журнал самочувствия из файла.
+func (fs *FS) GetJournal(ctx context.Context, userID int64) (chat.Journal, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	data, err := os.ReadFile(fs.journalPath(userID))
+	if os.IsNotExist(err) {
+		return chat.Journal{}, nil
+	}
+	if err != nil {
+		return chat.Journal{}, err
+	}
+
+	var journal chat.Journal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return chat.Journal{}, err
+	}
+
+	return journal, nil
+}
+
+// SaveJournal записывает журнал самочувствия в файл.
+func (fs *FS) SaveJournal(ctx context.Context, userID int64, journal chat.Journal) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := json.MarshalIndent(journal, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fs.journalPath(userID), data, 0644)
+}
+
+// GetRating читает оценку ответа из файла.
+func (fs *FS) GetRating(ctx context.Context, id string) (chat.Rating, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	rating, err := fs.readRating(fs.ratingPath(id))
+	if os.IsNotExist(err) {
+		return chat.Rating{}, nil
+	}
+
+	return rating, err
+}
+
+// GetRatings читает все оценки ответов из файлов.
+func (fs *FS) GetRatings(ctx context.Context) ([]chat.Rating, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	paths, err := filepath.Glob(fs.ratingPath("*"))
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make([]chat.Rating, 0, len(paths))
+	for _, path := range paths {
+		rating, err := fs.readRating(path)
+		if err != nil {
+			return nil, err
+		}
+
+		ratings = append(ratings, rating)
+	}
+
+	return ratings, nil
+}
+
+// SaveRating записывает оценку ответа в отдельный файл, чтобы не
+// перезаписывать остальные оценки.
+func (fs *FS) SaveRating(ctx context.Context, rating chat.Rating) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := json.MarshalIndent(rating, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fs.ratingPath(rating.ID), data, 0644)
+}
+
+// readRating читает оценку ответа. Вызывается под блокировкой.
+func (fs *FS) readRating(path string) (chat.Rating, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return chat.Rating{}, err
+	}
+
+	var rating chat.Rating
+	if err := json.Unmarshal(data, &rating); err != nil {
+		return chat.Rating{}, err
+	}
+
+	return rating, nil
+}
+
+// GetJobs читает все задания планировщика из файла.
+func (fs *FS) GetJobs(ctx context.Context) ([]chat.Job, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	return fs.readJobs()
+}
+
+// SaveJob записывает задание планировщика в файл.
+func (fs *FS) SaveJob(ctx context.Context, job chat.Job) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	jobs, err := fs.readJobs()
+	if err != nil {
+		return err
+	}
+
+	jobs = slices.DeleteFunc(jobs, func(j chat.Job) bool { return j.ID == job.ID })
+
+	return fs.writeJobs(append(jobs, job))
+}
+
+// DeleteJob удаляет задание планировщика из файла.
+func (fs *FS) DeleteJob(ctx context.Context, id string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	jobs, err := fs.readJobs()
+	if err != nil {
+		return err
+	}
+
+	return fs.writeJobs(slices.DeleteFunc(jobs, func(j chat.Job) bool { return j.ID == id }))
+}
+
+// readJobs читает задания планировщика. Вызывается под блокировкой.
+func (fs *FS) readJobs() ([]chat.Job, error) {
+	data, err := os.ReadFile(fs.jobsPath())
+	if os.IsNotExist(err) {
+		return []chat.Job{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []chat.Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// writeJobs записывает задания планировщика. Вызывается под блокировкой.
+func (fs *FS) writeJobs(jobs []chat.Job) error {
+	data, err := json.MarshalIndent(jobs, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fs.jobsPath(), data, 0644)
+}
+
 // historyPath возвращает путь к файлу истории чата.
 func (fs *FS) historyPath(chatID int64) string {
 	return filepath.Join(fs.dir, fmt.Sprintf("chat_%d.json", chatID))
 }
 
+// statePath возвращает путь к файлу состояния чата.
+func (fs *FS) statePath(chatID int64) string {
+	return filepath.Join(fs.dir, fmt.Sprintf("state_%d.json", chatID))
+}
+
 // userPath возвращает путь к файлу пользователя.
 func (fs *FS) userPath(userID int64) string {
 	return filepath.Join(fs.dir, fmt.Sprintf("user_%d.json", userID))
 }
+
+// planPath возвращает путь к файлу плана действий.
+func (fs *FS) planPath(userID int64) string {
+	return filepath.Join(fs.dir, fmt.Sprintf("plan_%d.json", userID))
+}
+
+// diaryPath возвращает путь к файлу дневника питания за день.
+func (fs *FS) diaryPath(userID int64, date string) string {
+	return filepath.Join(fs.dir, fmt.Sprintf("diary_%d_%s.json", userID, date))
+}
+
+// journalPath возвращает путь к файлу журнала самочувствия.
+func (fs *FS) journalPath(userID int64) string {
+	return filepath.Join(fs.dir, fmt.Sprintf("journal_%d.json", userID))
+}
+
+// ratingPath возвращает путь к файлу оценки ответа.
+func (fs *FS) ratingPath(id string) string {
+	return filepath.Join(fs.dir, fmt.Sprintf("rating_%s.json", filepath.Base(id)))
+}
+
+// jobsPath возвращает путь к файлу заданий планировщика.
+func (fs *FS) jobsPath() string {
+	return filepath.Join(fs.dir, "jobs.json")
+}
@@ -0,0 +1,384 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// backend - хранилище, проверяемое общими тестами.
+type backend struct {
+	name    string
+	storage server.DataStorage
+	dir     string // Каталог, в котором создано хранилище.
+}
+
+// backends возвращает файловое хранилище и хранилище BoltDB, созданные
+// во временных каталогах теста.
+func backends(t *testing.T) []backend {
+	t.Helper()
+
+	fsDir := t.TempDir()
+	fs, err := NewFS(filepath.Join(fsDir, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boltDir := t.TempDir()
+	bolt, err := NewBolt(filepath.Join(boltDir, "bolt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	return []backend{
+		{name: "fs", storage: fs, dir: fsDir},
+		{name: "bolt", storage: bolt, dir: boltDir},
+	}
+}
+
+func TestStorageRatings(t *testing.T) {
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			rating, err := b.storage.GetRating(ctx, "unknown")
+			if err != nil || !reflect.DeepEqual(rating, chat.Rating{}) {
+				t.Fatalf("GetRating(unknown) = %+v, %v, want empty rating", rating, err)
+			}
+
+			ratings, err := b.storage.GetRatings(ctx)
+			if err != nil || len(ratings) != 0 {
+				t.Fatalf("GetRatings = %+v, %v, want none", ratings, err)
+			}
+
+			up := chat.Rating{
+				ID:        "answer-1",
+				ChatID:    1,
+				UserID:    2,
+				Prompt:    "mygenetics_chat",
+				Model:     "test",
+				Score:     0.9,
+				Value:     chat.RatingUp,
+				CreatedAt: created,
+				RatedAt:   created.Add(time.Minute),
+			}
+			inaccurate := chat.Rating{ID: "answer-2", ChatID: 1, CreatedAt: created}
+
+			for _, r := range []chat.Rating{up, inaccurate} {
+				if err := b.storage.SaveRating(ctx, r); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Повторное сохранение заменяет оценку, не затрагивая остальные.
+			inaccurate.Value, inaccurate.Comment = chat.RatingInaccurate, "Неверный генотип."
+			if err := b.storage.SaveRating(ctx, inaccurate); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range []chat.Rating{up, inaccurate} {
+				got, err := b.storage.GetRating(ctx, want.ID)
+				if err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("GetRating(%s) = %+v, %v, want %+v", want.ID, got, err, want)
+				}
+			}
+
+			ratings, err = b.storage.GetRatings(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.SortFunc(ratings, func(a, b chat.Rating) int { return strings.Compare(a.ID, b.ID) })
+			if !reflect.DeepEqual(ratings, []chat.Rating{up, inaccurate}) {
+				t.Errorf("GetRatings = %+v, want both ratings", ratings)
+			}
+		})
+	}
+}
+
+func TestStorageRatingPathTraversal(t *testing.T) {
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			rating := chat.Rating{ID: "../../../evil", Value: chat.RatingDown}
+			if err := b.storage.SaveRating(ctx, rating); err != nil {
+				t.Fatal(err)
+			}
+
+			// Идентификатор не выводит файл оценки за пределы хранилища.
+			for _, dir := range []string{b.dir, filepath.Dir(b.dir)} {
+				if _, err := os.Stat(filepath.Join(dir, "evil.json")); !os.IsNotExist(err) {
+					t.Errorf("evil.json is written to %s: %v", dir, err)
+				}
+			}
+
+			got, err := b.storage.GetRating(ctx, rating.ID)
+			if err != nil || got != rating {
+				t.Errorf("GetRating = %+v, %v, want %+v", got, err, rating)
+			}
+
+			ratings, err := b.storage.GetRatings(ctx)
+			if err != nil || len(ratings) != 1 || ratings[0] != rating {
+				t.Errorf("GetRatings = %+v, %v, want the rating", ratings, err)
+			}
+		})
+	}
+}
+
+func TestStorageChatState(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		state chat.State
+	}{
+		{"onboarding", chat.State{}},
+		{"codelab", chat.State{Stage: chat.StageCodelabSelected, Codelab: "WN0000T", UpdatedAt: updated}},
+		{"input", chat.State{
+			Stage:      chat.StageChatting,
+			UpdatedAt:  updated,
+			Input:      "profile:next:age",
+			InputUntil: updated.Add(10 * time.Minute),
+		}},
+	}
+
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			// Состояние нового чата - нулевое.
+			state, err := b.storage.GetChatState(ctx, 100)
+			if err != nil || !reflect.DeepEqual(state, chat.State{}) {
+				t.Fatalf("GetChatState(new chat) = %+v, %v, want zero state", state, err)
+			}
+
+			for i, tt := range tests {
+				chatID := int64(i + 1)
+				if err := b.storage.SaveChatState(ctx, chatID, tt.state); err != nil {
+					t.Fatalf("%s: SaveChatState: %v", tt.name, err)
+				}
+			}
+
+			for i, tt := range tests {
+				got, err := b.storage.GetChatState(ctx, int64(i+1))
+				if err != nil || !reflect.DeepEqual(got, tt.state) {
+					t.Errorf("%s: GetChatState = %+v, %v, want %+v", tt.name, got, err, tt.state)
+				}
+			}
+		})
+	}
+}
+
+func TestStoragePlan(t *testing.T) {
+	done := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		userID int64
+		plan   chat.Plan
+	}{
+		{"one task", 1, chat.Plan{Tasks: []chat.Task{
+			{ID: "t1", Codelab: "WN0000T", Feature: "Метаболизм кофеина", Text: "Не пить кофе после 14:00"},
+		}}},
+		{"done task", 2, chat.Plan{Tasks: []chat.Task{
+			{ID: "t1", Codelab: "WN0000T", Text: "Пить больше воды", Done: true, DoneAt: done},
+			{ID: "t2", Codelab: "DX0000T", Text: "Кардио 3 раза в неделю"},
+		}}},
+	}
+
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			// У нового пользователя план пуст.
+			plan, err := b.storage.GetPlan(ctx, 100)
+			if err != nil || len(plan.Tasks) != 0 {
+				t.Fatalf("GetPlan(new user) = %+v, %v, want empty plan", plan, err)
+			}
+
+			for _, tt := range tests {
+				if err := b.storage.SavePlan(ctx, tt.userID, tt.plan); err != nil {
+					t.Fatalf("%s: SavePlan: %v", tt.name, err)
+				}
+			}
+
+			for _, tt := range tests {
+				got, err := b.storage.GetPlan(ctx, tt.userID)
+				if err != nil || !reflect.DeepEqual(got, tt.plan) {
+					t.Errorf("%s: GetPlan = %+v, %v, want %+v", tt.name, got, err, tt.plan)
+				}
+			}
+		})
+	}
+}
+
+func TestStorageJobs(t *testing.T) {
+	next := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	reminder := chat.Job{
+		ID:        chat.JobID(1, chat.JobReminder, ""),
+		ChatID:    1,
+		UserID:    1,
+		Kind:      chat.JobReminder,
+		Transport: "telegram",
+		Spec:      "0 10 * * *",
+	}
+	tip := chat.Job{ID: chat.JobID(1, chat.JobTip, ""), ChatID: 1, UserID: 1, Kind: chat.JobTip, Spec: "0 12 * * 1"}
+
+	scheduled := reminder
+	scheduled.Next, scheduled.Last = next, next.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name   string
+		save   []chat.Job
+		delete []string
+		want   []chat.Job
+	}{
+		{"empty", nil, nil, nil},
+		{"save", []chat.Job{reminder, tip}, nil, []chat.Job{reminder, tip}},
+		{"upsert", []chat.Job{scheduled}, nil, []chat.Job{scheduled, tip}},
+		{"delete", nil, []string{tip.ID}, []chat.Job{scheduled}},
+		{"delete unknown", nil, []string{"unknown"}, []chat.Job{scheduled}},
+		{"delete all", nil, []string{reminder.ID}, nil},
+	}
+
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			// Шаги выполняются по порядку над одним хранилищем.
+			for _, tt := range tests {
+				for _, job := range tt.save {
+					if err := b.storage.SaveJob(ctx, job); err != nil {
+						t.Fatalf("%s: SaveJob: %v", tt.name, err)
+					}
+				}
+				for _, id := range tt.delete {
+					if err := b.storage.DeleteJob(ctx, id); err != nil {
+						t.Fatalf("%s: DeleteJob: %v", tt.name, err)
+					}
+				}
+
+				got, err := b.storage.GetJobs(ctx)
+				if err != nil {
+					t.Fatalf("%s: GetJobs: %v", tt.name, err)
+				}
+				slices.SortFunc(got, func(a, b chat.Job) int { return strings.Compare(a.ID, b.ID) })
+
+				want := slices.Clone(tt.want)
+				slices.SortFunc(want, func(a, b chat.Job) int { return strings.Compare(a.ID, b.ID) })
+
+				if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+					t.Errorf("%s: GetJobs = %+v, want %+v", tt.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStorageDiary(t *testing.T) {
+	eaten := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		userID int64
+		diary  chat.Diary
+	}{
+		{"text", 1, chat.Diary{Date: "2026-03-01", Meals: []chat.Meal{{
+			Time:     eaten,
+			Text:     "овсянка на молоке и кофе",
+			Items:    []chat.FoodItem{{Name: "Овсянка", Amount: "250 г", Calories: 250, Tags: []chat.FoodTag{chat.FoodLactose}}},
+			Feedback: "Это ваша единственная чашка кофе на сегодня.",
+		}}}},
+		{"next day", 1, chat.Diary{Date: "2026-03-02", Meals: []chat.Meal{{
+			Time:  eaten.AddDate(0, 0, 1),
+			Photo: true,
+			Items: []chat.FoodItem{{Name: "Латте", Amount: "300 мл", Calories: 180}},
+		}}}},
+		{"other user", 2, chat.Diary{Date: "2026-03-01", Meals: []chat.Meal{{
+			Time:  eaten,
+			Text:  "яблоко",
+			Items: []chat.FoodItem{{Name: "Яблоко", Calories: 80}},
+		}}}},
+	}
+
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			// Пустой дневник сохраняет запрошенный день.
+			diary, err := b.storage.GetDiary(ctx, 100, "2026-03-01")
+			if err != nil || !reflect.DeepEqual(diary, chat.Diary{Date: "2026-03-01"}) {
+				t.Fatalf("GetDiary(new user) = %+v, %v, want an empty diary of the day", diary, err)
+			}
+
+			for _, tt := range tests {
+				if err := b.storage.SaveDiary(ctx, tt.userID, tt.diary); err != nil {
+					t.Fatalf("%s: SaveDiary: %v", tt.name, err)
+				}
+			}
+
+			for _, tt := range tests {
+				got, err := b.storage.GetDiary(ctx, tt.userID, tt.diary.Date)
+				if err != nil || !reflect.DeepEqual(got, tt.diary) {
+					t.Errorf("%s: GetDiary = %+v, %v, want %+v", tt.name, got, err, tt.diary)
+				}
+			}
+		})
+	}
+}
+
+func TestStorageJournal(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		userID  int64
+		journal chat.Journal
+	}{
+		{"metrics", 1, chat.Journal{Measurements: []chat.Measurement{
+			{Time: at, Metric: chat.MetricSleep, Value: 7.5},
+			{Time: at, Metric: chat.MetricEnergy, Value: 4},
+			{Time: at, Metric: chat.MetricMood, Value: 3},
+			{Time: at, Metric: chat.MetricWeight, Value: 72.4},
+		}}},
+		{"symptom", 2, chat.Journal{Measurements: []chat.Measurement{
+			{Time: at.Add(time.Hour), Metric: chat.MetricSymptom, Symptom: "головная боль"},
+		}}},
+	}
+
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := t.Context()
+
+			// У нового пользователя журнал пуст.
+			journal, err := b.storage.GetJournal(ctx, 100)
+			if err != nil || len(journal.Measurements) != 0 {
+				t.Fatalf("GetJournal(new user) = %+v, %v, want empty", journal, err)
+			}
+
+			for _, tt := range tests {
+				if err := b.storage.SaveJournal(ctx, tt.userID, tt.journal); err != nil {
+					t.Fatalf("%s: SaveJournal: %v", tt.name, err)
+				}
+			}
+
+			for _, tt := range tests {
+				got, err := b.storage.GetJournal(ctx, tt.userID)
+				if err != nil || !reflect.DeepEqual(got, tt.journal) {
+					t.Errorf("%s: GetJournal = %+v, %v, want %+v", tt.name, got, err, tt.journal)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package chat
 
-import "github.com/muzykantov/health-gpt/mygenetics"
+import (
+	"time"
+
+	"github.com/muzykantov/health-gpt/mygenetics"
+)
 
 // UserState определяет состояние пользователя.
 type UserState int
@@ -21,4 +25,16 @@ type User struct {
 	Password  string
 	Tokens    []mygenetics.Token
 	State     UserState
+
+	VoiceReplies bool   // Отвечать голосовыми сообщениями.
+	Language     string // Язык, выбранный командой /language.
+	LanguageCode string // Язык клиента пользователя (например, "en-US").
+
+	Profile       Profile       // Анкета пользователя (команда /profile).
+	Notifications Notifications // Настройки уведомлений (команда /notifications).
+
+	// Анализы на момент последней проверки, с которыми сравниваются новые
+	// результаты. Нулевое время означает, что анализы еще не проверялись.
+	Codelabs          []mygenetics.Codelab
+	CodelabsCheckedAt time.Time
 }
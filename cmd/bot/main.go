@@ -2,26 +2,35 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
+	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/muzykantov/health-gpt/chat"
 	"github.com/muzykantov/health-gpt/chat/storage"
 	"github.com/muzykantov/health-gpt/config"
+	"github.com/muzykantov/health-gpt/export"
 	"github.com/muzykantov/health-gpt/handler"
+	"github.com/muzykantov/health-gpt/i18n"
 	"github.com/muzykantov/health-gpt/llm"
 	"github.com/muzykantov/health-gpt/metrics"
 	"github.com/muzykantov/health-gpt/server"
+	"github.com/muzykantov/health-gpt/server/httpapi"
+	"github.com/muzykantov/health-gpt/server/scheduler"
 	"github.com/muzykantov/health-gpt/server/telegram"
+	"github.com/muzykantov/health-gpt/server/ws"
+	"github.com/muzykantov/health-gpt/speech"
 )
 
-const MsgUnsupportedType = "❌ Тип сообщения не поддерживается."
-
 func main() {
 	// Parse command line flags.
 	configPath := flag.String("config", "config.yaml", "path to configuration file")
+	ratingsPath := flag.String("export-ratings", "", "write answer ratings as CSV to the file and exit")
 	flag.Parse()
 
 	// Load configuration.
@@ -119,7 +128,10 @@ func main() {
 		)
 	}
 
-	var dataStorage server.DataStorage
+	var (
+		dataStorage server.DataStorage
+		boltStorage *storage.Bolt
+	)
 	switch cfg.Storage.Type {
 	case config.TypeFS:
 		dataStorage, err = storage.NewFS(cfg.Storage.FS.Dir)
@@ -128,7 +140,7 @@ func main() {
 		}
 
 	case config.TypeBolt:
-		boltStorage, err := storage.NewBolt(cfg.Storage.Bolt.Path)
+		boltStorage, err = storage.NewBolt(cfg.Storage.Bolt.Path)
 		if err != nil {
 			log.Fatalf("creating bolt db storage: %v", err)
 		}
@@ -139,19 +151,132 @@ func main() {
 		log.Fatalf("unknown storage type: %s", cfg.Storage.Type)
 	}
 
-	unsupported := func() chat.Message {
-		return chat.NewMessage(chat.RoleAssistant, MsgUnsupportedType)
+	// Export answer ratings for offline analysis.
+	if *ratingsPath != "" {
+		if err := exportRatings(dataStorage, *ratingsPath); err != nil {
+			log.Fatalf("exporting ratings: %v", err)
+		}
+		logger.Printf("Ratings exported to %s", *ratingsPath)
+		return
+	}
+
+	cacheTTL := cfg.Storage.Cache.TTL
+	if cacheTTL == 0 {
+		cacheTTL = time.Hour * 24 // default value
+	}
+
+	var cache server.Cache
+	switch cfg.Storage.Cache.Type {
+	case config.CacheTypeMemory, "":
+		cache = expirable.NewLRU[string, any](cfg.Storage.Cache.Size, nil, cacheTTL)
+
+	case config.CacheTypeBolt:
+		var boltCache *storage.BoltCache
+		switch {
+		case cfg.Storage.Cache.Path != "":
+			boltCache, err = storage.NewBoltCache(cfg.Storage.Cache.Path, cacheTTL)
+		case boltStorage != nil:
+			boltCache, err = boltStorage.Cache(cacheTTL)
+		default:
+			log.Fatalf("bolt cache requires cache path or bolt storage")
+		}
+		if err != nil {
+			log.Fatalf("creating bolt db cache: %v", err)
+		}
+		cache = boltCache
+		defer boltCache.Close()
+
+	default:
+		log.Fatalf("unknown cache type: %s", cfg.Storage.Cache.Type)
+	}
+
+	// Transport responses use the language chosen by /language, otherwise
+	// the client language.
+	unsupported := func(user chat.User) chat.Message {
+		lang := i18n.Choose(user.Language, user.LanguageCode)
+		return chat.NewMessage(chat.RoleAssistant, i18n.T(lang, "error.unsupported_type"))
+	}
+
+	transcriptionFailed := func(user chat.User) chat.Message {
+		lang := i18n.Choose(user.Language, user.LanguageCode)
+		return chat.NewMessage(chat.RoleAssistant, i18n.T(lang, "error.transcription"))
+	}
+
+	// Create speech-to-text client for voice messages.
+	var transcriber server.Transcriber
+	switch cfg.Speech.Provider {
+	case "":
+		// Voice messages are unsupported.
+	case config.SpeechProviderOpenAI:
+		transcriber, err = speech.NewOpenAI(
+			cfg.Speech.OpenAI.APIKey,
+			speech.OpenAIWithModel(cfg.Speech.OpenAI.Model),
+			speech.OpenAIWithLanguage(cfg.Speech.Language),
+			speech.OpenAIWithSocksProxy(cfg.Speech.OpenAI.SocksProxy),
+			speech.OpenAIWithBaseURL(cfg.Speech.OpenAI.BaseURL),
+		)
+	case config.SpeechProviderWhisperCPP:
+		transcriber, err = speech.NewWhisperCPP(
+			cfg.Speech.WhisperCPP.URL,
+			speech.WhisperCPPWithLanguage(cfg.Speech.Language),
+		)
+	default:
+		log.Fatalf("unknown speech provider: %s", cfg.Speech.Provider)
+	}
+	if err != nil {
+		log.Fatalf("creating speech client: %v", err)
+	}
+
+	// Create text-to-speech client for voice replies.
+	var synthesizer server.Synthesizer
+	switch cfg.Speech.TTS.Provider {
+	case "":
+		// Voice replies are unavailable.
+	case config.TTSProviderOpenAI:
+		synthesizer, err = speech.NewOpenAITTS(
+			cfg.Speech.TTS.OpenAI.APIKey,
+			speech.OpenAITTSWithModel(cfg.Speech.TTS.OpenAI.Model),
+			speech.OpenAITTSWithVoice(cfg.Speech.TTS.OpenAI.Voice),
+			speech.OpenAITTSWithSocksProxy(cfg.Speech.TTS.OpenAI.SocksProxy),
+			speech.OpenAITTSWithBaseURL(cfg.Speech.TTS.OpenAI.BaseURL),
+		)
+	case config.TTSProviderCommand:
+		if len(cfg.Speech.TTS.Command) == 0 {
+			log.Fatalf("speech tts command is not set")
+		}
+		synthesizer = speech.NewCommand(cfg.Speech.TTS.Command[0], cfg.Speech.TTS.Command[1:]...)
+	default:
+		log.Fatalf("unknown tts provider: %s", cfg.Speech.TTS.Provider)
+	}
+	if err != nil {
+		log.Fatalf("creating tts client: %v", err)
+	}
+
+	if cfg.Export.Font != "" {
+		export.FontPaths = []string{cfg.Export.Font}
+	}
+	if _, err := export.DefaultFont(); err != nil {
+		log.Printf("PDF reports are unavailable: %v", err)
 	}
 
 	// Create and configure the server.
 	srv := &telegram.Server{
 		Token:               cfg.Telegram.Token,
+		APIEndpoint:         cfg.Telegram.APIEndpoint,
+		FileEndpoint:        cfg.Telegram.FileEndpoint,
 		Handler:             handler.Start(),
 		Completion:          ai,
 		Storage:             dataStorage,
+		Cache:               cache,
 		Debug:               cfg.Telegram.Debug,
 		UnsupportedResponse: unsupported,
 		Log:                 logger,
+		EditSelected:        cfg.Telegram.EditSelected,
+		SelectPageSize:      cfg.Telegram.SelectPageSize,
+
+		Transcriber:                 transcriber,
+		TranscriptionFailedResponse: transcriptionFailed,
+		Synthesizer:                 synthesizer,
 	}
 
 	// Setup context with signal handling.
@@ -162,6 +287,99 @@ func main() {
 	)
 	defer stop()
 
+	// Start HTTP API server if enabled.
+	if cfg.HTTPAPI.Enabled {
+		apiAddress := cfg.HTTPAPI.Address
+		if apiAddress == "" {
+			apiAddress = ":8081" // default value
+		}
+
+		apiServer := &httpapi.Server{
+			Addr:       apiAddress,
+			APIKeys:    cfg.HTTPAPI.APIKeys,
+			Handler:    handler.Start(),
+			Completion: ai,
+			Storage:    dataStorage,
+			Cache:      cache,
+			Log:        logger,
+			Format:     server.Format(cfg.HTTPAPI.Format),
+		}
+		go func() {
+			if err := apiServer.ListenAndServe(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Printf("HTTP API server error: %v", err)
+			}
+		}()
+	}
+
+	// Start WebSocket server if enabled.
+	if cfg.WebSocket.Enabled {
+		wsAddress := cfg.WebSocket.Address
+		if wsAddress == "" {
+			wsAddress = ":8082" // default value
+		}
+
+		wsServer := &ws.Server{
+			Addr:           wsAddress,
+			APIKeys:        cfg.WebSocket.APIKeys,
+			AllowedOrigins: cfg.WebSocket.AllowedOrigins,
+			Handler:        handler.Start(),
+			Completion:     ai,
+			Storage:        dataStorage,
+			Cache:          cache,
+			Log:            logger,
+			Format:         server.Format(cfg.WebSocket.Format),
+		}
+		go func() {
+			if err := wsServer.ListenAndServe(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Printf("WebSocket server error: %v", err)
+			}
+		}()
+	}
+
+	// Start notification scheduler if enabled.
+	if cfg.Scheduler.Enabled {
+		location := time.Local
+		if cfg.Scheduler.Timezone != "" {
+			location, err = time.LoadLocation(cfg.Scheduler.Timezone)
+			if err != nil {
+				log.Fatalf("loading scheduler timezone: %v", err)
+			}
+		}
+
+		if cfg.Scheduler.Reminder != "" {
+			handler.ReminderSpec = cfg.Scheduler.Reminder
+		}
+		if cfg.Scheduler.Tip != "" {
+			handler.TipSpec = cfg.Scheduler.Tip
+		}
+		if cfg.Scheduler.Watch != "" {
+			handler.WatchSpec = cfg.Scheduler.Watch
+		}
+		for _, spec := range []string{handler.ReminderSpec, handler.TipSpec, handler.WatchSpec} {
+			if _, err := scheduler.Parse(spec); err != nil {
+				log.Fatalf("parsing scheduler spec: %v", err)
+			}
+		}
+
+		notifications := &scheduler.Scheduler{
+			Handler:   handler.Start(),
+			Notifier:  srv,
+			Completer: ai,
+			Storage:   dataStorage,
+			Cache:     cache,
+			Format:    server.FormatHTML,
+			Log:       logger,
+			Transport: server.TransportTelegram,
+			Location:  location,
+			Interval:  cfg.Scheduler.Interval,
+		}
+		go func() {
+			if err := notifications.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Printf("Scheduler error: %v", err)
+			}
+		}()
+	}
+
 	// Start the server.
 	if err := srv.ListenAndServe(ctx); err != nil {
 		log.Fatalf("starting server: %v", err)
@@ -174,3 +392,23 @@ func main() {
 		}
 	}
 }
+
+// exportRatings writes all answer ratings from the storage to a CSV file.
+func exportRatings(dataStorage server.DataStorage, path string) error {
+	ratings, err := dataStorage.GetRatings(context.Background())
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := export.RatingsCSV(f, ratings); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/handler"
+	"github.com/muzykantov/health-gpt/llm"
+	"github.com/muzykantov/health-gpt/server"
+	"github.com/muzykantov/health-gpt/server/cli"
+)
+
+func main() {
+	// Parse command line flags.
+	dataDir := flag.String("data", "./data-cli", "directory for file storage")
+	scriptPath := flag.String("script", "", "file with scripted LLM responses separated by '---' lines")
+	handlerName := flag.String("handler", "start", "handler to run: start or chat")
+	chatID := flag.Int64("chat-id", 1, "chat and user ID of the session")
+	language := flag.String("lang", "", "client language code of the user, e.g. en")
+	offline := flag.Bool("offline", false, "serve a demo codelab from a local fake MyGenetics API and sign the user in")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "cli: ", log.LstdFlags)
+
+	dataStorage, err := storage.NewFS(*dataDir)
+	if err != nil {
+		log.Fatalf("creating file storage: %v", err)
+	}
+
+	// Echo completer by default, scripted responses if provided.
+	var ai server.ChatCompleter = &llm.Mock{
+		CompleteChatFn: func(ctx context.Context, msgs []chat.Message) (chat.Message, error) {
+			if len(msgs) == 0 {
+				return chat.MsgA("mock: empty context"), nil
+			}
+			return chat.MsgAf("mock: %v", msgs[len(msgs)-1].Content), nil
+		},
+	}
+	if *scriptPath != "" {
+		if ai, err = llm.ScriptFromFile(*scriptPath); err != nil {
+			log.Fatalf("loading script: %v", err)
+		}
+	}
+
+	var h server.Handler
+	switch *handlerName {
+	case "start":
+		h = handler.Start()
+	case "chat":
+		h = handler.Chat()
+	default:
+		log.Fatalf("unknown handler: %s", *handlerName)
+	}
+
+	// Setup context with signal handling.
+	ctx, stop := signal.NotifyContext(
+		context.Background(),
+		syscall.SIGINT,
+		syscall.SIGTERM,
+	)
+	defer stop()
+
+	if *offline {
+		stopOffline, err := startOffline(ctx, dataStorage, *chatID)
+		if err != nil {
+			log.Fatalf("starting offline mode: %v", err)
+		}
+		defer stopOffline()
+
+		fmt.Printf("Offline mode: signed in as %s with a demo codelab.\n", offlineEmail)
+	}
+
+	fmt.Println("Type a message, /command or option number. :q to quit.")
+
+	srv := &cli.Server{
+		In:         os.Stdin,
+		Out:        os.Stdout,
+		ChatID:     *chatID,
+		Language:   *language,
+		Handler:    h,
+		Completion: ai,
+		Storage:    dataStorage,
+		Log:        logger,
+	}
+
+	if err := srv.ListenAndServe(ctx); err != nil && err != context.Canceled {
+		log.Fatalf("cli session: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/genetics"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+	"github.com/muzykantov/health-gpt/server/servertest"
+)
+
+// Credentials of the offline MyGenetics account.
+const (
+	offlineEmail    = "demo@example.com"
+	offlinePassword = "demo"
+)
+
+// offlineFeatures is the demo codelab served by the offline MyGenetics API.
+var offlineFeatures = genetics.FeatureSet{
+	{
+		Name: "Метаболизм кофеина",
+		Genes: []genetics.Gene{
+			{
+				Name:            "CYP1A2",
+				RSID:            "rs762551",
+				Genotype:        "C/C",
+				Interpretations: []string{"Медленный метаболизм кофеина."},
+			},
+		},
+		Conclusions: []string{"Кофеин выводится медленно."},
+		Nutrition:   []string{"Ограничьте кофе до 1 чашки в день."},
+		Checklist:   []string{"Не пить кофе после 14:00"},
+		Risk:        genetics.RiskHigh,
+	},
+	{
+		Name: "Непереносимость лактозы",
+		Genes: []genetics.Gene{
+			{
+				Name:            "MCM6",
+				RSID:            "rs4988235",
+				Genotype:        "C/T",
+				Interpretations: []string{"Лактаза сохраняется во взрослом возрасте."},
+			},
+		},
+		Conclusions: []string{"Молочные продукты переносятся хорошо."},
+		Risk:        genetics.RiskLow,
+	},
+}
+
+// startOffline replaces the MyGenetics API with a local fake serving the demo
+// codelab and signs the user of the session in. The returned function stops
+// the fake API.
+func startOffline(ctx context.Context, dataStorage server.DataStorage, chatID int64) (func(), error) {
+	fake := servertest.NewMyGenetics(offlineEmail, offlinePassword)
+	fake.AddCodelab("DEMO000", "Демо-анализ", offlineFeatures)
+	mygenetics.DefaultClient = fake.Client()
+
+	user, err := dataStorage.GetUser(ctx, chatID)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		user, err = chat.User{ID: chatID, FirstName: "CLI"}, nil
+	}
+	if err != nil {
+		fake.Close()
+		return nil, err
+	}
+
+	user.Email = offlineEmail
+	user.Password = offlinePassword
+	user.Tokens = fake.Tokens()
+	user.State = chat.UserStateAuthorized
+
+	if err := dataStorage.SaveUser(ctx, user); err != nil {
+		fake.Close()
+		return nil, err
+	}
+
+	return fake.Close, nil
+}
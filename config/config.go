@@ -10,10 +10,15 @@ import (
 
 // Bot represents the main configuration structure.
 type Bot struct {
-	Telegram `yaml:"telegram"`
-	Storage  `yaml:"storage"`
-	LLM      `yaml:"llm"`
-	Metrics  `yaml:"metrics"`
+	Telegram  `yaml:"telegram"`
+	Storage   `yaml:"storage"`
+	LLM       `yaml:"llm"`
+	Metrics   `yaml:"metrics"`
+	HTTPAPI   `yaml:"http_api"`
+	WebSocket `yaml:"websocket"`
+	Speech    `yaml:"speech"`
+	Export    `yaml:"export"`
+	Scheduler `yaml:"scheduler"`
 }
 
 // Read parses configuration from reader in YAML format.
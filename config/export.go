@@ -0,0 +1,6 @@
+package config
+
+// Export defines configuration of downloadable reports.
+type Export struct {
+	Font string `yaml:"font"` // TrueType font for PDF reports, looked up in system paths if empty
+}
@@ -0,0 +1,9 @@
+package config
+
+// HTTPAPI defines HTTP JSON API transport configuration.
+type HTTPAPI struct {
+	Enabled bool     `yaml:"enabled"`
+	Address string   `yaml:"address"`
+	APIKeys []string `yaml:"api_keys"`
+	Format  string   `yaml:"format"` // Report markup: html (default), markdown or plain.
+}
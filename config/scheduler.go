@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// Scheduler defines configuration of scheduled notifications.
+type Scheduler struct {
+	Enabled  bool          `yaml:"enabled"`
+	Timezone string        `yaml:"timezone"` // IANA time zone of schedules and quiet hours, local if empty
+	Interval time.Duration `yaml:"interval"` // How often due jobs are checked, one minute if zero
+	Reminder string        `yaml:"reminder"` // Cron spec of action plan reminders
+	Tip      string        `yaml:"tip"`      // Cron spec of weekly tips
+	Watch    string        `yaml:"watch"`    // Cron spec of checks for new test results
+}
@@ -0,0 +1,58 @@
+package config
+
+// Speech defines speech-to-text configuration for voice messages.
+type Speech struct {
+	Provider SpeechProvider `yaml:"provider"` // Voice messages are unsupported if empty
+	Language string         `yaml:"language"` // ISO-639-1 language, detected if empty
+
+	OpenAI     SpeechOpenAI `yaml:"openai"`
+	WhisperCPP WhisperCPP   `yaml:"whisper_cpp"`
+
+	TTS TTS `yaml:"tts"` // Text-to-speech for voice replies
+}
+
+// SpeechProvider defines supported speech-to-text providers.
+type SpeechProvider string
+
+const (
+	SpeechProviderOpenAI     SpeechProvider = "openai"
+	SpeechProviderWhisperCPP SpeechProvider = "whisper_cpp"
+)
+
+// SpeechOpenAI configuration for the OpenAI transcription API.
+type SpeechOpenAI struct {
+	APIKey     string `yaml:"api_key"`
+	Model      string `yaml:"model"`
+	SocksProxy string `yaml:"socks_proxy"`
+	BaseURL    string `yaml:"base_url"`
+}
+
+// WhisperCPP configuration for a local whisper.cpp server.
+type WhisperCPP struct {
+	URL string `yaml:"url"` // Server address, e.g. http://localhost:8178
+}
+
+// TTS defines text-to-speech configuration for voice replies.
+type TTS struct {
+	Provider TTSProvider `yaml:"provider"` // Voice replies are unavailable if empty
+
+	OpenAI  TTSOpenAI `yaml:"openai"`
+	Command []string  `yaml:"command"` // Program and arguments writing OGG/Opus to stdout
+}
+
+// TTSProvider defines supported text-to-speech providers.
+type TTSProvider string
+
+const (
+	TTSProviderOpenAI  TTSProvider = "openai"
+	TTSProviderCommand TTSProvider = "command"
+)
+
+// TTSOpenAI configuration for the OpenAI speech API.
+type TTSOpenAI struct {
+	APIKey     string `yaml:"api_key"`
+	Model      string `yaml:"model"`
+	Voice      string `yaml:"voice"`
+	SocksProxy string `yaml:"socks_proxy"`
+	BaseURL    string `yaml:"base_url"`
+}
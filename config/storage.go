@@ -1,11 +1,14 @@
 package config
 
+import "time"
+
 // Storage defines bot data storage configuration.
 type Storage struct {
 	Type `yaml:"type"`
 
-	FS   `yaml:"fs"`
-	Bolt `yaml:"bolt"`
+	FS    `yaml:"fs"`
+	Bolt  `yaml:"bolt"`
+	Cache `yaml:"cache"`
 }
 
 // Type defines supported storage types.
@@ -25,3 +28,19 @@ type FS struct {
 type Bolt struct {
 	Path string `yaml:"path"`
 }
+
+// Cache configuration for temporary request data (pending questions, etc.).
+type Cache struct {
+	Type CacheType     `yaml:"type"`
+	TTL  time.Duration `yaml:"ttl"`
+	Size int           `yaml:"size"`
+	Path string        `yaml:"path"` // Separate BoltDB file, shares storage DB if empty.
+}
+
+// CacheType defines supported cache types.
+type CacheType string
+
+const (
+	CacheTypeMemory CacheType = "memory"
+	CacheTypeBolt   CacheType = "bolt"
+)
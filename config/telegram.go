@@ -2,6 +2,11 @@ package config
 
 // Telegram defines Telegram bot configuration.
 type Telegram struct {
-	Token string `yaml:"token"`
-	Debug bool   `yaml:"debug"`
+	Token        string `yaml:"token"`
+	Debug        bool   `yaml:"debug"`
+	APIEndpoint  string `yaml:"api_endpoint"`  // Custom Bot API server, e.g. http://localhost:8081/bot%s/%s
+	FileEndpoint string `yaml:"file_endpoint"` // Custom file server, e.g. http://localhost:8081/file/bot%s/%s
+
+	EditSelected   bool `yaml:"edit_selected"`    // Replace the keyboard with the chosen item
+	SelectPageSize int  `yaml:"select_page_size"` // Items per select page, 0 disables pagination
 }
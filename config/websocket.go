@@ -0,0 +1,13 @@
+package config
+
+// WebSocket defines WebSocket transport configuration (web chat widget).
+type WebSocket struct {
+	Enabled bool     `yaml:"enabled"`
+	Address string   `yaml:"address"`
+	APIKeys []string `yaml:"api_keys"`
+	Format  string   `yaml:"format"` // Report markup: html (default), markdown or plain.
+
+	// AllowedOrigins lists pages allowed to connect from a browser without
+	// an API key, e.g. https://example.com.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
@@ -0,0 +1,221 @@
+// Package export renders genetic reports into downloadable documents:
+// PDF with an embedded TrueType font, self-contained HTML and Markdown.
+// Documents are generated locally without external services. Answer
+// ratings are exported as CSV for offline analysis.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/muzykantov/health-gpt/genetics"
+)
+
+var ErrUnknownFormat = errors.New("unknown document format")
+
+// Format is a document format.
+type Format string
+
+// Supported document formats.
+const (
+	FormatPDF      Format = "pdf"
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "md"
+)
+
+// Formats lists supported document formats.
+var Formats = []Format{FormatPDF, FormatHTML, FormatMarkdown}
+
+// ParseFormat returns the format by its name.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// MIME returns the content type of the format.
+func (f Format) MIME() string {
+	switch f {
+	case FormatPDF:
+		return "application/pdf"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Name returns a human readable format name.
+func (f Format) Name() string {
+	switch f {
+	case FormatPDF:
+		return "PDF"
+	case FormatHTML:
+		return "HTML"
+	case FormatMarkdown:
+		return "Markdown"
+	default:
+		return string(f)
+	}
+}
+
+// Report is a genetic report to export.
+type Report struct {
+	Title    string
+	Subtitle string // Codelab name, code and date
+	Locale   genetics.Locale
+	Features genetics.FeatureSet
+
+	// InterpretationTitle heads the AI interpretation (Markdown or
+	// Telegram HTML). The section is omitted if Interpretation is empty.
+	InterpretationTitle string
+	Interpretation      string
+
+	Note string // Small print at the end of the document
+}
+
+// Render renders the report. PDF documents use DefaultFont.
+func Render(format Format, r Report) ([]byte, error) {
+	switch format {
+	case FormatPDF:
+		font, err := DefaultFont()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load font: %w", err)
+		}
+
+		return r.PDF(font)
+
+	case FormatHTML:
+		return r.HTML()
+
+	case FormatMarkdown:
+		return r.Markdown(), nil
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// blockKind defines the style of a block.
+type blockKind int
+
+const (
+	blockTitle blockKind = iota
+	blockSubtitle
+	blockHeading
+	blockSubheading
+	blockParagraph
+	blockBullet
+	blockNote
+)
+
+// block is a paragraph of the document layout shared by all formats.
+type block struct {
+	kind blockKind
+	text string
+}
+
+// blocks lays out the report.
+func (r Report) blocks() []block {
+	blocks := []block{{blockTitle, r.Title}}
+	if r.Subtitle != "" {
+		blocks = append(blocks, block{blockSubtitle, r.Subtitle})
+	}
+
+	section := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+
+		blocks = append(blocks, block{blockSubheading, title})
+		for _, item := range items {
+			blocks = append(blocks, block{blockBullet, item})
+		}
+	}
+
+	for _, f := range r.Features {
+		blocks = append(blocks, block{blockHeading, fmt.Sprintf("%s: %s", r.Locale.Feature, f.Name)})
+		if risk := f.MaxRisk(); risk != genetics.RiskUnknown {
+			blocks = append(blocks, block{blockParagraph, fmt.Sprintf("%s: %s", r.Locale.Risk, r.Locale.RiskName(risk))})
+		}
+
+		genes := make([]string, 0, len(f.Genes))
+		for _, gene := range f.Genes {
+			text := strings.Join(slices.DeleteFunc([]string{gene.Name, gene.RSID, gene.Genotype}, func(s string) bool {
+				return s == ""
+			}), " ")
+			if len(gene.Interpretations) > 0 {
+				text += " — " + strings.Join(gene.Interpretations, " ")
+			}
+			genes = append(genes, text)
+		}
+
+		section(r.Locale.Genes, genes)
+		section(r.Locale.Nutrition, f.Nutrition)
+		section(r.Locale.Additional, f.Additional)
+		section(r.Locale.Checklist, f.Checklist)
+		section(r.Locale.Conclusions, f.Conclusions)
+	}
+
+	if strings.TrimSpace(r.Interpretation) != "" {
+		blocks = append(blocks, block{blockHeading, r.InterpretationTitle})
+		blocks = append(blocks, textBlocks(r.Interpretation)...)
+	}
+
+	if r.Note != "" {
+		blocks = append(blocks, block{blockNote, r.Note})
+	}
+
+	return blocks
+}
+
+var (
+	textTags     = regexp.MustCompile(`<[^<>]+>`)
+	textHeading  = regexp.MustCompile(`^#{1,6}\s+`)
+	textBullet   = regexp.MustCompile(`^[-*+•]\s+`)
+	textMarkup   = regexp.MustCompile("\\*\\*|__|~~|`+")
+	textLinks    = regexp.MustCompile(`\[([^\[\]\n]+)\]\(([^\s()]+)\)`)
+	textFence    = regexp.MustCompile("^```")
+	textBoldLine = regexp.MustCompile(`^\*\*([^*]+)\*\*:?$`)
+)
+
+// textBlocks converts model output (Markdown or Telegram HTML) into blocks.
+func textBlocks(s string) []block {
+	s = textTags.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	var blocks []block
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || textFence.MatchString(line) {
+			continue
+		}
+
+		kind := blockParagraph
+		switch {
+		case textHeading.MatchString(line):
+			kind, line = blockSubheading, textHeading.ReplaceAllString(line, "")
+		case textBoldLine.MatchString(line):
+			kind = blockSubheading
+		case textBullet.MatchString(line):
+			kind, line = blockBullet, textBullet.ReplaceAllString(line, "")
+		}
+
+		line = textLinks.ReplaceAllString(line, "$1 ($2)")
+		line = textMarkup.ReplaceAllString(line, "")
+
+		blocks = append(blocks, block{kind, strings.TrimSpace(line)})
+	}
+
+	return blocks
+}
@@ -0,0 +1,233 @@
+package export_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/export"
+	"github.com/muzykantov/health-gpt/genetics"
+)
+
+var testReport = export.Report{
+	Title:    "Генетический отчет",
+	Subtitle: "Питание (WN0000T)",
+	Locale:   genetics.LocaleRussian,
+	Features: genetics.FeatureSet{
+		{
+			Name: "Метаболизм кофеина",
+			Genes: []genetics.Gene{
+				{Name: "CYP1A2", Interpretations: []string{"Медленный метаболизм <кофеина>."}},
+			},
+			Nutrition:   []string{"Ограничьте кофе до 1 чашки в день."},
+			Conclusions: []string{"Кофеин выводится медленно. 🐢"},
+		},
+	},
+	InterpretationTitle: "Интерпретация ИИ",
+	Interpretation:      "## Итог\n- **Кофе**: умеренно\n<b>Чай</b> можно &amp; нужно.",
+	Note:                "Не является медицинским заключением.",
+}
+
+func TestMarkdown(t *testing.T) {
+	got := string(testReport.Markdown())
+
+	for _, want := range []string{
+		"# Генетический отчет\n\n_Питание (WN0000T)_\n\n## Признак: Метаболизм кофеина\n\n### Входящие гены\n" +
+			"- CYP1A2 — Медленный метаболизм <кофеина>.\n",
+		"### Итог\n- Кофе: умеренно\n\nЧай можно & нужно.\n",
+		"> Не является медицинским заключением.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown() does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	data, err := testReport.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+
+	for _, want := range []string{
+		"<title>Генетический отчет</title>",
+		"<h2>Признак: Метаболизм кофеина</h2>",
+		"<li>CYP1A2 — Медленный метаболизм &lt;кофеина&gt;.</li>",
+		"<p>Чай можно &amp; нужно.</p>",
+		`<p class="note">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML() does not contain %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "<b>") || strings.Contains(got, "http") && strings.Contains(got, "<link") {
+		t.Errorf("HTML() is not self-contained or not sanitized:\n%s", got)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	font, err := export.DefaultFont()
+	if err != nil {
+		t.Fatalf("no font available, install DejaVu fonts or set export.FontPaths: %v", err)
+	}
+
+	// Enough features for several pages.
+	report := testReport
+	for range 30 {
+		report.Features = append(report.Features, testReport.Features[0])
+	}
+
+	data, err := report.PDF(font)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("not a PDF file")
+	}
+
+	checkXref(t, data)
+
+	text := pdfText(t, data)
+	pages := regexp.MustCompile(`\n1 / (\d+)\n`).FindStringSubmatch(text)
+	if pages == nil || pages[1] == "1" {
+		t.Fatalf("report is not paginated:\n%s", text)
+	}
+
+	for _, want := range []string{"Генетический отчет", "CYP1A2", "Интерпретация ИИ", "Чай можно & нужно.", pages[1] + " / " + pages[1]} {
+		if !strings.Contains(text, want) {
+			t.Errorf("PDF text does not contain %q:\n%s", want, text)
+		}
+	}
+
+	if strings.Contains(text, "🐢") {
+		t.Error("PDF contains characters missing in the font")
+	}
+}
+
+func TestParseFont(t *testing.T) {
+	if _, err := export.ParseFont([]byte("not a font")); !errors.Is(err, export.ErrInvalidFont) {
+		t.Errorf("ParseFont() error = %v, want %v", err, export.ErrInvalidFont)
+	}
+
+	font, err := export.DefaultFont()
+	if err != nil {
+		t.Fatalf("no font available, install DejaVu fonts or set export.FontPaths: %v", err)
+	}
+
+	if _, ok := font.Glyph('Ж'); !ok {
+		t.Error("font has no Cyrillic glyphs")
+	}
+
+	if w := font.TextWidth("WW", 10); w <= font.TextWidth("ii", 10) {
+		t.Errorf("TextWidth(WW) = %v is not wider than ii", w)
+	}
+}
+
+func TestRender(t *testing.T) {
+	if _, err := export.Render("doc", testReport); !errors.Is(err, export.ErrUnknownFormat) {
+		t.Errorf("Render() error = %v, want %v", err, export.ErrUnknownFormat)
+	}
+
+	if _, err := export.ParseFormat("md"); err != nil {
+		t.Errorf("ParseFormat() error = %v", err)
+	}
+}
+
+// checkXref verifies that cross-reference entries point to objects.
+func checkXref(t *testing.T, data []byte) {
+	t.Helper()
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+
+	xref, _ := strconv.Atoi(string(m[1]))
+	lines := strings.Split(string(data[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+
+	for id := 1; id < count; id++ {
+		offset, _ := strconv.Atoi(lines[2+id][:10])
+		if !bytes.HasPrefix(data[offset:], []byte(strconv.Itoa(id)+" 0 obj\n")) {
+			t.Errorf("xref entry %d points to %q", id, data[offset:offset+10])
+		}
+	}
+}
+
+// pdfText extracts text shown on pages using the ToUnicode map.
+func pdfText(t *testing.T, data []byte) string {
+	t.Helper()
+
+	var streams []string
+	for _, m := range regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode[^>]*>>\nstream\n`).FindAllSubmatchIndex(data, -1) {
+		n, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+
+		zr, err := zlib.NewReader(bytes.NewReader(data[m[1] : m[1]+n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams = append(streams, string(stream))
+	}
+
+	glyphs := make(map[string]string)
+	for _, stream := range streams {
+		for _, m := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]+)>`).FindAllStringSubmatch(stream, -1) {
+			code, _ := strconv.ParseUint(m[2], 16, 32)
+			glyphs[m[1]] = string(rune(code))
+		}
+	}
+
+	var text strings.Builder
+	for _, stream := range streams {
+		for _, m := range regexp.MustCompile(`<([0-9A-F]*)> Tj`).FindAllStringSubmatch(stream, -1) {
+			for i := 0; i+4 <= len(m[1]); i += 4 {
+				r, ok := glyphs[m[1][i:i+4]]
+				if !ok {
+					r = " "
+				}
+				text.WriteString(r)
+			}
+			text.WriteString("\n")
+		}
+	}
+
+	return text.String()
+}
+
+func TestRatingsCSV(t *testing.T) {
+	created := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	ratings := []chat.Rating{
+		{ID: "b", ChatID: 1, UserID: 2, Prompt: "codelab", Model: "gpt-4o", CreatedAt: created.Add(time.Hour)},
+		{
+			ID: "a", ChatID: 1, UserID: 2, Prompt: "chat", Model: "gpt-4o", Score: 0.9, QuestionID: "q",
+			Value: chat.RatingInaccurate, Comment: "Кофе, \"чай\"", CreatedAt: created, RatedAt: created.Add(time.Minute),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := export.RatingsCSV(&buf, ratings); err != nil {
+		t.Fatalf("RatingsCSV: %v", err)
+	}
+
+	want := "id,chat_id,user_id,prompt,model,validator_score,question_id,rating,comment,created_at,rated_at\n" +
+		"a,1,2,chat,gpt-4o,0.90,q,inaccurate,\"Кофе, \"\"чай\"\"\",2025-03-01T09:00:00Z,2025-03-01T09:01:00Z\n" +
+		"b,1,2,codelab,gpt-4o,0.00,,,,2025-03-01T10:00:00Z,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("RatingsCSV =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,359 @@
+package export
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+var ErrInvalidFont = errors.New("invalid TrueType font")
+
+// FontPaths are searched by DefaultFont. The font must be a TrueType font
+// with Cyrillic glyphs.
+var FontPaths = []string{
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/TTF/DejaVuSans.ttf",
+	"/usr/share/fonts/dejavu/DejaVuSans.ttf",
+	"/usr/local/share/fonts/DejaVuSans.ttf",
+	"/Library/Fonts/Arial Unicode.ttf",
+	`C:\Windows\Fonts\arial.ttf`,
+}
+
+var defaultFont struct {
+	once sync.Once
+	font *Font
+	err  error
+}
+
+// DefaultFont loads the first font found in FontPaths. The font is loaded
+// once; change FontPaths before the first call.
+func DefaultFont() (*Font, error) {
+	defaultFont.once.Do(func() {
+		for _, path := range FontPaths {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+
+			defaultFont.font, defaultFont.err = LoadFont(path)
+			return
+		}
+
+		defaultFont.err = fmt.Errorf("no font found in %s", strings.Join(FontPaths, ", "))
+	})
+
+	return defaultFont.font, defaultFont.err
+}
+
+// Font is a TrueType font embedded into PDF documents.
+type Font struct {
+	Name string // PostScript name
+
+	data       []byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	capHeight  int
+	bbox       [4]int
+	advances   []int           // Advance widths by glyph ID
+	glyphs     map[rune]uint16 // Glyph IDs by character
+}
+
+// LoadFont reads a TrueType font file.
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font: %w", err)
+	}
+
+	return ParseFont(data)
+}
+
+// ParseFont parses a TrueType font. Only the tables needed for text layout
+// and embedding are read; the font is embedded as is.
+func ParseFont(data []byte) (*Font, error) {
+	tables, err := fontTables(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("%w: no %s table", ErrInvalidFont, tag)
+		}
+	}
+
+	f := &Font{data: data, Name: "Embedded"}
+
+	head := tables["head"]
+	if len(head) < 54 {
+		return nil, fmt.Errorf("%w: short head table", ErrInvalidFont)
+	}
+	f.unitsPerEm = int(u16(head, 18))
+	if f.unitsPerEm == 0 {
+		return nil, fmt.Errorf("%w: zero units per em", ErrInvalidFont)
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(u16(head, 36+i*2)))
+	}
+
+	hhea := tables["hhea"]
+	if len(hhea) < 36 {
+		return nil, fmt.Errorf("%w: short hhea table", ErrInvalidFont)
+	}
+	f.ascent = int(int16(u16(hhea, 4)))
+	f.descent = int(int16(u16(hhea, 6)))
+	f.capHeight = f.ascent
+
+	if os2 := tables["OS/2"]; len(os2) >= 90 && u16(os2, 0) >= 2 {
+		f.capHeight = int(int16(u16(os2, 88)))
+	}
+
+	maxp := tables["maxp"]
+	if len(maxp) < 6 {
+		return nil, fmt.Errorf("%w: short maxp table", ErrInvalidFont)
+	}
+	numGlyphs := int(u16(maxp, 4))
+
+	metrics := int(u16(hhea, 34))
+	hmtx := tables["hmtx"]
+	if metrics == 0 || len(hmtx) < metrics*4 {
+		return nil, fmt.Errorf("%w: short hmtx table", ErrInvalidFont)
+	}
+	f.advances = make([]int, numGlyphs)
+	for i := range f.advances {
+		f.advances[i] = int(u16(hmtx, min(i, metrics-1)*4))
+	}
+
+	if f.glyphs, err = parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+
+	if name := postScriptName(tables["name"]); name != "" {
+		f.Name = name
+	}
+
+	return f, nil
+}
+
+// Glyph returns the glyph ID of the character and false if the font
+// has no glyph for it.
+func (f *Font) Glyph(r rune) (uint16, bool) {
+	gid, ok := f.glyphs[r]
+	return gid, ok && gid != 0
+}
+
+// Width returns the advance width of the glyph in 1/1000 of the font size.
+func (f *Font) Width(gid uint16) float64 {
+	if int(gid) >= len(f.advances) {
+		return 0
+	}
+
+	return float64(f.advances[gid]) * 1000 / float64(f.unitsPerEm)
+}
+
+// TextWidth returns the width of the text at the font size in points.
+// Characters without glyphs are ignored.
+func (f *Font) TextWidth(s string, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		if gid, ok := f.Glyph(r); ok {
+			w += f.Width(gid)
+		}
+	}
+
+	return w * size / 1000
+}
+
+// scale converts font units to 1/1000 of the font size.
+func (f *Font) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+func fontTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("%w: short header", ErrInvalidFont)
+	}
+
+	switch version := u32(data, 0); version {
+	case 0x00010000, 0x74727565: // TrueType outlines ("true")
+	default:
+		return nil, fmt.Errorf("%w: unsupported version %#x", ErrInvalidFont, version)
+	}
+
+	numTables := int(u16(data, 4))
+	if len(data) < 12+numTables*16 {
+		return nil, fmt.Errorf("%w: short table directory", ErrInvalidFont)
+	}
+
+	tables := make(map[string][]byte, numTables)
+	for i := range numTables {
+		record := 12 + i*16
+		tag := string(data[record : record+4])
+		offset, length := int(u32(data, record+8)), int(u32(data, record+12))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("%w: table %s out of bounds", ErrInvalidFont, tag)
+		}
+
+		tables[tag] = data[offset : offset+length]
+	}
+
+	return tables, nil
+}
+
+// parseCmap reads the Unicode character map (format 12 or 4).
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, fmt.Errorf("%w: short cmap table", ErrInvalidFont)
+	}
+
+	var format4, format12 []byte
+	for i := range int(u16(cmap, 2)) {
+		record := 4 + i*8
+		if record+8 > len(cmap) {
+			break
+		}
+
+		platform, encoding, offset := u16(cmap, record), u16(cmap, record+2), int(u32(cmap, record+4))
+		if offset+4 > len(cmap) {
+			continue
+		}
+
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+
+		switch u16(cmap, offset) {
+		case 4:
+			format4 = cmap[offset:]
+		case 12:
+			format12 = cmap[offset:]
+		}
+	}
+
+	glyphs := make(map[rune]uint16)
+
+	switch {
+	case format12 != nil:
+		if len(format12) < 16 {
+			return nil, fmt.Errorf("%w: short cmap subtable", ErrInvalidFont)
+		}
+
+		groups := int(u32(format12, 12))
+		if len(format12) < 16+groups*12 {
+			return nil, fmt.Errorf("%w: short cmap subtable", ErrInvalidFont)
+		}
+
+		for i := range groups {
+			group := 16 + i*12
+			start, end, gid := u32(format12, group), u32(format12, group+4), u32(format12, group+8)
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				glyphs[rune(c)] = uint16(gid + c - start)
+			}
+		}
+
+	case format4 != nil:
+		if len(format4) < 14 {
+			return nil, fmt.Errorf("%w: short cmap subtable", ErrInvalidFont)
+		}
+
+		segments := int(u16(format4, 6)) / 2
+		var (
+			ends    = 14
+			starts  = ends + segments*2 + 2
+			deltas  = starts + segments*2
+			offsets = deltas + segments*2
+		)
+		if len(format4) < offsets+segments*2 {
+			return nil, fmt.Errorf("%w: short cmap subtable", ErrInvalidFont)
+		}
+
+		for i := range segments {
+			start, end := int(u16(format4, starts+i*2)), int(u16(format4, ends+i*2))
+			delta, rangeOffset := int(u16(format4, deltas+i*2)), int(u16(format4, offsets+i*2))
+
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				gid := (c + delta) & 0xFFFF
+				if rangeOffset != 0 {
+					at := offsets + i*2 + rangeOffset + (c-start)*2
+					if at+2 > len(format4) {
+						continue
+					}
+
+					if gid = int(u16(format4, at)); gid != 0 {
+						gid = (gid + delta) & 0xFFFF
+					}
+				}
+
+				glyphs[rune(c)] = uint16(gid)
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: no Unicode cmap", ErrInvalidFont)
+	}
+
+	return glyphs, nil
+}
+
+// postScriptName reads the PostScript name (name ID 6) of the font.
+func postScriptName(name []byte) string {
+	if len(name) < 6 {
+		return ""
+	}
+
+	count, storage := int(u16(name, 2)), int(u16(name, 4))
+	for i := range count {
+		record := 6 + i*12
+		if record+12 > len(name) {
+			break
+		}
+
+		platform, nameID := u16(name, record), u16(name, record+6)
+		length, offset := int(u16(name, record+8)), storage+int(u16(name, record+10))
+		if nameID != 6 || offset+length > len(name) {
+			continue
+		}
+
+		raw := name[offset : offset+length]
+
+		var s string
+		switch platform {
+		case 1: // Macintosh, single byte
+			s = string(raw)
+		case 0, 3: // Unicode and Windows, UTF-16BE
+			units := make([]uint16, len(raw)/2)
+			for j := range units {
+				units[j] = u16(raw, j*2)
+			}
+			s = string(utf16.Decode(units))
+		default:
+			continue
+		}
+
+		// PDF names must not contain delimiters.
+		s = strings.Map(func(r rune) rune {
+			if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+				return -1
+			}
+			return r
+		}, s)
+		if s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+func u16(b []byte, at int) uint16 {
+	return binary.BigEndian.Uint16(b[at:])
+}
+
+func u32(b []byte, at int) uint32 {
+	return binary.BigEndian.Uint32(b[at:])
+}
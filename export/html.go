@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// htmlElement is a block or a list of consecutive bullets.
+type htmlElement struct {
+	Tag   string
+	Class string
+	Text  string
+	Items []string
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, "DejaVu Sans", Arial, sans-serif;
+       max-width: 800px; margin: 2em auto; padding: 0 1em; color: #222; line-height: 1.5; }
+h1, h2 { color: #1a3366; }
+h2 { border-bottom: 1px solid #dde3ee; padding-bottom: .2em; margin-top: 1.6em; }
+h3 { color: #33598c; font-size: 1em; margin: 1em 0 .3em; }
+ul { margin: .2em 0; }
+.subtitle, .note { color: #737373; }
+.note { font-size: .8em; margin-top: 2.5em; }
+@media print { body { margin: 0; } h2 { break-after: avoid; } }
+</style>
+</head>
+<body>
+{{range .Elements}}{{if .Items}}<ul>
+{{range .Items}}<li>{{.}}</li>
+{{end}}</ul>
+{{else if eq .Tag "h1"}}<h1>{{.Text}}</h1>
+{{else if eq .Tag "h2"}}<h2>{{.Text}}</h2>
+{{else if eq .Tag "h3"}}<h3>{{.Text}}</h3>
+{{else}}<p{{with .Class}} class="{{.}}"{{end}}>{{.Text}}</p>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// htmlTags maps block kinds to elements.
+var htmlTags = map[blockKind][2]string{
+	blockTitle:      {"h1", ""},
+	blockSubtitle:   {"p", "subtitle"},
+	blockHeading:    {"h2", ""},
+	blockSubheading: {"h3", ""},
+	blockParagraph:  {"p", ""},
+	blockNote:       {"p", "note"},
+}
+
+// HTML renders the report as a self-contained HTML page.
+func (r Report) HTML() ([]byte, error) {
+	var elements []htmlElement
+	for _, b := range r.blocks() {
+		if b.kind == blockBullet {
+			if n := len(elements); n > 0 && elements[n-1].Items != nil {
+				elements[n-1].Items = append(elements[n-1].Items, b.text)
+			} else {
+				elements = append(elements, htmlElement{Items: []string{b.text}})
+			}
+			continue
+		}
+
+		tag := htmlTags[b.kind]
+		elements = append(elements, htmlElement{Tag: tag[0], Class: tag[1], Text: b.text})
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, struct {
+		Title    string
+		Elements []htmlElement
+	}{r.Title, elements}); err != nil {
+		return nil, fmt.Errorf("failed to render html: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
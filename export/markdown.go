@@ -0,0 +1,39 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// markdownPrefixes maps block kinds to line prefixes and suffixes.
+var markdownPrefixes = map[blockKind][2]string{
+	blockTitle:      {"# ", ""},
+	blockSubtitle:   {"_", "_"},
+	blockHeading:    {"## ", ""},
+	blockSubheading: {"### ", ""},
+	blockParagraph:  {"", ""},
+	blockBullet:     {"- ", ""},
+	blockNote:       {"> ", ""},
+}
+
+// Markdown renders the report as a Markdown document.
+func (r Report) Markdown() []byte {
+	var (
+		buf  bytes.Buffer
+		prev blockKind
+	)
+
+	for i, b := range r.blocks() {
+		// Lists follow their headings and are not separated by blank lines.
+		list := b.kind == blockBullet && (prev == blockBullet || prev == blockSubheading)
+		if i > 0 && !list {
+			buf.WriteString("\n")
+		}
+
+		affix := markdownPrefixes[b.kind]
+		fmt.Fprintf(&buf, "%s%s%s\n", affix[0], b.text, affix[1])
+		prev = b.kind
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,371 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// A4 page layout in points.
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	pageMargin = 56.0
+	bulletGap  = 14.0
+)
+
+// pdfStyle defines how a block is typeset.
+type pdfStyle struct {
+	size    float64    // Font size
+	leading float64    // Line height relative to the size
+	before  float64    // Space before the block
+	indent  float64    // Left indent
+	color   [3]float64 // Fill color (RGB)
+}
+
+var pdfStyles = map[blockKind]pdfStyle{
+	blockTitle:      {size: 20, leading: 1.3, before: 0, color: [3]float64{0.10, 0.20, 0.40}},
+	blockSubtitle:   {size: 10, leading: 1.4, before: 4, color: [3]float64{0.45, 0.45, 0.45}},
+	blockHeading:    {size: 14, leading: 1.3, before: 18, color: [3]float64{0.10, 0.20, 0.40}},
+	blockSubheading: {size: 11, leading: 1.3, before: 9, color: [3]float64{0.20, 0.35, 0.55}},
+	blockParagraph:  {size: 10, leading: 1.45, before: 4},
+	blockBullet:     {size: 10, leading: 1.45, before: 2, indent: bulletGap},
+	blockNote:       {size: 8, leading: 1.4, before: 24, color: [3]float64{0.45, 0.45, 0.45}},
+}
+
+// PDF renders the report as a PDF document with the font embedded.
+// Characters missing in the font (such as emoji) are skipped.
+func (r Report) PDF(font *Font) ([]byte, error) {
+	p := &pdfWriter{font: font, used: make(map[uint16]rune)}
+	p.newPage()
+
+	for _, b := range r.blocks() {
+		p.block(b)
+	}
+
+	return p.document(r.Title)
+}
+
+// pdfWriter typesets blocks into pages.
+type pdfWriter struct {
+	font  *Font
+	used  map[uint16]rune // Glyphs used in the document
+	pages []*bytes.Buffer // Content streams
+	y     float64         // Baseline of the next line
+}
+
+func (p *pdfWriter) newPage() {
+	p.pages = append(p.pages, new(bytes.Buffer))
+	p.y = pageHeight - pageMargin
+}
+
+// page returns the content stream of the current page.
+func (p *pdfWriter) page() *bytes.Buffer {
+	return p.pages[len(p.pages)-1]
+}
+
+// block typesets a block, wrapping lines and breaking pages.
+func (p *pdfWriter) block(b block) {
+	style := pdfStyles[b.kind]
+
+	text := p.printable(b.text)
+	if text == "" {
+		return
+	}
+
+	lineHeight := style.size * style.leading
+	width := pageWidth - 2*pageMargin - style.indent
+	lines := p.wrap(text, style.size, width)
+
+	// Headings are kept with the next line.
+	keep := lineHeight
+	if b.kind == blockHeading || b.kind == blockSubheading {
+		keep += 2 * lineHeight
+	}
+
+	if p.y != pageHeight-pageMargin {
+		p.y -= style.before
+	}
+	if p.y-keep < pageMargin {
+		p.newPage()
+	}
+
+	for i, line := range lines {
+		if p.y-lineHeight < pageMargin {
+			p.newPage()
+		}
+		p.y -= style.size
+
+		x := pageMargin + style.indent
+		if b.kind == blockBullet && i == 0 {
+			bullet := "•"
+			if _, ok := p.font.Glyph('•'); !ok {
+				bullet = "-"
+			}
+			p.text(p.page(), pageMargin+style.indent-bulletGap+4, p.y, style, bullet)
+		}
+
+		p.text(p.page(), x, p.y, style, line)
+		p.y -= lineHeight - style.size
+	}
+}
+
+// printable drops characters without glyphs and extra spaces.
+func (p *pdfWriter) printable(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' {
+			return ' '
+		}
+		if _, ok := p.font.Glyph(r); !ok && r != ' ' {
+			return -1
+		}
+		return r
+	}, s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// wrap splits text into lines not wider than width.
+func (p *pdfWriter) wrap(text string, size, width float64) []string {
+	var (
+		lines []string
+		line  string
+	)
+
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+
+		if p.font.TextWidth(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+
+		// Words wider than the line are broken by characters.
+		line = ""
+		for _, r := range word {
+			if line != "" && p.font.TextWidth(line+string(r), size) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// text shows a single line at the position.
+func (p *pdfWriter) text(page *bytes.Buffer, x, y float64, style pdfStyle, s string) {
+	var hex strings.Builder
+	for _, r := range s {
+		gid, ok := p.font.Glyph(r)
+		if !ok {
+			continue
+		}
+
+		p.used[gid] = r
+		fmt.Fprintf(&hex, "%04X", gid)
+	}
+
+	c := style.color
+	fmt.Fprintf(page, "BT %.2f %.2f %.2f rg /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n",
+		c[0], c[1], c[2], style.size, x, y, hex.String())
+}
+
+// document assembles the PDF file.
+func (p *pdfWriter) document(title string) ([]byte, error) {
+	// Page numbers are added when the number of pages is known.
+	footer := pdfStyles[blockNote]
+	for i, page := range p.pages {
+		number := fmt.Sprintf("%d / %d", i+1, len(p.pages))
+		x := (pageWidth - p.font.TextWidth(number, footer.size)) / 2
+		p.text(page, x, pageMargin/2, footer, number)
+	}
+
+	out := &pdfFile{}
+	out.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	const (
+		catalogID = iota + 1
+		pagesID
+		fontID
+		cidFontID
+		descriptorID
+		fontFileID
+		toUnicodeID
+		infoID
+		firstPageID
+	)
+
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageID+i*2)
+	}
+
+	out.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	out.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(p.pages)))
+
+	name := "/" + p.font.Name
+	out.object(fontID, fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont %s /Encoding /Identity-H "+
+			"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, cidFontID, toUnicodeID,
+	))
+	out.object(cidFontID, fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont %s "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW 0 /W [%s] >>",
+		name, descriptorID, p.widths(),
+	))
+
+	f := p.font
+	out.object(descriptorID, fmt.Sprintf(
+		"<< /Type /FontDescriptor /FontName %s /Flags 32 /FontBBox [%d %d %d %d] "+
+			"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.capHeight), fontFileID,
+	))
+
+	if err := out.stream(fontFileID, fmt.Sprintf("/Length1 %d", len(f.data)), f.data); err != nil {
+		return nil, err
+	}
+
+	if err := out.stream(toUnicodeID, "", []byte(p.toUnicode())); err != nil {
+		return nil, err
+	}
+
+	out.object(infoID, fmt.Sprintf("<< /Title %s /Producer (health-gpt) /CreationDate (D:%s) >>",
+		pdfText(title), time.Now().UTC().Format("20060102150405Z")))
+
+	for i, content := range p.pages {
+		pageID := firstPageID + i*2
+		out.object(pageID, fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] "+
+				"/Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesID, pageWidth, pageHeight, fontID, pageID+1,
+		))
+
+		if err := out.stream(pageID+1, "", content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	return out.finish(catalogID, infoID), nil
+}
+
+// widths returns the /W array of the used glyphs.
+func (p *pdfWriter) widths() string {
+	var sb strings.Builder
+	for _, gid := range slices.Sorted(maps.Keys(p.used)) {
+		fmt.Fprintf(&sb, "%d [%d] ", gid, int(p.font.Width(gid)+0.5))
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// toUnicode returns a CMap mapping the used glyphs to characters,
+// so that text can be searched and copied.
+func (p *pdfWriter) toUnicode() string {
+	var sb strings.Builder
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	gids := slices.Sorted(maps.Keys(p.used))
+	for chunk := range slices.Chunk(gids, 100) {
+		fmt.Fprintf(&sb, "%d beginbfchar\n", len(chunk))
+		for _, gid := range chunk {
+			sb.WriteString(fmt.Sprintf("<%04X> <", gid))
+			for _, unit := range utf16.Encode([]rune{p.used[gid]}) {
+				fmt.Fprintf(&sb, "%04X", unit)
+			}
+			sb.WriteString(">\n")
+		}
+		sb.WriteString("endbfchar\n")
+	}
+
+	sb.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	return sb.String()
+}
+
+// pdfText encodes a text string as UTF-16BE with a byte order mark.
+func pdfText(s string) string {
+	var sb strings.Builder
+	sb.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&sb, "%04X", unit)
+	}
+	sb.WriteString(">")
+
+	return sb.String()
+}
+
+// pdfFile writes numbered objects and the cross-reference table.
+type pdfFile struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (f *pdfFile) object(id int, body string) {
+	if f.offsets == nil {
+		f.offsets = make(map[int]int)
+	}
+
+	f.offsets[id] = f.buf.Len()
+	fmt.Fprintf(&f.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes a Flate-compressed stream object.
+func (f *pdfFile) stream(id int, dict string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return fmt.Errorf("failed to compress stream: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress stream: %w", err)
+	}
+
+	if dict != "" {
+		dict = " " + dict
+	}
+
+	f.object(id, fmt.Sprintf("<< /Length %d /Filter /FlateDecode%s >>\nstream\n%s\nendstream",
+		compressed.Len(), dict, compressed.Bytes()))
+
+	return nil
+}
+
+func (f *pdfFile) finish(rootID, infoID int) []byte {
+	size := len(f.offsets) + 1
+
+	xref := f.buf.Len()
+	fmt.Fprintf(&f.buf, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for id := 1; id < size; id++ {
+		fmt.Fprintf(&f.buf, "%010d 00000 n \n", f.offsets[id])
+	}
+
+	fmt.Fprintf(&f.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		size, rootID, infoID, xref)
+
+	return f.buf.Bytes()
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+)
+
+// ratingColumns is the header row of the ratings export.
+var ratingColumns = []string{
+	"id", "chat_id", "user_id", "prompt", "model", "validator_score",
+	"question_id", "rating", "comment", "created_at", "rated_at",
+}
+
+// RatingsCSV writes answer ratings as CSV for offline analysis, oldest
+// answers first. Unrated answers have an empty rating.
+func RatingsCSV(w io.Writer, ratings []chat.Rating) error {
+	ratings = slices.Clone(ratings)
+	slices.SortStableFunc(ratings, func(a, b chat.Rating) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ratingColumns); err != nil {
+		return err
+	}
+
+	for _, r := range ratings {
+		record := []string{
+			r.ID,
+			strconv.FormatInt(r.ChatID, 10),
+			strconv.FormatInt(r.UserID, 10),
+			r.Prompt,
+			r.Model,
+			strconv.FormatFloat(r.Score, 'f', 2, 64),
+			r.QuestionID,
+			string(r.Value),
+			r.Comment,
+			csvTime(r.CreatedAt),
+			csvTime(r.RatedAt),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvTime formats t in RFC 3339, an empty string for the zero time.
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,195 @@
+package genetics
+
+import (
+	"slices"
+	"strings"
+)
+
+// Status is the result of comparing a feature or a gene of two sets.
+type Status string
+
+// Comparison statuses.
+const (
+	StatusSame      Status = "same"       // Present in both sets with equal interpretations
+	StatusChanged   Status = "changed"    // Present in both sets with different interpretations
+	StatusOnlyLeft  Status = "only_left"  // Present in the left set only
+	StatusOnlyRight Status = "only_right" // Present in the right set only
+)
+
+// GeneDiff compares interpretations of a gene.
+type GeneDiff struct {
+	Name   string
+	Status Status
+	Left   []string // Interpretations in the left set
+	Right  []string // Interpretations in the right set
+}
+
+// FeatureDiff compares a feature of two sets. Features are equal when their
+// genes have equal interpretations, since recommendations follow from genes.
+type FeatureDiff struct {
+	Name   string
+	Status Status
+	Genes  []GeneDiff // Genes of both features, see Changed for differences
+	Left   Feature    // Feature of the left set, empty if StatusOnlyRight
+	Right  Feature    // Feature of the right set, empty if StatusOnlyLeft
+}
+
+// Changed returns genes with different interpretations or present in one
+// feature only.
+func (d FeatureDiff) Changed() []GeneDiff {
+	var genes []GeneDiff
+	for _, g := range d.Genes {
+		if g.Status != StatusSame {
+			genes = append(genes, g)
+		}
+	}
+
+	return genes
+}
+
+// Comparison is the result of Compare.
+type Comparison struct {
+	Features []FeatureDiff // Features of the left set in order, then the rest of the right set
+}
+
+// Compare aligns features and genes of two sets by name, ignoring case and
+// extra spaces. Features with the same name in one set are matched in order.
+func Compare(left, right FeatureSet) Comparison {
+	rightIndex := make(map[string][]int)
+	for i, f := range right {
+		key := compareKey(f.Name)
+		rightIndex[key] = append(rightIndex[key], i)
+	}
+
+	matched := make([]bool, len(right))
+
+	var c Comparison
+	for _, l := range left {
+		key := compareKey(l.Name)
+
+		indexes := rightIndex[key]
+		if len(indexes) == 0 {
+			c.Features = append(c.Features, FeatureDiff{
+				Name:   l.Name,
+				Status: StatusOnlyLeft,
+				Genes:  compareGenes(l.Genes, nil),
+				Left:   l,
+			})
+			continue
+		}
+
+		rightIndex[key] = indexes[1:]
+		matched[indexes[0]] = true
+
+		r := right[indexes[0]]
+		diff := FeatureDiff{
+			Name:   l.Name,
+			Status: StatusSame,
+			Genes:  compareGenes(l.Genes, r.Genes),
+			Left:   l,
+			Right:  r,
+		}
+		if len(diff.Changed()) > 0 {
+			diff.Status = StatusChanged
+		}
+
+		c.Features = append(c.Features, diff)
+	}
+
+	for i, r := range right {
+		if matched[i] {
+			continue
+		}
+
+		c.Features = append(c.Features, FeatureDiff{
+			Name:   r.Name,
+			Status: StatusOnlyRight,
+			Genes:  compareGenes(nil, r.Genes),
+			Right:  r,
+		})
+	}
+
+	return c
+}
+
+// compareGenes aligns genes of two features by name.
+func compareGenes(left, right []Gene) []GeneDiff {
+	rightIndex := make(map[string][]int)
+	for i, g := range right {
+		key := compareKey(g.Name)
+		rightIndex[key] = append(rightIndex[key], i)
+	}
+
+	matched := make([]bool, len(right))
+
+	var genes []GeneDiff
+	for _, l := range left {
+		key := compareKey(l.Name)
+
+		indexes := rightIndex[key]
+		if len(indexes) == 0 {
+			genes = append(genes, GeneDiff{Name: l.Name, Status: StatusOnlyLeft, Left: l.Interpretations})
+			continue
+		}
+
+		rightIndex[key] = indexes[1:]
+		matched[indexes[0]] = true
+
+		r := right[indexes[0]]
+		status := StatusChanged
+		if slices.EqualFunc(l.Interpretations, r.Interpretations, func(a, b string) bool {
+			return compareKey(a) == compareKey(b)
+		}) {
+			status = StatusSame
+		}
+
+		genes = append(genes, GeneDiff{
+			Name:   l.Name,
+			Status: status,
+			Left:   l.Interpretations,
+			Right:  r.Interpretations,
+		})
+	}
+
+	for i, r := range right {
+		if !matched[i] {
+			genes = append(genes, GeneDiff{Name: r.Name, Status: StatusOnlyRight, Right: r.Interpretations})
+		}
+	}
+
+	return genes
+}
+
+// compareKey normalizes a name or an interpretation for comparison.
+func compareKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// Filter returns features with any of the statuses.
+func (c Comparison) Filter(statuses ...Status) []FeatureDiff {
+	var features []FeatureDiff
+	for _, f := range c.Features {
+		if slices.Contains(statuses, f.Status) {
+			features = append(features, f)
+		}
+	}
+
+	return features
+}
+
+// Same returns features with equal genes.
+func (c Comparison) Same() []FeatureDiff { return c.Filter(StatusSame) }
+
+// Changed returns features with different genes.
+func (c Comparison) Changed() []FeatureDiff { return c.Filter(StatusChanged) }
+
+// OnlyLeft returns features present in the left set only.
+func (c Comparison) OnlyLeft() []FeatureDiff { return c.Filter(StatusOnlyLeft) }
+
+// OnlyRight returns features present in the right set only.
+func (c Comparison) OnlyRight() []FeatureDiff { return c.Filter(StatusOnlyRight) }
+
+// Equal reports whether both sets have the same features with equal genes.
+func (c Comparison) Equal() bool {
+	return len(c.Filter(StatusChanged, StatusOnlyLeft, StatusOnlyRight)) == 0
+}
@@ -0,0 +1,97 @@
+package genetics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muzykantov/health-gpt/genetics"
+)
+
+var (
+	compareLeft = genetics.FeatureSet{
+		{Name: "Caffeine", Genes: []genetics.Gene{
+			{Name: "CYP1A2", Interpretations: []string{"Slow metabolism."}},
+			{Name: "AHR", Interpretations: []string{"Normal."}},
+		}},
+		{Name: "Lactose", Genes: []genetics.Gene{
+			{Name: "MCM6", Interpretations: []string{"Intolerance."}},
+		}},
+		{Name: "Vitamin D", Genes: []genetics.Gene{
+			{Name: "VDR", Interpretations: []string{"Low."}},
+		}},
+	}
+
+	compareRight = genetics.FeatureSet{
+		{Name: " lactose ", Genes: []genetics.Gene{
+			{Name: "mcm6", Interpretations: []string{"intolerance. "}},
+		}},
+		{Name: "Caffeine", Genes: []genetics.Gene{
+			{Name: "CYP1A2", Interpretations: []string{"Fast metabolism."}},
+			{Name: "AHR", Interpretations: []string{"Normal."}},
+			{Name: "ADORA2A", Interpretations: []string{"Anxiety."}},
+		}},
+		{Name: "Iron", Genes: []genetics.Gene{
+			{Name: "HFE", Interpretations: []string{"Normal."}},
+		}},
+	}
+)
+
+func TestCompare(t *testing.T) {
+	c := genetics.Compare(compareLeft, compareRight)
+
+	var got []string
+	for _, f := range c.Features {
+		got = append(got, f.Name+":"+string(f.Status))
+	}
+
+	want := "Caffeine:changed Lactose:same Vitamin D:only_left Iron:only_right"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Compare() = %v, want %s", got, want)
+	}
+
+	genes := c.Changed()[0].Changed()
+	if len(genes) != 2 {
+		t.Fatalf("changed genes = %+v", genes)
+	}
+	if genes[0].Name != "CYP1A2" || genes[0].Status != genetics.StatusChanged ||
+		genes[0].Right[0] != "Fast metabolism." {
+		t.Errorf("changed gene = %+v", genes[0])
+	}
+	if genes[1].Name != "ADORA2A" || genes[1].Status != genetics.StatusOnlyRight {
+		t.Errorf("added gene = %+v", genes[1])
+	}
+
+	if c.Equal() {
+		t.Error("Equal() = true for different sets")
+	}
+	if !genetics.Compare(compareLeft, compareLeft).Equal() {
+		t.Error("Equal() = false for the same set")
+	}
+}
+
+func TestRendererComparison(t *testing.T) {
+	c := genetics.Compare(compareLeft, compareRight)
+
+	for _, format := range []genetics.Format{
+		genetics.FormatHTML,
+		genetics.FormatMarkdown,
+		genetics.FormatPlain,
+		genetics.FormatLLM,
+	} {
+		r, err := genetics.NewRenderer(format, genetics.LocaleEnglish)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := r.Comparison(c, "WN0000T", "DX0000T")
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		for _, want := range []string{"Comparison of tests", "CYP1A2", "Fast metabolism.", "Only in WN0000T", "Vitamin D", "Iron", "Lactose"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s comparison does not contain %q:\n%s", format, want, got)
+			}
+		}
+	}
+}
@@ -1,84 +1,54 @@
 package genetics
 
-import (
-	"fmt"
-	"strings"
-)
+import "slices"
 
 // Feature represents a trait on which conclusions are based
 type Feature struct {
-	Name        string   // Name of the feature
-	Genes       []Gene   // Genes associated with this feature
-	Conclusions []string // Conclusions about the feature
-	Nutrition   []string // Dietary recommendations
-	Additional  []string // Additional lifestyle recommendations
-	Checklist   []string // Action items to be completed
+	Name        string     // Name of the feature
+	Genes       []Gene     // Genes associated with this feature
+	Conclusions []string   // Conclusions about the feature
+	Nutrition   []string   // Dietary recommendations
+	Additional  []string   // Additional lifestyle recommendations
+	Checklist   []string   // Action items to be completed
+	Risk        Risk       // Risk level of the feature, see MaxRisk
+	Categories  []Category // Areas of life the feature belongs to
 }
 
 // Gene represents a genetic marker with its name and interpretations
 type Gene struct {
 	Name            string   // Name of the gene
 	Interpretations []string // Interpretations of gene variations
+	RSID            string   // Marker ID, e.g. rs762551
+	Genotype        string   // Alleles of the person, e.g. A/C
+	Effect          Effect   // Direction in which the genotype changes the trait
+	Risk            Risk     // Risk level of the genotype
+	Evidence        Evidence // Level of scientific evidence
 }
 
-// ToHTML formats Feature in HTML format with emoji
-func (f Feature) ToHTML() string {
-	sb := new(strings.Builder)
-
-	// Title
-	sb.WriteString(fmt.Sprintf("<b>🧬 Признак: %s</b>\n\n", f.Name))
-
-	// Genes
-	if len(f.Genes) > 0 {
-		sb.WriteString("<b>🔬 Входящие гены:</b>\n")
-		for _, gene := range f.Genes {
-			sb.WriteString(fmt.Sprintf("• <b>%s</b>\n", gene.Name))
-			if len(gene.Interpretations) > 0 {
-				sb.WriteString(
-					fmt.Sprintf(
-						"  <i>%s</i>\n",
-						strings.Join(gene.Interpretations, " "),
-					),
-				)
-			}
-		}
-		sb.WriteString("\n")
-	}
-
-	// Nutrition recommendations
-	if len(f.Nutrition) > 0 {
-		sb.WriteString("<b>🍎 Питание:</b>\n")
-		for _, n := range f.Nutrition {
-			sb.WriteString(fmt.Sprintf("• %s\n", n))
-		}
-		sb.WriteString("\n")
+// MaxRisk returns the risk level of the feature or, if it is unknown,
+// the highest risk level of its genes.
+func (f Feature) MaxRisk() Risk {
+	if f.Risk != RiskUnknown {
+		return f.Risk
 	}
 
-	// Additional recommendations
-	if len(f.Additional) > 0 {
-		sb.WriteString("<b>📌 Дополнительные рекомендации:</b>\n")
-		for _, a := range f.Additional {
-			sb.WriteString(fmt.Sprintf("• %s\n", a))
-		}
-		sb.WriteString("\n")
+	var risk Risk
+	for _, g := range f.Genes {
+		risk = max(risk, g.Risk)
 	}
 
-	// Checklist
-	if len(f.Checklist) > 0 {
-		sb.WriteString("<b>✅ Чеклист:</b>\n")
-		for _, c := range f.Checklist {
-			sb.WriteString(fmt.Sprintf("• %s\n", c))
-		}
-		sb.WriteString("\n")
-	}
+	return risk
+}
 
-	// Conclusions
-	if len(f.Conclusions) > 0 {
-		sb.WriteString("<b>📋 Заключения:</b>\n")
-		for _, c := range f.Conclusions {
-			sb.WriteString(fmt.Sprintf("• %s\n", c))
-		}
-	}
+// HasCategory reports whether the feature belongs to the category.
+func (f Feature) HasCategory(c Category) bool {
+	return slices.Contains(f.Categories, c)
+}
 
-	return sb.String()
+// ToHTML formats Feature in Telegram HTML with emoji and Russian headings.
+// Use NewRenderer for other formats and locales.
+func (f Feature) ToHTML() string {
+	return mustRender(FormatHTML, LocaleRussian, func(r *Renderer) (string, error) {
+		return r.Feature(f)
+	})
 }
@@ -1,8 +1,8 @@
 package genetics
 
 import (
-	"fmt"
-	"strings"
+	"cmp"
+	"slices"
 )
 
 // FeatureSet represents a collection of genetic features
@@ -16,71 +16,45 @@ func (fs FeatureSet) MergeWith(other FeatureSet) FeatureSet {
 	return result
 }
 
-// BuildLLMContext creates a formatted context string from a set of Features
-// that can be sent to an LLM for interpretation
-func (fs FeatureSet) BuildLLMContext() string {
-	var builder strings.Builder
-
-	builder.WriteString("# Genetic Analysis Data\n\n")
-
-	// Process each feature
-	for i, feature := range fs {
-		builder.WriteString(fmt.Sprintf("## Feature %d: %s\n\n", i+1, feature.Name))
-
-		// Add genes information
-		if len(feature.Genes) > 0 {
-			builder.WriteString("### Genes:\n")
-			for _, gene := range feature.Genes {
-				builder.WriteString(fmt.Sprintf("#### %s\n", gene.Name))
+// SortByRisk returns a copy of the set ordered from the highest risk level
+// to unknown, keeping the order of features with the same level.
+func (fs FeatureSet) SortByRisk() FeatureSet {
+	sorted := slices.Clone(fs)
+	slices.SortStableFunc(sorted, func(a, b Feature) int {
+		return cmp.Compare(b.MaxRisk(), a.MaxRisk())
+	})
 
-				if len(gene.Interpretations) > 0 {
-					for _, interp := range gene.Interpretations {
-						builder.WriteString(fmt.Sprintf("- %s\n", interp))
-					}
-				}
-				builder.WriteString("\n")
-			}
-		}
-
-		// Add conclusions
-		if len(feature.Conclusions) > 0 {
-			builder.WriteString("### Conclusions:\n")
-			for _, conclusion := range feature.Conclusions {
-				builder.WriteString(fmt.Sprintf("- %s\n", conclusion))
-			}
-			builder.WriteString("\n")
-		}
+	return sorted
+}
 
-		// Add nutritional recommendations
-		if len(feature.Nutrition) > 0 {
-			builder.WriteString("### Nutrition:\n")
-			for _, nutrition := range feature.Nutrition {
-				builder.WriteString(fmt.Sprintf("- %s\n", nutrition))
-			}
-			builder.WriteString("\n")
+// Filter returns features matching the predicate.
+func (fs FeatureSet) Filter(match func(Feature) bool) FeatureSet {
+	var filtered FeatureSet
+	for _, f := range fs {
+		if match(f) {
+			filtered = append(filtered, f)
 		}
+	}
 
-		// Add additional recommendations
-		if len(feature.Additional) > 0 {
-			builder.WriteString("### Additional:\n")
-			for _, additional := range feature.Additional {
-				builder.WriteString(fmt.Sprintf("- %s\n", additional))
-			}
-			builder.WriteString("\n")
-		}
+	return filtered
+}
 
-		// Add checklist items
-		if len(feature.Checklist) > 0 {
-			builder.WriteString("### Checklist:\n")
-			for _, item := range feature.Checklist {
-				builder.WriteString(fmt.Sprintf("- %s\n", item))
-			}
-			builder.WriteString("\n")
-		}
+// ByCategory returns features of the category.
+func (fs FeatureSet) ByCategory(c Category) FeatureSet {
+	return fs.Filter(func(f Feature) bool { return f.HasCategory(c) })
+}
 
-		// Add clear separator between features
-		builder.WriteString("\n===========================================\n\n")
-	}
+// AtLeast returns features with the risk level of at least risk.
+func (fs FeatureSet) AtLeast(risk Risk) FeatureSet {
+	return fs.Filter(func(f Feature) bool { return f.MaxRisk() >= risk })
+}
 
-	return builder.String()
+// BuildLLMContext creates a formatted context string from a set of Features
+// that can be sent to an LLM for interpretation, the riskiest features
+// first. Headings are in English; use NewRenderer with FormatLLM for other
+// locales.
+func (fs FeatureSet) BuildLLMContext() string {
+	return mustRender(FormatLLM, LocaleEnglish, func(r *Renderer) (string, error) {
+		return r.FeatureSet(fs.SortByRisk())
+	})
 }
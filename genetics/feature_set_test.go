@@ -0,0 +1,88 @@
+package genetics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muzykantov/health-gpt/genetics"
+)
+
+var riskFeatures = genetics.FeatureSet{
+	{Name: "Lactose", Categories: []genetics.Category{genetics.CategoryNutrition}},
+	{Name: "Endurance", Genes: []genetics.Gene{
+		{Name: "ACTN3", Risk: genetics.RiskLow},
+		{Name: "PPARA", Risk: genetics.RiskHigh, RSID: "rs4253778", Genotype: "G/C"},
+	}, Categories: []genetics.Category{genetics.CategorySport}},
+	{Name: "Caffeine", Risk: genetics.RiskModerate, Categories: []genetics.Category{genetics.CategoryNutrition}},
+	{Name: "Vitamin D", Risk: genetics.RiskLow},
+}
+
+func TestFeatureSetSortByRisk(t *testing.T) {
+	var got []string
+	for _, f := range riskFeatures.SortByRisk() {
+		got = append(got, f.Name)
+	}
+
+	if want := "Endurance Caffeine Vitamin D Lactose"; strings.Join(got, " ") != want {
+		t.Errorf("SortByRisk() = %v, want %s", got, want)
+	}
+
+	if riskFeatures[0].Name != "Lactose" {
+		t.Error("SortByRisk() changed the original set")
+	}
+}
+
+func TestFeatureSetFilter(t *testing.T) {
+	if got := riskFeatures.ByCategory(genetics.CategoryNutrition); len(got) != 2 {
+		t.Errorf("ByCategory(nutrition) = %v", got)
+	}
+
+	if got := riskFeatures.AtLeast(genetics.RiskModerate); len(got) != 2 {
+		t.Errorf("AtLeast(moderate) = %v", got)
+	}
+}
+
+func TestParseRisk(t *testing.T) {
+	for s, want := range map[string]genetics.Risk{
+		"high":                genetics.RiskHigh,
+		"3":                   genetics.RiskHigh,
+		"Повышенный риск":     genetics.RiskModerate,
+		"умеренно высокий":    genetics.RiskModerate,
+		"Низкий риск":         genetics.RiskLow,
+		"минимальный":         genetics.RiskLow,
+		"":                    genetics.RiskUnknown,
+		"без особенностей":    genetics.RiskUnknown,
+		"Moderately elevated": genetics.RiskModerate,
+		"highly increased":    genetics.RiskHigh,
+		"Высокий риск":        genetics.RiskHigh,
+		"slow metabolism":     genetics.RiskUnknown,
+		"below average":       genetics.RiskUnknown,
+		"не повышен":          genetics.RiskLow,
+		"риск не высокий":     genetics.RiskLow,
+		"not elevated":        genetics.RiskLow,
+		"не низкий, средний":  genetics.RiskModerate,
+	} {
+		if got := genetics.ParseRisk(s); got != want {
+			t.Errorf("ParseRisk(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestParseCategories(t *testing.T) {
+	got := genetics.ParseCategories("Метаболизм кофеина")
+	if len(got) != 2 || got[0] != genetics.CategoryNutrition || got[1] != genetics.CategoryMetabolism {
+		t.Errorf("ParseCategories() = %v", got)
+	}
+}
+
+func TestBuildLLMContextMarkers(t *testing.T) {
+	got := riskFeatures.BuildLLMContext()
+
+	if !strings.HasPrefix(got, "# Genetic Analysis Data\n\n## Feature 1: Endurance\n\nRisk: high\nCategories: sport\n") {
+		t.Errorf("riskiest feature is not first:\n%s", got)
+	}
+
+	if !strings.Contains(got, "#### PPARA rs4253778 G/C\n- Risk: high\n") {
+		t.Errorf("gene markers are missing:\n%s", got)
+	}
+}
@@ -0,0 +1,107 @@
+package genetics
+
+// Locale holds the headings used by report templates.
+type Locale struct {
+	Report      string // Title of a report with several features
+	Feature     string // Feature title prefix
+	Genes       string // Genes section
+	Nutrition   string // Nutrition recommendations section
+	Additional  string // Additional recommendations section
+	Checklist   string // Checklist section
+	Conclusions string // Conclusions section
+
+	Risk         string // Risk level label
+	RiskLow      string
+	RiskModerate string
+	RiskHigh     string
+	Effect       string // Effect direction label
+	Evidence     string // Evidence level label
+	Categories   string // Feature categories label
+
+	Comparison  string // Title of a comparison of two sets
+	Differences string // Features with different genes
+	OnlyIn      string // Features present in one set, followed by its label
+	Matches     string // Features with equal genes
+	NoDiff      string // Shown when the sets are equal
+}
+
+// Built-in locales.
+var (
+	LocaleRussian = Locale{
+		Report:      "Данные генетического анализа",
+		Feature:     "Признак",
+		Genes:       "Входящие гены",
+		Nutrition:   "Питание",
+		Additional:  "Дополнительные рекомендации",
+		Checklist:   "Чеклист",
+		Conclusions: "Заключения",
+
+		Risk:         "Риск",
+		RiskLow:      "низкий",
+		RiskModerate: "умеренный",
+		RiskHigh:     "высокий",
+		Effect:       "Эффект",
+		Evidence:     "Доказательность",
+		Categories:   "Категории",
+
+		Comparison:  "Сравнение анализов",
+		Differences: "Различия",
+		OnlyIn:      "Только в",
+		Matches:     "Совпадения",
+		NoDiff:      "Различий не найдено.",
+	}
+
+	LocaleEnglish = Locale{
+		Report:      "Genetic Analysis Data",
+		Feature:     "Feature",
+		Genes:       "Genes",
+		Nutrition:   "Nutrition",
+		Additional:  "Additional recommendations",
+		Checklist:   "Checklist",
+		Conclusions: "Conclusions",
+
+		Risk:         "Risk",
+		RiskLow:      "low",
+		RiskModerate: "moderate",
+		RiskHigh:     "high",
+		Effect:       "Effect",
+		Evidence:     "Evidence",
+		Categories:   "Categories",
+
+		Comparison:  "Comparison of tests",
+		Differences: "Differences",
+		OnlyIn:      "Only in",
+		Matches:     "Matches",
+		NoDiff:      "No differences found.",
+	}
+)
+
+// Locales maps language codes to built-in locales.
+var Locales = map[string]Locale{
+	"ru": LocaleRussian,
+	"en": LocaleEnglish,
+}
+
+// RiskName returns the name of the risk level, empty if it is unknown.
+func (l Locale) RiskName(r Risk) string {
+	switch r {
+	case RiskLow:
+		return l.RiskLow
+	case RiskModerate:
+		return l.RiskModerate
+	case RiskHigh:
+		return l.RiskHigh
+	default:
+		return ""
+	}
+}
+
+// LocaleFor returns the built-in locale for the language code,
+// falling back to Russian.
+func LocaleFor(lang string) Locale {
+	if locale, ok := Locales[lang]; ok {
+		return locale
+	}
+
+	return LocaleRussian
+}
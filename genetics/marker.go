@@ -0,0 +1,199 @@
+package genetics
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Risk is the risk level of a gene or a feature. Levels are ordered, so
+// risks may be compared with < and >.
+type Risk int
+
+// Risk levels.
+const (
+	RiskUnknown Risk = iota
+	RiskLow
+	RiskModerate
+	RiskHigh
+)
+
+// String returns the risk level name used in payloads and LLM contexts.
+func (r Risk) String() string {
+	switch r {
+	case RiskLow:
+		return "low"
+	case RiskModerate:
+		return "moderate"
+	case RiskHigh:
+		return "high"
+	default:
+		return ""
+	}
+}
+
+// riskWords maps word stems of risk descriptions to levels. A word matches
+// a stem if it starts with it, so "highly" is high and "slow" is not low.
+var riskWords = []struct {
+	stem string
+	risk Risk
+}{
+	{"moderate", RiskModerate},
+	{"elevated", RiskModerate},
+	{"increased", RiskModerate},
+	{"умерен", RiskModerate},
+	{"повышен", RiskModerate},
+	{"средн", RiskModerate},
+	{"minimal", RiskLow},
+	{"минимал", RiskLow},
+	{"high", RiskHigh},
+	{"высок", RiskHigh},
+	{"low", RiskLow},
+	{"низк", RiskLow},
+}
+
+// negations are words that negate the following risk word.
+var negations = []string{"не", "нет", "без", "not", "no", "non"}
+
+// ParseRisk returns the risk level of a payload value like "high" or 3,
+// or of a description like "Высокий риск", RiskUnknown if there is none.
+// The first risk word of a description sets the level, so "highly
+// increased" is high and "умеренно высокий" is moderate; a negated
+// elevated level like "не повышен" is low.
+func ParseRisk(s string) Risk {
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		return Risk(min(max(n, int(RiskUnknown)), int(RiskHigh)))
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		risk := wordRisk(word)
+		if risk == RiskUnknown {
+			continue
+		}
+
+		if i > 0 && slices.Contains(negations, words[i-1]) {
+			if risk > RiskLow {
+				return RiskLow
+			}
+			continue
+		}
+
+		return risk
+	}
+
+	return RiskUnknown
+}
+
+// wordRisk returns the risk level of a single lowercase word.
+func wordRisk(word string) Risk {
+	for _, w := range riskWords {
+		if strings.HasPrefix(word, w.stem) {
+			return w.risk
+		}
+	}
+
+	return RiskUnknown
+}
+
+// Effect is the direction in which a genotype changes a trait.
+type Effect string
+
+// Effect directions.
+const (
+	EffectIncreased Effect = "increased"
+	EffectDecreased Effect = "decreased"
+	EffectNeutral   Effect = "neutral"
+)
+
+// ParseEffect returns the effect of a payload value, empty if unknown.
+func ParseEffect(s string) Effect {
+	s = strings.ToLower(s)
+	switch {
+	case strings.Contains(s, "increas"), strings.Contains(s, "повыш"), strings.Contains(s, "усил"), s == "+":
+		return EffectIncreased
+	case strings.Contains(s, "decreas"), strings.Contains(s, "сниж"), strings.Contains(s, "ослаб"), s == "-":
+		return EffectDecreased
+	case strings.Contains(s, "neutral"), strings.Contains(s, "нейтрал"), strings.Contains(s, "норм"):
+		return EffectNeutral
+	default:
+		return ""
+	}
+}
+
+// Evidence is the level of scientific evidence for an interpretation.
+type Evidence string
+
+// Evidence levels.
+const (
+	EvidenceStrong   Evidence = "strong"
+	EvidenceModerate Evidence = "moderate"
+	EvidenceLimited  Evidence = "limited"
+)
+
+// ParseEvidence returns the evidence level of a payload value like "strong"
+// or a grade from A (strong) to C (limited), empty if unknown.
+func ParseEvidence(s string) Evidence {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "a", strings.Contains(s, "strong"), strings.Contains(s, "high"), strings.Contains(s, "высок"):
+		return EvidenceStrong
+	case s == "b", strings.Contains(s, "moderate"), strings.Contains(s, "medium"), strings.Contains(s, "средн"):
+		return EvidenceModerate
+	case s == "c", strings.Contains(s, "limited"), strings.Contains(s, "low"), strings.Contains(s, "низк"):
+		return EvidenceLimited
+	default:
+		return ""
+	}
+}
+
+// Category groups features by area of life.
+type Category string
+
+// Feature categories.
+const (
+	CategoryNutrition  Category = "nutrition"
+	CategorySport      Category = "sport"
+	CategoryMetabolism Category = "metabolism"
+)
+
+// Categories lists known categories.
+var Categories = []Category{CategoryNutrition, CategorySport, CategoryMetabolism}
+
+// categoryWords maps word stems of feature names and payload values to
+// categories.
+var categoryWords = map[Category][]string{
+	CategoryNutrition: {
+		"nutrition", "food", "diet", "vitamin", "lactose", "gluten", "caffeine", "alcohol",
+		"питан", "диет", "витамин", "лактоз", "глютен", "кофеин", "алкогол", "вкус",
+	},
+	CategorySport: {
+		"sport", "endurance", "strength", "muscle", "injur", "training",
+		"спорт", "выносл", "силов", "мышц", "травм", "тренир",
+	},
+	CategoryMetabolism: {
+		"metabol", "obesity", "weight", "insulin", "cholesterol", "lipid",
+		"метабол", "ожирен", "вес тела", "инсулин", "холестерин", "липид", "обмен",
+	},
+}
+
+// ParseCategories returns categories mentioned in s, for example in
+// a payload value or a feature name.
+func ParseCategories(s string) []Category {
+	s = strings.ToLower(s)
+
+	var categories []Category
+	for _, c := range Categories {
+		if slices.ContainsFunc(categoryWords[c], func(stem string) bool {
+			return strings.Contains(s, stem)
+		}) {
+			categories = append(categories, c)
+		}
+	}
+
+	return categories
+}
@@ -0,0 +1,174 @@
+package genetics
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+// Templates executed by renderers. Every template set defines the first two,
+// TemplateComparison is required only by Renderer.Comparison.
+const (
+	TemplateFeature    = "feature"    // Renders a single Feature
+	TemplateFeatureSet = "features"   // Renders a FeatureSet
+	TemplateComparison = "comparison" // Renders a Comparison
+)
+
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// Format is the markup of a rendered report.
+type Format string
+
+// Built-in report formats.
+const (
+	FormatHTML     Format = "html"     // Telegram HTML
+	FormatMarkdown Format = "markdown" // Markdown for web and mobile clients
+	FormatPlain    Format = "plain"    // Plain text for terminals
+	FormatLLM      Format = "llm"      // Context for language models
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// Funcs are available in built-in templates. Custom templates may use them
+// by passing Funcs to template.Funcs before parsing.
+var Funcs = map[string]any{
+	"join": strings.Join,
+	"inc":  func(i int) int { return i + 1 },
+}
+
+// Template is implemented by both *text/template.Template and
+// *html/template.Template.
+type Template interface {
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+// Renderer renders features as text reports.
+type Renderer struct {
+	tmpl   Template
+	locale Locale
+}
+
+// builtin caches parsed built-in templates by format.
+var builtin = struct {
+	sync.Mutex
+	templates map[Format]Template
+}{templates: make(map[Format]Template)}
+
+// NewRenderer creates a renderer with the built-in templates of the format.
+// HTML reports are escaped by html/template, other formats use text/template.
+func NewRenderer(format Format, locale Locale) (*Renderer, error) {
+	builtin.Lock()
+	defer builtin.Unlock()
+
+	if tmpl, ok := builtin.templates[format]; ok {
+		return NewTemplateRenderer(tmpl, locale), nil
+	}
+
+	name := fmt.Sprintf("templates/%s.tmpl", format)
+
+	var (
+		tmpl Template
+		err  error
+	)
+	switch format {
+	case FormatHTML:
+		tmpl, err = htmltemplate.New(string(format)).Funcs(Funcs).ParseFS(templatesFS, name)
+	case FormatMarkdown, FormatPlain, FormatLLM:
+		tmpl, err = texttemplate.New(string(format)).Funcs(Funcs).ParseFS(templatesFS, name)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s templates: %w", format, err)
+	}
+
+	builtin.templates[format] = tmpl
+
+	return NewTemplateRenderer(tmpl, locale), nil
+}
+
+// FeatureData is passed to TemplateFeature.
+type FeatureData struct {
+	Locale  Locale
+	Feature Feature
+	Index   int // Position of the feature in the set, starting with 0
+}
+
+// FeatureSetData is passed to TemplateFeatureSet.
+type FeatureSetData struct {
+	Locale   Locale
+	Features []FeatureData
+}
+
+// ComparisonData is passed to TemplateComparison.
+type ComparisonData struct {
+	Locale     Locale
+	Left       string // Label of the left set
+	Right      string // Label of the right set
+	Comparison Comparison
+}
+
+// NewTemplateRenderer creates a renderer with custom templates. The templates
+// must define TemplateFeature and TemplateFeatureSet, executed with
+// FeatureData and FeatureSetData respectively.
+func NewTemplateRenderer(tmpl Template, locale Locale) *Renderer {
+	return &Renderer{tmpl: tmpl, locale: locale}
+}
+
+// Feature renders a single feature.
+func (r *Renderer) Feature(f Feature) (string, error) {
+	return r.execute(TemplateFeature, FeatureData{Locale: r.locale, Feature: f})
+}
+
+// FeatureSet renders all features of the set.
+func (r *Renderer) FeatureSet(fs FeatureSet) (string, error) {
+	data := FeatureSetData{
+		Locale:   r.locale,
+		Features: make([]FeatureData, len(fs)),
+	}
+	for i, f := range fs {
+		data.Features[i] = FeatureData{Locale: r.locale, Feature: f, Index: i}
+	}
+
+	return r.execute(TemplateFeatureSet, data)
+}
+
+// Comparison renders a comparison of two sets labeled left and right.
+func (r *Renderer) Comparison(c Comparison, left, right string) (string, error) {
+	return r.execute(TemplateComparison, ComparisonData{
+		Locale:     r.locale,
+		Left:       left,
+		Right:      right,
+		Comparison: c,
+	})
+}
+
+func (r *Renderer) execute(name string, data any) (string, error) {
+	sb := new(strings.Builder)
+	if err := r.tmpl.ExecuteTemplate(sb, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	return sb.String(), nil
+}
+
+// mustRender renders with built-in templates, which are covered by tests.
+func mustRender(format Format, locale Locale, render func(*Renderer) (string, error)) string {
+	r, err := NewRenderer(format, locale)
+	if err != nil {
+		panic(err)
+	}
+
+	s, err := render(r)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
@@ -0,0 +1,126 @@
+package genetics_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+
+	"github.com/muzykantov/health-gpt/genetics"
+)
+
+var testFeature = genetics.Feature{
+	Name: "Caffeine <metabolism>",
+	Genes: []genetics.Gene{
+		{Name: "CYP1A2", Interpretations: []string{"Slow", "metabolism."}},
+	},
+	Nutrition:   []string{"Coffee & tea: 1 cup a day."},
+	Conclusions: []string{"Caffeine is cleared slowly."},
+}
+
+func render(t *testing.T, format genetics.Format, locale genetics.Locale, f genetics.Feature) string {
+	t.Helper()
+
+	r, err := genetics.NewRenderer(format, locale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := r.Feature(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestRendererHTML(t *testing.T) {
+	got := render(t, genetics.FormatHTML, genetics.LocaleEnglish, testFeature)
+
+	want := "<b>🧬 Feature: Caffeine &lt;metabolism&gt;</b>\n\n" +
+		"<b>🔬 Genes:</b>\n" +
+		"• <b>CYP1A2</b>\n" +
+		"  <i>Slow metabolism.</i>\n\n" +
+		"<b>🍎 Nutrition:</b>\n" +
+		"• Coffee &amp; tea: 1 cup a day.\n\n" +
+		"<b>📋 Conclusions:</b>\n" +
+		"• Caffeine is cleared slowly.\n"
+
+	if got != want {
+		t.Errorf("Feature()\n got: %q\nwant: %q", got, want)
+	}
+
+	if html := testFeature.ToHTML(); !strings.Contains(html, "Признак") || !strings.Contains(html, "Входящие гены") {
+		t.Errorf("ToHTML() is not in Russian: %q", html)
+	}
+}
+
+func TestRendererFormats(t *testing.T) {
+	tests := []struct {
+		format genetics.Format
+		want   []string
+	}{
+		{genetics.FormatMarkdown, []string{"### 🧬 Feature: Caffeine <metabolism>", "- **CYP1A2**", "  _Slow metabolism._"}},
+		{genetics.FormatPlain, []string{"Feature: Caffeine <metabolism>\n", "Genes:\n- CYP1A2\n  Slow metabolism.\n"}},
+		{genetics.FormatLLM, []string{"# Genetic Analysis Data", "## Feature 1: Caffeine <metabolism>", "#### CYP1A2\n- Slow\n- metabolism."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			r, err := genetics.NewRenderer(tt.format, genetics.LocaleEnglish)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.FeatureSet(genetics.FeatureSet{testFeature})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("FeatureSet() does not contain %q:\n%s", want, got)
+				}
+			}
+
+			if strings.Contains(got, "<b>") {
+				t.Errorf("FeatureSet() contains HTML:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestRendererLocale(t *testing.T) {
+	got := render(t, genetics.FormatPlain, genetics.LocaleFor("ru"), testFeature)
+	if !strings.HasPrefix(got, "Признак: ") || !strings.Contains(got, "Заключения:") {
+		t.Errorf("Feature() is not in Russian:\n%s", got)
+	}
+
+	if genetics.LocaleFor("de") != genetics.LocaleRussian {
+		t.Error("LocaleFor() does not fall back to Russian")
+	}
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	tmpl := texttemplate.Must(texttemplate.New("custom").Funcs(genetics.Funcs).Parse(
+		`{{define "feature"}}{{inc .Index}}. {{.Feature.Name}}{{end}}` +
+			`{{define "features"}}{{range .Features}}{{template "feature" .}};{{end}}{{end}}`,
+	))
+
+	r := genetics.NewTemplateRenderer(tmpl, genetics.LocaleEnglish)
+
+	got, err := r.FeatureSet(genetics.FeatureSet{{Name: "A"}, {Name: "B"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "1. A;2. B;" {
+		t.Errorf("FeatureSet() = %q", got)
+	}
+}
+
+func TestRendererUnknownFormat(t *testing.T) {
+	if _, err := genetics.NewRenderer("pdf", genetics.LocaleEnglish); !errors.Is(err, genetics.ErrUnknownFormat) {
+		t.Errorf("NewRenderer() error = %v, want %v", err, genetics.ErrUnknownFormat)
+	}
+}
@@ -0,0 +1,156 @@
+package genetics
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Query filters features of a set. Empty fields match any feature.
+type Query struct {
+	Text     string   // Words to find in names, rsIDs and texts of a feature
+	Category Category // Category of the feature
+	MinRisk  Risk     // Lowest risk level of the feature
+}
+
+// Query filter prefixes, e.g. "кофе risk:high category:nutrition".
+const (
+	QueryRisk     = "risk:"
+	QueryCategory = "category:"
+)
+
+// ParseQuery splits a search string into filters and text. Categories and
+// risk levels are parsed like payload values, so "category:спорт" and
+// "risk:высокий" work too.
+func ParseQuery(s string) Query {
+	var (
+		q     Query
+		words []string
+	)
+	for _, word := range strings.Fields(s) {
+		lower := strings.ToLower(word)
+
+		switch {
+		case strings.HasPrefix(lower, QueryRisk):
+			q.MinRisk = ParseRisk(strings.TrimPrefix(lower, QueryRisk))
+
+		case strings.HasPrefix(lower, QueryCategory):
+			if categories := ParseCategories(strings.TrimPrefix(lower, QueryCategory)); len(categories) > 0 {
+				q.Category = categories[0]
+			}
+
+		default:
+			words = append(words, word)
+		}
+	}
+
+	q.Text = strings.Join(words, " ")
+
+	return q
+}
+
+// String returns the query in the form accepted by ParseQuery.
+func (q Query) String() string {
+	parts := make([]string, 0, 3)
+	if q.Text != "" {
+		parts = append(parts, q.Text)
+	}
+	if q.Category != "" {
+		parts = append(parts, QueryCategory+string(q.Category))
+	}
+	if q.MinRisk != RiskUnknown {
+		parts = append(parts, QueryRisk+q.MinRisk.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// IsEmpty reports whether the query matches any feature.
+func (q Query) IsEmpty() bool {
+	return q == Query{}
+}
+
+// Search relevance of a feature: where all words of the query are found.
+const (
+	matchNone = iota
+	matchText
+	matchGene
+	matchName
+)
+
+// Search returns features matching the query. Features whose names match
+// the text come first, then features with matching genes or rsIDs, then
+// features with the text in interpretations, conclusions or
+// recommendations; the order of the set is kept within each group.
+func (fs FeatureSet) Search(q Query) FeatureSet {
+	words := strings.Fields(strings.ToLower(q.Text))
+
+	type result struct {
+		feature Feature
+		match   int
+	}
+
+	var results []result
+	for _, f := range fs {
+		if q.Category != "" && !f.HasCategory(q.Category) {
+			continue
+		}
+		if f.MaxRisk() < q.MinRisk {
+			continue
+		}
+
+		match := matchName
+		if len(words) > 0 {
+			match = f.match(words)
+		}
+		if match == matchNone {
+			continue
+		}
+
+		results = append(results, result{f, match})
+	}
+
+	slices.SortStableFunc(results, func(a, b result) int {
+		return cmp.Compare(b.match, a.match)
+	})
+
+	found := make(FeatureSet, 0, len(results))
+	for _, r := range results {
+		found = append(found, r.feature)
+	}
+
+	return found
+}
+
+// match returns where all words are found in the feature.
+func (f Feature) match(words []string) int {
+	containsAll := func(texts ...string) bool {
+		s := strings.ToLower(strings.Join(texts, " "))
+		for _, word := range words {
+			if !strings.Contains(s, word) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if containsAll(f.Name) {
+		return matchName
+	}
+
+	genes := make([]string, 0, len(f.Genes)*2)
+	texts := slices.Concat(f.Conclusions, f.Nutrition, f.Additional, f.Checklist)
+	for _, g := range f.Genes {
+		genes = append(genes, g.Name, g.RSID)
+		texts = append(texts, g.Interpretations...)
+	}
+
+	switch {
+	case containsAll(genes...):
+		return matchGene
+	case containsAll(slices.Concat([]string{f.Name}, genes, texts)...):
+		return matchText
+	default:
+		return matchNone
+	}
+}
@@ -0,0 +1,60 @@
+package genetics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muzykantov/health-gpt/genetics"
+)
+
+var searchFeatures = genetics.FeatureSet{
+	{Name: "Lactose intolerance", Genes: []genetics.Gene{
+		{Name: "MCM6", RSID: "rs4988235", Interpretations: []string{"Reduced lactase activity."}},
+	}, Risk: genetics.RiskHigh, Categories: []genetics.Category{genetics.CategoryNutrition}},
+	{Name: "Caffeine metabolism", Genes: []genetics.Gene{
+		{Name: "CYP1A2", RSID: "rs762551", Interpretations: []string{"Slow metabolism."}},
+	}, Risk: genetics.RiskModerate, Categories: []genetics.Category{genetics.CategoryNutrition, genetics.CategoryMetabolism}},
+	{Name: "Endurance", Genes: []genetics.Gene{
+		{Name: "PPARA", Interpretations: []string{"Lower endurance, limit caffeine before training."}},
+	}, Risk: genetics.RiskLow, Categories: []genetics.Category{genetics.CategorySport}},
+}
+
+func names(fs genetics.FeatureSet) string {
+	var out []string
+	for _, f := range fs {
+		out = append(out, f.Name)
+	}
+	return strings.Join(out, ", ")
+}
+
+func TestFeatureSetSearch(t *testing.T) {
+	for query, want := range map[string]string{
+		"":                             "Lactose intolerance, Caffeine metabolism, Endurance",
+		"caffeine":                     "Caffeine metabolism, Endurance",
+		"rs4988235":                    "Lactose intolerance",
+		"cyp1a2":                       "Caffeine metabolism",
+		"slow":                         "Caffeine metabolism",
+		"category:sport":               "Endurance",
+		"category:питание risk:high":   "Lactose intolerance",
+		"risk:moderate":                "Lactose intolerance, Caffeine metabolism",
+		"caffeine category:metabolism": "Caffeine metabolism",
+		"vitamin":                      "",
+	} {
+		if got := names(searchFeatures.Search(genetics.ParseQuery(query))); got != want {
+			t.Errorf("Search(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q := genetics.ParseQuery("Caffeine  RISK:высокий category:sport")
+
+	want := genetics.Query{Text: "Caffeine", Category: genetics.CategorySport, MinRisk: genetics.RiskHigh}
+	if q != want {
+		t.Errorf("ParseQuery() = %+v, want %+v", q, want)
+	}
+
+	if got := genetics.ParseQuery(q.String()); got != q {
+		t.Errorf("ParseQuery(%q) = %+v, want %+v", q.String(), got, q)
+	}
+}
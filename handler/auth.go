@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"slices"
 	"time"
 
 	"github.com/muzykantov/health-gpt/chat"
@@ -32,41 +33,61 @@ func auth(next server.Handler) server.Handler {
 					r.From.Email,
 					r.From.Password,
 				); err != nil {
-					w.WriteResponse(chat.MsgAf("⛔ Ошибка аутентификации mygenetics: %v", err))
+					w.WriteResponse(msg(r, "auth.failed", err))
 					return
 				}
 
 				if err := r.Storage.SaveUser(ctx, r.From); err != nil {
-					w.WriteResponse(chat.MsgAf("⛔ Ошибка обновления информации о пользователе: %v", err))
+					w.WriteResponse(msg(r, "error.user.save", err))
 					return
 				}
 
+				// Пользователи, вошедшие до появления уведомлений, получают их
+				// при обновлении токенов. Существующие задания не изменяются.
+				scheduleNotifications(ctx, r, chat.JobCodelab, chat.JobTip)
+
 				next.Serve(ctx, w, r)
 				return
 			}
 
-			// Если пользователь ешё не ввел email и пароль, то читаем историю чата.
+			// Если пользователь ешё не ввел email и пароль, то читаем состояние и историю чата.
+			state, err := r.Storage.GetChatState(ctx, r.ChatID)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.chat_state.get", err))
+				return
+			}
+
 			msgs, err := r.Storage.GetChatHistory(ctx, r.ChatID, 0)
 			if err != nil {
-				w.WriteResponse(chat.MsgAf("⛔ Ошибка получения истории чата: %v", err))
+				w.WriteResponse(msg(r, "error.history.get", err))
 				return
 			}
 
-			// Пользователь ноывй? Добавляем инстукции для ИИ получить email и пароль.
-			if len(msgs) == 0 {
-				prompt := prompts.Get(authPrompt, r.Completer.ModelName())
+			// Вход ещё не начат? Добавляем инстукции для ИИ получить email и пароль.
+			if state.Stage != chat.StageAwaitingCredentials || len(msgs) == 0 {
+				prompt := prompts.Get(authPrompt, promptLang(r), r.Completer.ModelName())
 				if prompt == prompts.Default {
-					w.WriteResponse(chat.MsgA("⛔ Промпт не найден."))
+					w.WriteResponse(msg(r, "error.prompt_not_found"))
 					return
 				}
 
 				msgs = []chat.Message{
 					chat.MsgS(prompt),
 				}
+
+				if state, err = state.Reset().AwaitCredentials(); err != nil {
+					w.WriteResponse(msg(r, "error.chat_state.change", err))
+					return
+				}
+
+				if err := r.Storage.SaveChatState(ctx, r.ChatID, state); err != nil {
+					w.WriteResponse(msg(r, "error.chat_state.save", err))
+					return
+				}
 			}
 
 			if _, ok := r.Incoming.Content.(string); !ok {
-				r.Incoming.Content = "Привет."
+				r.Incoming.Content = tr(r, "auth.hello")
 			}
 
 			// Добавляем присланное пользователем сообщение в контекст.
@@ -75,7 +96,7 @@ func auth(next server.Handler) server.Handler {
 			// Даем ИИ разобраться с сообщениями и решить что делать дальше.
 			response, err := r.Completer.CompleteChat(ctx, msgs)
 			if err != nil {
-				w.WriteResponse(chat.MsgAf("⛔ Ошибка генерации ответа: %v", err))
+				w.WriteResponse(msg(r, "error.completion", err))
 				return
 			}
 
@@ -84,7 +105,7 @@ func auth(next server.Handler) server.Handler {
 				msgs = append(msgs, response)
 
 				if err := r.Storage.SaveChatHistory(ctx, r.ChatID, msgs); err != nil {
-					w.WriteResponse(chat.MsgAf("⛔ Ошибка сохранения истории чата: %v", err))
+					w.WriteResponse(msg(r, "error.history.save", err))
 					return
 				}
 
@@ -101,7 +122,7 @@ func auth(next server.Handler) server.Handler {
 				[]byte(response.Content.(string)),
 				&credentials,
 			); err != nil {
-				w.WriteResponse(chat.MsgAf("⛔ Ошибка парсинга ответа: %v", err))
+				w.WriteResponse(msg(r, "error.parse", err))
 				return
 			}
 
@@ -114,7 +135,7 @@ func auth(next server.Handler) server.Handler {
 			if err != nil {
 				// Если не получилось, то сбрасываем переписку и отправляем ответ пользователю.
 				w.WriteResponse(
-					chat.MsgA("❌ Имя пользователя или пароль не подходят. Попробуйте ещё раз."),
+					msg(r, "auth.invalid"),
 				)
 
 				if err := r.Storage.SaveChatHistory(
@@ -122,7 +143,11 @@ func auth(next server.Handler) server.Handler {
 					r.ChatID,
 					make([]chat.Message, 0),
 				); err != nil {
-					w.WriteResponse(chat.MsgAf("⛔ Ошибка сохранения истории чата: %v", err))
+					w.WriteResponse(msg(r, "error.history.save", err))
+				}
+
+				if err := r.Storage.SaveChatState(ctx, r.ChatID, state.Reset()); err != nil {
+					w.WriteResponse(msg(r, "error.chat_state.save", err))
 				}
 				return
 			}
@@ -133,21 +158,27 @@ func auth(next server.Handler) server.Handler {
 			r.From.Tokens = tokens
 			r.From.State = chat.UserStateAuthorized
 			if err := r.Storage.SaveUser(ctx, r.From); err != nil {
-				w.WriteResponse(chat.MsgAf("⛔ Ошибка сохранения пользователя: %v", err))
+				w.WriteResponse(msg(r, "error.user.save", err))
 				return
 			}
 
-			// Сбрасываем переписку.
+			// Сбрасываем переписку и возвращаем чат к приветствию.
 			if err := r.Storage.SaveChatHistory(
 				ctx,
 				r.ChatID,
 				make([]chat.Message, 0),
 			); err != nil {
-				w.WriteResponse(chat.MsgAf("⛔ Ошибка сохранения истории чата: %v", err))
+				w.WriteResponse(msg(r, "error.history.save", err))
+				return
+			}
+
+			if err := r.Storage.SaveChatState(ctx, r.ChatID, state.Reset()); err != nil {
+				w.WriteResponse(msg(r, "error.chat_state.save", err))
 				return
 			}
 
-			w.WriteResponse(chat.MsgA("✅ Вы успешно вошли в систему! Благодарим за предоставленные данные."))
+			w.WriteResponse(msg(r, "auth.success"))
+			scheduleNotifications(ctx, r, chat.JobCodelab, chat.JobTip)
 
 			// Передаем запрос дальше.
 			r.Incoming = chat.NewMessage(chat.RoleUser, "")
@@ -155,3 +186,40 @@ func auth(next server.Handler) server.Handler {
 		},
 	)
 }
+
+// renewTokens обновляет истекшие токены пользователя, сохраняет их
+// и возвращает токен доступа.
+func renewTokens(ctx context.Context, r *server.Request) (mygenetics.Token, error) {
+	tokens, err := mygenetics.DefaultClient.Renew(ctx, r.From.Tokens, r.From.Email, r.From.Password)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.Equal(tokens, r.From.Tokens) {
+		if err := updateUser(ctx, r, func(user *chat.User) {
+			user.Tokens = tokens
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return mygenetics.AccessToken(tokens), nil
+}
+
+// updateUser перечитывает пользователя, изменяет его функцией update
+// и сохраняет. Так сетевые запросы, выполненные между чтением и сохранением,
+// не затирают настройки, измененные за это время другими запросами.
+func updateUser(ctx context.Context, r *server.Request, update func(user *chat.User)) error {
+	user, err := r.Storage.GetUser(ctx, r.From.ID)
+	if err != nil {
+		return err
+	}
+
+	update(&user)
+	if err := r.Storage.SaveUser(ctx, user); err != nil {
+		return err
+	}
+
+	r.From = user
+	return nil
+}
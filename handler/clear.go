@@ -2,29 +2,43 @@ package handler
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/muzykantov/health-gpt/chat"
 	"github.com/muzykantov/health-gpt/server"
 )
 
 const (
+	// DefaultFirstMessage использовался как маркер начала диалога в истории.
+	// Сейчас этап диалога хранится в chat.State, маркер фильтруется только
+	// для совместимости со старыми историями.
 	DefaultFirstMessage = "[Начало диалога]"
 )
 
 func clear(response bool) server.Handler {
 	return server.HandlerFunc(
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
-			if err := r.Storage.SaveChatHistory(ctx, r.ChatID, []chat.Message{
-				chat.MsgU(DefaultFirstMessage),
-			}); err != nil {
-				w.WriteResponse(chat.MsgAf("⚠️ Ошибка записи истории чата: %v", err))
+			if err := r.Storage.SaveChatHistory(ctx, r.ChatID, []chat.Message{}); err != nil {
+				w.WriteResponse(msg(r, "error.history.save", err))
 			}
 
-			r.Cache.Remove(fmt.Sprintf(codelabCodeCacheKey, r.ChatID))
+			// Сбрасываем выбранный анализ и переходим к свободному общению.
+			state, err := r.Storage.GetChatState(ctx, r.ChatID)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.chat_state.get", err))
+				return
+			}
+
+			if state, err = state.StartChatting(); err != nil {
+				w.WriteResponse(msg(r, "error.chat_state.change", err))
+				return
+			}
+
+			if err := r.Storage.SaveChatState(ctx, r.ChatID, state); err != nil {
+				w.WriteResponse(msg(r, "error.chat_state.save", err))
+			}
 
 			if response {
-				w.WriteResponse(chat.MsgU("🧹 История чата очищена."))
+				w.WriteResponse(chat.MsgU(tr(r, "clear.done")))
 			}
 		},
 	)
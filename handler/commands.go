@@ -5,49 +5,50 @@ import (
 
 	"github.com/muzykantov/health-gpt/chat"
 	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/i18n"
 	"github.com/muzykantov/health-gpt/server"
 )
 
 type Command string
 
 const (
-	CmdUnspecified  Command = ""
-	CmdClear        Command = "clear"
-	CmdStart        Command = "start"
-	CmdExit         Command = "exit"
-	CmdMyGenetics   Command = "mygenetics"
-	CmdMyGeneticsAI Command = "mygenetics_ai"
+	CmdUnspecified   Command = ""
+	CmdClear         Command = "clear"
+	CmdStart         Command = "start"
+	CmdExit          Command = "exit"
+	CmdMyGenetics    Command = "mygenetics"
+	CmdMyGeneticsAI  Command = "mygenetics_ai"
+	CmdVoice         Command = "voice"
+	CmdLanguage      Command = "language"
+	CmdFind          Command = "find"
+	CmdPlan          Command = "plan"
+	CmdCompare       Command = "compare"
+	CmdExport        Command = "export"
+	CmdNotifications Command = "notifications"
+	CmdProfile       Command = "profile"
+	CmdDiary         Command = "diary"
+	CmdJournal       Command = "journal"
 )
 
-var commandsMessage = chat.MsgA(content.Commands{
-	Items: []content.Command{
-		{
-			Name:        string(CmdStart),
-			Description: "Начать новый диалог и предложить варианты",
-		},
-		{
-			Name:        string(CmdClear),
-			Description: "Начать новую сессию (забыть диалог)",
-		},
-		{
-			Name:        string(CmdMyGenetics),
-			Description: "Показать список анализов",
-		},
-		{
-			Name:        string(CmdMyGeneticsAI),
-			Description: "Показать список анализов с интерпретацией ИИ",
-		},
-		{
-			Name:        string(CmdExit),
-			Description: "Выйти из аккаунта",
-		},
-	},
-})
+// commandsMessage возвращает список команд на языке lang.
+func commandsMessage(lang i18n.Lang) chat.Message {
+	cmds := []Command{CmdStart, CmdClear, CmdMyGenetics, CmdMyGeneticsAI, CmdFind, CmdPlan, CmdDiary, CmdJournal, CmdProfile, CmdCompare, CmdExport, CmdNotifications, CmdVoice, CmdLanguage, CmdExit}
+
+	items := make([]content.Command, 0, len(cmds))
+	for _, cmd := range cmds {
+		items = append(items, content.Command{
+			Name:        string(cmd),
+			Description: i18n.T(lang, "command."+string(cmd)),
+		})
+	}
+
+	return chat.MsgA(content.Commands{Items: items})
+}
 
 func commands(cmd Command) server.Handler {
 	return server.HandlerFunc(
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
-			w.WriteResponse(commandsMessage)
+			w.WriteResponse(commandsMessage(lang(r)))
 
 			switch cmd {
 			case CmdUnspecified:
@@ -68,9 +69,53 @@ func commands(cmd Command) server.Handler {
 			case CmdMyGeneticsAI:
 				myGeneticsCodelabs(CmdMyGeneticsAI).Serve(ctx, w, r)
 
+			case CmdFind:
+				var args string
+				if c, ok := r.Incoming.Content.(content.Command); ok {
+					args = c.Args
+				}
+
+				find(args).Serve(ctx, w, r)
+
+			case CmdPlan:
+				plan().Serve(ctx, w, r)
+
+			case CmdDiary:
+				var args string
+				if c, ok := r.Incoming.Content.(content.Command); ok {
+					args = c.Args
+				}
+
+				diary(args).Serve(ctx, w, r)
+
+			case CmdJournal:
+				journal().Serve(ctx, w, r)
+
+			case CmdProfile:
+				profile().Serve(ctx, w, r)
+
+			case CmdCompare:
+				compareCodelabs().Serve(ctx, w, r)
+
+			case CmdExport:
+				exportCodelabs().Serve(ctx, w, r)
+
+			case CmdNotifications:
+				notifications().Serve(ctx, w, r)
+
+			case CmdVoice:
+				voice().Serve(ctx, w, r)
+
+			case CmdLanguage:
+				var args string
+				if c, ok := r.Incoming.Content.(content.Command); ok {
+					args = c.Args
+				}
+
+				language(args).Serve(ctx, w, r)
+
 			default:
-				w.WriteResponse(chat.MsgA("⛔ Неизвестная команда. " +
-					"Пожалуйста, выберите действие из предложенного списка."))
+				w.WriteResponse(msg(r, "error.unknown_command"))
 			}
 		},
 	)
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/genetics"
+	"github.com/muzykantov/health-gpt/handler/prompts"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+const compareCodelabsPrompt = "compare"
+
+// compareCodelabs предлагает выбрать первый анализ для сравнения. Если
+// анализов два, сразу сравнивает их.
+func compareCodelabs() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelabs.fetch_failed"))
+				r.Log.Printf("failed to fetch codelabs (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			switch len(codelabs) {
+			case 0, 1:
+				w.WriteResponse(msg(r, "compare.not_enough"))
+
+			case 2:
+				compareResult(codelabs[0].Code+":"+codelabs[1].Code, false).Serve(ctx, w, r)
+
+			default:
+				msgContent := content.Select{Header: tr(r, "compare.select_first")}
+				for _, codelab := range codelabs {
+					msgContent.Items = append(msgContent.Items, content.SelectItem{
+						Caption: fmt.Sprintf("%s (%s)", codelab.Name, codelab.Code),
+						Data:    PrefixCompare + codelab.Code,
+					})
+				}
+
+				w.WriteResponse(chat.MsgA(msgContent))
+			}
+		},
+	)
+}
+
+// compareSecond предлагает выбрать анализ, с которым сравнить первый.
+func compareSecond(first string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelabs.fetch_failed"))
+				r.Log.Printf("failed to fetch codelabs (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			msgContent := content.Select{Header: tr(r, "compare.select_second", first)}
+			for _, codelab := range codelabs {
+				if codelab.Code == first {
+					continue
+				}
+
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: fmt.Sprintf("%s (%s)", codelab.Name, codelab.Code),
+					Data:    PrefixCompareWith + first + ":" + codelab.Code,
+				})
+			}
+
+			if len(msgContent.Items) == 0 {
+				w.WriteResponse(msg(r, "compare.not_enough"))
+				return
+			}
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// compareResult сравнивает два анализа, заданных как "WN0000T:DX0000T".
+// Без ИИ показывает различия и предлагает их прокомментировать, с ИИ -
+// отправляет различия ассистенту и возвращает его комментарий.
+func compareResult(codes string, useAI bool) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			left, right, ok := strings.Cut(codes, ":")
+			if !ok || left == "" || right == "" {
+				w.WriteResponse(msg(r, "compare.invalid"))
+				r.Log.Printf("invalid compare data (chatID: %d): %s", r.ChatID, codes)
+				return
+			}
+
+			w.WriteResponse(msg(r, "compare.loading", left, right))
+
+			var sets [2]genetics.FeatureSet
+			for i, code := range []string{left, right} {
+				features, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, code)
+				if err != nil {
+					w.WriteResponse(msg(r, "codelab.fetch_failed"))
+					r.Log.Printf("failed to fetch features for codelab %s (chatID: %d): %v",
+						code, r.ChatID, err)
+					return
+				}
+
+				sets[i] = features
+			}
+
+			comparison := genetics.Compare(sets[0], sets[1])
+
+			if !useAI {
+				renderer, err := reportRenderer(r)
+				if err != nil {
+					w.WriteResponse(msg(r, "error.report", err))
+					r.Log.Printf("failed to create report renderer (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				report, err := renderer.Comparison(comparison, left, right)
+				if err != nil {
+					w.WriteResponse(msg(r, "error.report", err))
+					r.Log.Printf("failed to render comparison (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				w.WriteResponse(chat.MsgA(report))
+
+				if !comparison.Equal() {
+					w.WriteResponse(chat.MsgA(content.Select{
+						Header: tr(r, "compare.ai_offer"),
+						Items: []content.SelectItem{{
+							Caption: tr(r, "compare.ai"),
+							Data:    PrefixCompareAI + codes,
+						}},
+					}))
+				}
+
+				return
+			}
+
+			msgs, err := compareMessages(r, comparison, left, right)
+			if errors.Is(err, errPromptNotFound) {
+				w.WriteResponse(msg(r, "error.prompt_not_found"))
+				return
+			}
+			if err != nil {
+				w.WriteResponse(msg(r, "error.report", err))
+				r.Log.Printf("failed to build llm context (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			w.WriteResponse(msg(r, "codelab.analyzing"))
+			w.WriteResponse(chat.MsgA(content.Typing{}))
+
+			response, err := r.Completer.CompleteChat(ctx, msgs)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelab.ai_failed"))
+				r.Log.Printf("failed to complete chat (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			writeAnswer(ctx, w, r, fmt.Sprint(response.Content))
+		},
+	)
+}
+
+// compareMessages формирует запрос к ИИ для комментария к сравнению анализов.
+func compareMessages(
+	r *server.Request,
+	comparison genetics.Comparison,
+	left, right string,
+) ([]chat.Message, error) {
+	prompt := prompts.Get(compareCodelabsPrompt, promptLang(r), r.Completer.ModelName())
+	if prompt == prompts.Default {
+		return nil, errPromptNotFound
+	}
+
+	renderer, err := genetics.NewRenderer(genetics.FormatLLM, genetics.LocaleFor(string(lang(r))))
+	if err != nil {
+		return nil, err
+	}
+
+	comparisonContext, err := renderer.Comparison(comparison, left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	return []chat.Message{
+		chat.MsgS(prompt),
+		chat.MsgU(comparisonContext),
+	}, nil
+}
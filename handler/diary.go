@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/handler/prompts"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+const (
+	diaryPrompt        = "diary"
+	diarySummaryPrompt = "diary_summary"
+)
+
+// errNoFood возвращается, если ИИ не нашел еды в описании приема пищи.
+var errNoFood = errors.New("no food in the meal")
+
+// diary показывает записи дневника питания за сегодня и предлагает добавить
+// прием пищи или подвести итоги. С текстом команды сразу записывает прием
+// пищи.
+func diary(text string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if text = strings.TrimSpace(text); text != "" {
+				diaryAdd(text, nil).Serve(ctx, w, r)
+				return
+			}
+
+			today, err := r.Storage.GetDiary(ctx, r.From.ID, chat.DiaryDate(chat.Now()))
+			if err != nil {
+				w.WriteResponse(msg(r, "error.diary.get", err))
+				r.Log.Printf("failed to read diary (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			header := tr(r, diaryHint(r, "diary.today_empty"))
+			if !today.IsEmpty() {
+				header = tr(r, "diary.today", diaryMeals(r, today), today.Calories())
+			}
+
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: header,
+				Items: []content.SelectItem{
+					{Caption: tr(r, "diary.add"), Data: PrefixDiary + "add"},
+					{Caption: tr(r, "diary.summary.day"), Data: PrefixDiary + "day"},
+					{Caption: tr(r, "diary.summary.week"), Data: PrefixDiary + "week"},
+				},
+			}))
+		},
+	)
+}
+
+// diaryAction обрабатывает кнопки дневника питания.
+func diaryAction(action SelectItemData) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			switch action {
+			case "add":
+				awaitInput(ctx, r, PrefixDiary+action)
+				w.WriteResponse(msg(r, diaryHint(r, "diary.ask")))
+
+			case "day":
+				diarySummary(1).Serve(ctx, w, r)
+
+			case "week":
+				diarySummary(7).Serve(ctx, w, r)
+
+			default:
+				w.WriteResponse(msg(r, "error.unknown_command"))
+			}
+		},
+	)
+}
+
+// diaryPhotos сообщает, можно ли записать прием пищи по фото: модель должна
+// понимать изображения.
+func diaryPhotos(r *server.Request) bool {
+	completer, ok := r.Completer.(server.ImageCompleter)
+	return ok && completer.SupportsImages()
+}
+
+// diaryHint возвращает ключ подсказки дневника. Если модель не понимает
+// изображения, подсказка не предлагает прислать фото.
+func diaryHint(r *server.Request, key string) string {
+	if diaryPhotos(r) {
+		return key
+	}
+
+	return key + ".text"
+}
+
+// diaryAdd распознает с помощью ИИ продукты в описании или на фото приема
+// пищи, записывает его в дневник за сегодня и отвечает комментарием
+// с учетом рекомендаций генетического анализа.
+func diaryAdd(text string, photo *content.Photo) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			prompt := prompts.Get(diaryPrompt, promptLang(r), r.Completer.ModelName())
+			if prompt == prompts.Default {
+				w.WriteResponse(msg(r, "error.prompt_not_found"))
+				return
+			}
+
+			if photo != nil {
+				text = photo.Caption
+			}
+
+			w.WriteResponse(chat.MsgA(content.Typing{}))
+
+			request := tr(r, "diary.context.meal", text)
+			if text == "" {
+				request = tr(r, "diary.context.photo")
+			}
+			mealContext := diaryContext(ctx, r, request)
+
+			var meal chat.Message
+			if photo != nil {
+				meal = chat.MsgU(content.Photo{Data: photo.Data, MIME: photo.MIME, Caption: mealContext})
+			} else {
+				meal = chat.MsgU(mealContext)
+			}
+
+			response, err := r.Completer.CompleteChat(ctx, []chat.Message{chat.MsgS(prompt), meal})
+			if err != nil {
+				w.WriteResponse(msg(r, "diary.failed"))
+				r.Log.Printf("failed to complete meal (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			items, feedback, err := parseMeal(fmt.Sprint(response.Content))
+			if errors.Is(err, errNoFood) {
+				w.WriteResponse(msg(r, diaryHint(r, "diary.no_food")))
+				return
+			}
+			if err != nil {
+				w.WriteResponse(msg(r, "diary.failed"))
+				r.Log.Printf("failed to parse meal (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			now := chat.Now()
+
+			today, err := r.Storage.GetDiary(ctx, r.From.ID, chat.DiaryDate(now))
+			if err != nil {
+				w.WriteResponse(msg(r, "error.diary.get", err))
+				r.Log.Printf("failed to read diary (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			today = today.Add(chat.Meal{
+				Time:     now,
+				Text:     text,
+				Photo:    photo != nil,
+				Items:    items,
+				Feedback: feedback,
+			})
+
+			if err := r.Storage.SaveDiary(ctx, r.From.ID, today); err != nil {
+				w.WriteResponse(msg(r, "error.diary.save", err))
+				r.Log.Printf("failed to save diary (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			w.WriteResponse(msg(r, "diary.added", diaryItems(r, items, ""), today.Calories()))
+
+			if feedback != "" {
+				writeAnswer(ctx, w, r, feedback)
+			}
+		},
+	)
+}
+
+// diarySummary подводит итоги питания за последние days дней: считает
+// калории и особенности состава и просит ИИ сравнить питание
+// с рекомендациями генетического анализа.
+func diarySummary(days int) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			now := chat.Now()
+
+			var diaries []chat.Diary
+			for i := days - 1; i >= 0; i-- {
+				d, err := r.Storage.GetDiary(ctx, r.From.ID, chat.DiaryDate(now.AddDate(0, 0, -i)))
+				if err != nil {
+					w.WriteResponse(msg(r, "error.diary.get", err))
+					r.Log.Printf("failed to read diary (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				if !d.IsEmpty() {
+					diaries = append(diaries, d)
+				}
+			}
+
+			period := tr(r, "diary.period.day")
+			if days > 1 {
+				period = tr(r, "diary.period.week")
+			}
+
+			if len(diaries) == 0 {
+				w.WriteResponse(msg(r, "diary.empty", period))
+				return
+			}
+
+			w.WriteResponse(msg(r, "diary.stats", period, diaryStats(r, diaries)))
+
+			prompt := prompts.Get(diarySummaryPrompt, promptLang(r), r.Completer.ModelName())
+			if prompt == prompts.Default {
+				w.WriteResponse(msg(r, "error.prompt_not_found"))
+				return
+			}
+
+			var entries []string
+			for _, d := range diaries {
+				entries = append(entries, d.Date+":\n"+diaryMeals(r, d))
+			}
+
+			summaryContext := diaryContext(ctx, r, tr(r, "diary.context.summary", period, strings.Join(entries, "\n\n")))
+
+			w.WriteResponse(msg(r, "codelab.analyzing"))
+			w.WriteResponse(chat.MsgA(content.Typing{}))
+
+			response, err := r.Completer.CompleteChat(ctx, []chat.Message{
+				chat.MsgS(prompt),
+				chat.MsgU(summaryContext),
+			})
+			if err != nil {
+				w.WriteResponse(msg(r, "codelab.ai_failed"))
+				r.Log.Printf("failed to complete diary summary (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			writeAnswer(ctx, w, r, fmt.Sprint(response.Content))
+		},
+	)
+}
+
+// diaryContext дополняет запрос к ИИ рекомендациями по питанию из анализа
+// и анкетой пользователя. Без рекомендаций ИИ комментирует питание
+// в общем.
+func diaryContext(ctx context.Context, r *server.Request, request string) string {
+	parts := []string{request}
+
+	if nutrition := diaryNutrition(ctx, r); nutrition != "" {
+		parts = append(parts, tr(r, "diary.context.nutrition", nutrition))
+	}
+
+	if profileMsg := profileContext(r); profileMsg != "" {
+		parts = append(parts, profileMsg)
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// diaryNutrition перечисляет рекомендации по питанию из анализа, выбранного
+// в чате, или из первого анализа пользователя. Признаки с высоким риском
+// идут первыми.
+func diaryNutrition(ctx context.Context, r *server.Request) string {
+	access := mygenetics.AccessToken(r.From.Tokens)
+	if access == "" {
+		return ""
+	}
+
+	code, err := notifyCodelabCode(ctx, r, access)
+	if err != nil {
+		r.Log.Printf("failed to choose codelab for diary (chatID: %d): %v", r.ChatID, err)
+		return ""
+	}
+	if code == "" {
+		return ""
+	}
+
+	features, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, code)
+	if err != nil {
+		r.Log.Printf("failed to fetch features for diary (chatID: %d): %v", r.ChatID, err)
+		return ""
+	}
+
+	var lines []string
+	for _, feature := range features.SortByRisk() {
+		for _, text := range feature.Nutrition {
+			lines = append(lines, fmt.Sprintf("- %s: %s", feature.Name, text))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseMeal разбирает ответ ИИ с продуктами приема пищи. ИИ может обернуть
+// JSON в текст или разметку, поэтому разбирается первый объект ответа.
+func parseMeal(response string) ([]chat.FoodItem, string, error) {
+	start, end := strings.Index(response, "{"), strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return nil, "", fmt.Errorf("no json in response: %q", response)
+	}
+
+	var meal struct {
+		Items []struct {
+			Name     string   `json:"name"`
+			Amount   string   `json:"amount"`
+			Calories float64  `json:"calories"`
+			Tags     []string `json:"tags"`
+		} `json:"items"`
+		Feedback string `json:"feedback"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &meal); err != nil {
+		return nil, "", err
+	}
+
+	var items []chat.FoodItem
+	for _, item := range meal.Items {
+		if strings.TrimSpace(item.Name) == "" {
+			continue
+		}
+
+		food := chat.FoodItem{
+			Name:     strings.TrimSpace(item.Name),
+			Amount:   strings.TrimSpace(item.Amount),
+			Calories: max(int(item.Calories+0.5), 0),
+		}
+		for _, tag := range item.Tags {
+			if t := chat.FoodTag(tag); slices.Contains(chat.FoodTags, t) && !slices.Contains(food.Tags, t) {
+				food.Tags = append(food.Tags, t)
+			}
+		}
+
+		items = append(items, food)
+	}
+
+	if len(items) == 0 {
+		return nil, "", errNoFood
+	}
+
+	return items, strings.TrimSpace(meal.Feedback), nil
+}
+
+// diaryItems перечисляет продукты по одному на строку с отступом indent.
+func diaryItems(r *server.Request, items []chat.FoodItem, indent string) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		details := tr(r, "diary.calories", item.Calories)
+		if item.Amount != "" {
+			details = item.Amount + ", " + details
+		}
+
+		lines = append(lines, fmt.Sprintf("%s• %s (%s)", indent, item.Name, details))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// diaryMeals перечисляет приемы пищи за день с временем записи.
+func diaryMeals(r *server.Request, d chat.Diary) string {
+	lines := make([]string, 0, len(d.Meals))
+	for _, meal := range d.Meals {
+		lines = append(lines, meal.Time.Format("15:04")+"\n"+diaryItems(r, meal.Items, "  "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// diaryStats описывает приемы пищи, калорийность и особенности состава
+// за период.
+func diaryStats(r *server.Request, diaries []chat.Diary) string {
+	var meals, calories int
+	tags := make(map[chat.FoodTag]int)
+	for _, d := range diaries {
+		meals += len(d.Meals)
+		calories += d.Calories()
+		for tag, n := range d.TagCounts() {
+			tags[tag] += n
+		}
+	}
+
+	lines := []string{
+		tr(r, "diary.stats.meals", meals, len(diaries)),
+		tr(r, "diary.stats.calories", calories/len(diaries)),
+	}
+	for _, tag := range chat.FoodTags {
+		if n := tags[tag]; n > 0 {
+			lines = append(lines, tr(r, "diary.stats.tag", tr(r, "diary.tag."+string(tag)), n))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -11,7 +11,24 @@ func exit() server.Handler {
 	return server.HandlerFunc(
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
 			if err := r.Storage.SaveChatHistory(ctx, r.ChatID, []chat.Message{}); err != nil {
-				w.WriteResponse(chat.MsgAf("⚠️ Ошибка записи истории чата: %v", err))
+				w.WriteResponse(msg(r, "error.history.save", err))
+			}
+
+			state, err := r.Storage.GetChatState(ctx, r.ChatID)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.chat_state.get", err))
+				return
+			}
+
+			if err := r.Storage.SaveChatState(ctx, r.ChatID, state.Reset()); err != nil {
+				w.WriteResponse(msg(r, "error.chat_state.save", err))
+			}
+
+			// После выхода уведомления чата больше не нужны.
+			for _, kind := range chat.JobKinds {
+				if err := r.Storage.DeleteJob(ctx, chat.JobID(r.ChatID, kind, "")); err != nil {
+					r.Log.Printf("failed to delete %s job (chatID: %d): %v", kind, r.ChatID, err)
+				}
 			}
 
 			r.From.Password = ""
@@ -19,11 +36,11 @@ func exit() server.Handler {
 			r.From.State = chat.UserStateUnauthorized
 
 			if err := r.Storage.SaveUser(ctx, r.From); err != nil {
-				w.WriteResponse(chat.MsgAf("⛔ Ошибка сохранения пользователя: %v", err))
+				w.WriteResponse(msg(r, "error.user.save", err))
 				return
 			}
 
-			w.WriteResponse(chat.MsgA("👋 Вы успешно вышли из системы. До новых встреч!"))
+			w.WriteResponse(msg(r, "auth.signed_out"))
 			Start().Serve(ctx, w, r)
 		},
 	)
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/export"
+	"github.com/muzykantov/health-gpt/genetics"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// exportWithAI отмечает формат отчета с интерпретацией ИИ.
+const exportWithAI = "+ai"
+
+// exportCodelabs предлагает выбрать анализ для отчета. Если анализ один,
+// сразу предлагает выбрать формат.
+func exportCodelabs() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelabs.fetch_failed"))
+				r.Log.Printf("failed to fetch codelabs (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			switch len(codelabs) {
+			case 0:
+				w.WriteResponse(msg(r, "codelabs.empty"))
+
+			case 1:
+				exportFormats(codelabs[0].Code).Serve(ctx, w, r)
+
+			default:
+				msgContent := content.Select{Header: tr(r, "export.select_codelab")}
+				for _, codelab := range codelabs {
+					msgContent.Items = append(msgContent.Items, content.SelectItem{
+						Caption: fmt.Sprintf("%s (%s)", codelab.Name, codelab.Code),
+						Data:    PrefixExport + codelab.Code,
+					})
+				}
+
+				w.WriteResponse(chat.MsgA(msgContent))
+			}
+		},
+	)
+}
+
+// exportFormats предлагает выбрать формат отчета по анализу. PDF не
+// предлагается, если не найден шрифт.
+func exportFormats(code string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			msgContent := content.Select{
+				Header:  tr(r, "export.select_format", code),
+				Columns: 2,
+			}
+
+			for _, format := range export.Formats {
+				if format == export.FormatPDF {
+					if _, err := export.DefaultFont(); err != nil {
+						r.Log.Printf("pdf export is unavailable: %v", err)
+						continue
+					}
+				}
+
+				data := fmt.Sprintf("%s%s:%s", PrefixExportFormat, format, code)
+				msgContent.Items = append(msgContent.Items,
+					content.SelectItem{
+						Caption: format.Name(),
+						Data:    data,
+					},
+					content.SelectItem{
+						Caption: tr(r, "export.with_ai", format.Name()),
+						Data:    fmt.Sprintf("%s%s%s:%s", PrefixExportFormat, format, exportWithAI, code),
+					},
+				)
+			}
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// exportDocument формирует отчет по анализу в выбранном формате
+// (например, export_as:pdf+ai:WN0000T) и отправляет его документом.
+func exportDocument(data SelectItemData) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			formatName, code, ok := strings.Cut(strings.TrimPrefix(data, PrefixExportFormat), ":")
+			formatName, withAI := strings.CutSuffix(formatName, exportWithAI)
+
+			format, err := export.ParseFormat(formatName)
+			if !ok || code == "" || err != nil {
+				w.WriteResponse(msg(r, "export.invalid"))
+				r.Log.Printf("invalid export data (chatID: %d): %s", r.ChatID, data)
+				return
+			}
+
+			w.WriteResponse(msg(r, "export.preparing"))
+			w.WriteResponse(chat.MsgA(content.Typing{}))
+
+			features, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, code)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelab.fetch_failed"))
+				r.Log.Printf("failed to fetch features (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			report := export.Report{
+				Title:    tr(r, "export.title"),
+				Subtitle: tr(r, "export.subtitle", code, time.Now().Format(tr(r, "export.date_layout"))),
+				Locale:   genetics.LocaleFor(string(lang(r))),
+				Features: features,
+				Note:     tr(r, "export.note"),
+			}
+
+			if withAI {
+				msgs, err := codelabMessages(r, features)
+				if err != nil {
+					w.WriteResponse(msg(r, "export.failed"))
+					r.Log.Printf("failed to build llm context (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				response, err := r.Completer.CompleteChat(ctx, msgs)
+				if err != nil {
+					w.WriteResponse(msg(r, "codelab.ai_failed"))
+					r.Log.Printf("failed to complete chat (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				report.InterpretationTitle = tr(r, "export.interpretation")
+				report.Interpretation = fmt.Sprint(response.Content)
+			}
+
+			document, err := export.Render(format, report)
+			if err != nil {
+				w.WriteResponse(msg(r, "export.failed"))
+				r.Log.Printf("failed to render %s report (chatID: %d): %v", format, r.ChatID, err)
+				return
+			}
+
+			w.WriteResponse(chat.MsgA(content.Document{
+				Name:    fmt.Sprintf("report-%s.%s", code, format),
+				MIME:    format.MIME(),
+				Data:    document,
+				Caption: tr(r, "export.caption", code),
+			}))
+		},
+	)
+}
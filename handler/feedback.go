@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/metrics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// maxFeedbackComment ограничивает длину пояснения к неточному ответу.
+const maxFeedbackComment = 1000
+
+// writeRatedAnswer отправляет ответ ИИ на сообщение questionID с кнопками
+// оценки и запоминает, с каким промптом и моделью он получен. score - оценка
+// достоверности ответа валидатором, 0, если ответ не проверялся.
+func writeRatedAnswer(ctx context.Context, w server.ResponseWriter, r *server.Request, answer, prompt, questionID, answerID string, score float64) {
+	rating := chat.Rating{
+		ID:         answerID,
+		ChatID:     r.ChatID,
+		UserID:     r.From.ID,
+		Prompt:     prompt,
+		Model:      r.Completer.ModelName(),
+		Score:      score,
+		QuestionID: questionID,
+		CreatedAt:  chat.Now(),
+	}
+
+	// Без сохраненного ответа оценку не к чему привязать, поэтому кнопки
+	// не показываются.
+	if err := r.Storage.SaveRating(ctx, rating); err != nil {
+		r.Log.Printf("failed to save rating (chatID: %d): %v", r.ChatID, err)
+		writeAnswer(ctx, w, r, answer)
+		return
+	}
+
+	msgContent := content.Select{
+		Header:  answer,
+		Columns: len(chat.RatingValues),
+	}
+	for _, value := range chat.RatingValues {
+		msgContent.Items = append(msgContent.Items, content.SelectItem{
+			Caption: tr(r, "feedback."+string(value)),
+			Data:    PrefixFeedback + string(value) + ":" + answerID,
+		})
+	}
+
+	// К голосовому ответу кнопки не прикрепить, поэтому они отправляются
+	// отдельным сообщением.
+	if r.From.VoiceReplies && r.Synthesizer != nil {
+		writeAnswer(ctx, w, r, answer)
+		msgContent.Header = tr(r, "feedback.header")
+	}
+
+	w.WriteResponse(chat.MsgA(msgContent))
+}
+
+// feedbackAction сохраняет оценку ответа. Данные кнопки имеют вид
+// "<оценка>:<идентификатор ответа>". К оценке "неточно" предлагается
+// написать пояснение; вопрос вместо пояснения передается ИИ.
+func feedbackAction(data SelectItemData) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			value, id, _ := strings.Cut(data, ":")
+
+			rating, err := r.Storage.GetRating(ctx, id)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.feedback.save", err))
+				r.Log.Printf("failed to read rating (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			switch {
+			case rating.ID == "":
+				w.WriteResponse(msg(r, "feedback.unknown"))
+				return
+
+			case rating.IsRated():
+				w.WriteResponse(msg(r, "feedback.already"))
+				return
+			}
+
+			switch v := chat.RatingValue(value); v {
+			case chat.RatingUp, chat.RatingDown, chat.RatingInaccurate:
+				rating.Value = v
+			default:
+				w.WriteResponse(msg(r, "error.unknown_command"))
+				return
+			}
+
+			rating.RatedAt = chat.Now()
+			if err := r.Storage.SaveRating(ctx, rating); err != nil {
+				w.WriteResponse(msg(r, "error.feedback.save", err))
+				r.Log.Printf("failed to save rating (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			metrics.RecordFeedback(rating.Prompt, rating.Model, string(rating.Value))
+
+			if rating.Value == chat.RatingInaccurate {
+				awaitInput(ctx, r, PrefixFeedback+rating.ID)
+				w.WriteResponse(msg(r, "feedback.ask_comment"))
+				return
+			}
+
+			w.WriteResponse(msg(r, "feedback.thanks"))
+		},
+	)
+}
+
+// feedbackComment сохраняет пояснение пользователя к неточному ответу.
+func feedbackComment(id, text string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			clearInput(ctx, r)
+
+			rating, err := r.Storage.GetRating(ctx, id)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.feedback.save", err))
+				r.Log.Printf("failed to read rating (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+			if rating.ID == "" {
+				w.WriteResponse(msg(r, "feedback.unknown"))
+				return
+			}
+
+			text = strings.TrimSpace(text)
+			if utf8.RuneCountInString(text) > maxFeedbackComment {
+				text = string([]rune(text)[:maxFeedbackComment])
+			}
+
+			rating.Comment = text
+			if err := r.Storage.SaveRating(ctx, rating); err != nil {
+				w.WriteResponse(msg(r, "error.feedback.save", err))
+				r.Log.Printf("failed to save rating (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			w.WriteResponse(msg(r, "feedback.comment_saved"))
+		},
+	)
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/genetics"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// findPageSize - количество признаков на одной странице результатов.
+const findPageSize = 5
+
+// find ищет признаки по запросу из аргументов команды /find. Без запроса
+// предлагает готовые фильтры по категориям и уровню риска.
+func find(query string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if strings.TrimSpace(query) != "" {
+				findQuery(query).Serve(ctx, w, r)
+				return
+			}
+
+			msgContent := content.Select{
+				Header:  tr(r, "find.usage"),
+				Columns: 2,
+			}
+			for _, category := range genetics.Categories {
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: tr(r, "find.category."+string(category)),
+					Data:    PrefixFindQuery + genetics.Query{Category: category}.String(),
+				})
+			}
+			for _, risk := range []genetics.Risk{genetics.RiskHigh, genetics.RiskModerate} {
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: tr(r, "find.risk."+risk.String()),
+					Data:    PrefixFindQuery + genetics.Query{MinRisk: risk}.String(),
+				})
+			}
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// findQuery запоминает запрос и предлагает выбрать анализ для поиска.
+// Если анализ один, сразу показывает результаты.
+func findQuery(query string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelabs.fetch_failed"))
+				r.Log.Printf("failed to fetch codelabs (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			// Запрос может быть длиннее допустимых данных кнопки, поэтому
+			// в данных передается только идентификатор сообщения.
+			r.Cache.Add(PrefixFind+r.Incoming.ID, query)
+
+			switch len(codelabs) {
+			case 0:
+				w.WriteResponse(msg(r, "codelabs.empty"))
+
+			case 1:
+				findResults(findData(r.Incoming.ID, codelabs[0].Code, 0)).Serve(ctx, w, r)
+
+			default:
+				msgContent := content.Select{Header: tr(r, "find.select_codelab")}
+				for _, codelab := range codelabs {
+					msgContent.Items = append(msgContent.Items, content.SelectItem{
+						Caption: fmt.Sprintf("%s (%s)", codelab.Name, codelab.Code),
+						Data:    findData(r.Incoming.ID, codelab.Code, 0),
+					})
+				}
+
+				w.WriteResponse(chat.MsgA(msgContent))
+			}
+		},
+	)
+}
+
+// findData формирует данные кнопки со страницей результатов поиска.
+// Пустой идентификатор запроса означает все признаки анализа.
+func findData(queryID, code string, page int) SelectItemData {
+	return fmt.Sprintf("%s%s:%s:%d", PrefixFind, queryID, code, page)
+}
+
+// findResults показывает страницу результатов поиска по анализу, заданную
+// как "find:<идентификатор запроса>:<код анализа>:<страница>", и кнопки
+// для перехода между страницами.
+func findResults(data SelectItemData) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			parts := strings.Split(strings.TrimPrefix(data, PrefixFind), ":")
+			if len(parts) != 3 {
+				w.WriteResponse(msg(r, "find.invalid"))
+				r.Log.Printf("invalid find data (chatID: %d): %s", r.ChatID, data)
+				return
+			}
+
+			queryID, code := parts[0], parts[1]
+
+			page, err := strconv.Atoi(parts[2])
+			if err != nil || page < 0 {
+				w.WriteResponse(msg(r, "find.invalid"))
+				r.Log.Printf("invalid find page (chatID: %d): %s", r.ChatID, data)
+				return
+			}
+
+			var query genetics.Query
+			if queryID != "" {
+				cached, ok := r.Cache.Get(PrefixFind + queryID)
+				if !ok {
+					w.WriteResponse(msg(r, "find.expired"))
+					return
+				}
+
+				query = genetics.ParseQuery(cached.(string))
+			}
+
+			features, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, code)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelab.fetch_failed"))
+				r.Log.Printf("failed to fetch features (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			found := features.Search(query)
+			if len(found) == 0 {
+				w.WriteResponse(msg(r, "find.nothing", query, code))
+				return
+			}
+
+			pages := (len(found) + findPageSize - 1) / findPageSize
+			page = min(page, pages-1)
+
+			renderer, err := reportRenderer(r)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.report", err))
+				r.Log.Printf("failed to create report renderer (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			reports := make([]string, 0, findPageSize)
+			for _, feature := range found[page*findPageSize : min((page+1)*findPageSize, len(found))] {
+				report, err := renderer.Feature(feature)
+				if err != nil {
+					w.WriteResponse(msg(r, "error.report", err))
+					r.Log.Printf("failed to render feature (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				reports = append(reports, report)
+			}
+
+			w.WriteResponse(chat.MsgA(strings.Join(reports, "\n")))
+
+			if pages == 1 {
+				return
+			}
+
+			nav := content.Select{
+				Header:  tr(r, "find.page", len(found), page+1, pages),
+				Columns: 2,
+			}
+			if page > 0 {
+				nav.Items = append(nav.Items, content.SelectItem{
+					Caption: tr(r, "find.prev"),
+					Data:    findData(queryID, code, page-1),
+				})
+			}
+			if page < pages-1 {
+				nav.Items = append(nav.Items, content.SelectItem{
+					Caption: tr(r, "find.next"),
+					Data:    findData(queryID, code, page+1),
+				})
+			}
+
+			w.WriteResponse(chat.MsgA(nav))
+		},
+	)
+}
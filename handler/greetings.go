@@ -3,7 +3,6 @@ package handler
 import (
 	"context"
 
-	"github.com/muzykantov/health-gpt/chat"
 	"github.com/muzykantov/health-gpt/server"
 )
 
@@ -13,13 +12,12 @@ import (
 func greetings() server.Handler {
 	return server.HandlerFunc(
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
-			w.WriteResponse(chat.MsgA("👋 Добро пожаловать! Вы можете выбрать анализы из " +
-				"списка и получить их интерпретацию с помощью искусственного интеллекта. " +
-				"Также вы можете задавать вопросы относительно имеющихся анализов в базе."))
+			w.WriteResponse(msg(r, "greetings.welcome"))
 
 			clear(false).Serve(ctx, w, r)
 			commands(CmdUnspecified).Serve(ctx, w, r)
 			myGeneticsCodelabs(CmdUnspecified).Serve(ctx, w, r)
+			profileOffer().Serve(ctx, w, r)
 		},
 	)
 }
@@ -0,0 +1,1067 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/genetics"
+	"github.com/muzykantov/health-gpt/i18n"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+	"github.com/muzykantov/health-gpt/server/servertest"
+)
+
+const (
+	testEmail    = "user@example.com"
+	testPassword = "secret"
+)
+
+var testFeatures = genetics.FeatureSet{
+	{
+		Name: "Метаболизм кофеина",
+		Genes: []genetics.Gene{
+			{Name: "CYP1A2", Interpretations: []string{"Медленный метаболизм кофеина."}},
+		},
+		Conclusions: []string{"Кофеин выводится медленно."},
+		Nutrition:   []string{"Ограничьте кофе до 1 чашки в день."},
+		Checklist:   []string{"Не пить кофе после 14:00"},
+	},
+}
+
+// newMyGenetics starts a fake MyGenetics API with two codelabs.
+func newMyGenetics(t *testing.T) *servertest.MyGenetics {
+	t.Helper()
+
+	fake := servertest.NewMyGenetics(testEmail, testPassword)
+	fake.AddCodelab("WN0000T", "Питание", testFeatures)
+	fake.AddCodelab("DX0000T", "Спорт", testFeatures)
+	fake.Install(t)
+
+	return fake
+}
+
+// authorize stores an authorized user with the chat in the chatting stage.
+func authorize(t *testing.T, conv *servertest.Conversation, fake *servertest.MyGenetics) {
+	t.Helper()
+
+	user := conv.User
+	user.Email = testEmail
+	user.Password = testPassword
+	user.Tokens = fake.Tokens()
+	user.State = chat.UserStateAuthorized
+
+	if err := conv.Storage.SaveUser(t.Context(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := chat.State{}.StartChatting()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conv.Storage.SaveChatState(t.Context(), conv.ChatID, state); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoginFlow(t *testing.T) {
+	newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+
+	completer.Expect(
+		servertest.SystemPromptContains("email"),
+		servertest.LastUserMessage("Привет"),
+	).Reply("Пожалуйста, введите email и пароль.")
+
+	conv.Send("Привет").
+		ExpectCommands(string(CmdStart)).
+		ExpectTextContaining("введите email и пароль")
+
+	completer.Expect(
+		servertest.PromptContains("Пожалуйста, введите email и пароль."),
+		servertest.LastUserMessage(testEmail+" "+testPassword),
+	).Reply(`{"email":"` + testEmail + `","password":"` + testPassword + `"}`)
+
+	conv.Send(testEmail + " " + testPassword).
+		ExpectTextContaining("Вы успешно вошли").
+		ExpectTextContaining("Добро пожаловать").
+		ExpectSelect(2)
+
+	user, err := conv.Storage.GetUser(t.Context(), conv.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.State != chat.UserStateAuthorized || user.Email != testEmail {
+		t.Errorf("user after login = %+v", user)
+	}
+
+	state, err := conv.Storage.GetChatState(t.Context(), conv.ChatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Stage != chat.StageChatting {
+		t.Errorf("stage after login = %v, want %v", state.Stage, chat.StageChatting)
+	}
+}
+
+func TestLoginInvalidCredentials(t *testing.T) {
+	newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+
+	completer.Expect().Reply(`{"email":"` + testEmail + `","password":"wrong"}`)
+
+	conv.Send(testEmail + " wrong").
+		ExpectTextContaining("Имя пользователя или пароль не подходят")
+}
+
+func TestMyGeneticsCommand(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	conv := servertest.NewConversation(t, Start(), servertest.NewCompleter(t))
+	authorize(t, conv, fake)
+
+	conv.Command(string(CmdMyGenetics)).
+		ExpectSelect(2).
+		Press(1).
+		ExpectTextContaining("Метаболизм кофеина").
+		ExpectTextContaining("CYP1A2")
+}
+
+func TestMyGeneticsReportFormat(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	conv := servertest.NewConversation(t, Start(), servertest.NewCompleter(t))
+	conv.Format = server.FormatMarkdown
+	conv.User.Language = string(i18n.English)
+	authorize(t, conv, fake)
+
+	conv.Command(string(CmdMyGenetics)).
+		ExpectSelect(2).
+		Press(1).
+		ExpectTextContaining("### 🧬 Feature: Метаболизм кофеина").
+		ExpectTextContaining("- **CYP1A2**").
+		ExpectNoTextContaining("<b>")
+}
+
+func TestMyGeneticsAIChat(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	// Two codelabs: the assistant asks which one to use.
+	turn := conv.Send("Можно ли мне пить кофе?").ExpectSelect(2)
+
+	completer.Expect(
+		servertest.PromptContains("CYP1A2"),
+		servertest.LastUserMessage("Можно ли мне пить кофе?"),
+	).Reply("Лучше ограничить кофе.")
+
+	turn.Press(2).ExpectTextContaining("Вот, что показывают данные из анализа DX0000T")
+
+	// The selected codelab is persisted and reused for the next question.
+	completer.Expect(
+		servertest.PromptContains("Лучше ограничить кофе."),
+		servertest.LastUserMessage("А чай?"),
+	).Reply("Чай можно.")
+
+	conv.Send("А чай?").ExpectTextContaining("Чай можно.")
+
+	state, err := conv.Storage.GetChatState(t.Context(), conv.ChatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code, ok := state.SelectedCodelab(); !ok || code != "DX0000T" {
+		t.Errorf("selected codelab = %q, %v, want DX0000T", code, ok)
+	}
+}
+
+func TestChatStateUpgrade(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	// Chats started before chat.State have history but no saved state.
+	if err := conv.Storage.SaveChatState(t.Context(), conv.ChatID, chat.State{}); err != nil {
+		t.Fatal(err)
+	}
+
+	history := []chat.Message{chat.MsgU("Можно ли мне пить кофе?"), chat.MsgA("Лучше ограничить кофе.")}
+	if err := conv.Storage.SaveChatHistory(t.Context(), conv.ChatID, history); err != nil {
+		t.Fatal(err)
+	}
+
+	conv.Send("А чай?").ExpectNoTextContaining("Добро пожаловать").ExpectSelect(2)
+
+	state, err := conv.Storage.GetChatState(t.Context(), conv.ChatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Stage != chat.StageChatting {
+		t.Errorf("stage = %s, want chatting", state.Stage)
+	}
+
+	saved, err := conv.Storage.GetChatHistory(t.Context(), conv.ChatID, 0)
+	if err != nil || len(saved) != len(history) {
+		t.Errorf("history = %v, %v, want it kept", saved, err)
+	}
+}
+
+func TestClientLanguage(t *testing.T) {
+	newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	conv.User.LanguageCode = "en-US"
+
+	completer.Expect(
+		servertest.SystemPromptContains("Reply in English"),
+		servertest.LastUserMessage("Hi"),
+	).Reply("Please enter your email and password.")
+
+	conv.Send("Hi").ExpectTextContaining("Please enter your email")
+
+	completer.Expect().Reply(`{"email":"` + testEmail + `","password":"` + testPassword + `"}`)
+
+	conv.Send(testEmail + " " + testPassword).
+		ExpectTextContaining("You have signed in").
+		ExpectTextContaining("Welcome").
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.HasPrefix(s.Header, "🧪 Choose a test")
+		})
+}
+
+func TestLanguageCommand(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	conv := servertest.NewConversation(t, Start(), servertest.NewCompleter(t))
+	authorize(t, conv, fake)
+
+	conv.Command(string(CmdLanguage)).
+		ExpectSelect(len(i18n.Languages())).
+		Press(2).
+		ExpectTextContaining("Interface language: English").
+		ExpectCommands(string(CmdLanguage))
+
+	user, err := conv.Storage.GetUser(t.Context(), conv.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Language != string(i18n.English) {
+		t.Errorf("user language = %q, want %q", user.Language, i18n.English)
+	}
+
+	conv.Command(string(CmdMyGenetics)).ExpectSelectWith(func(s content.Select) bool {
+		return strings.HasPrefix(s.Header, "🧪 Choose a test")
+	})
+
+	conv.Command(string(CmdLanguage), "de").ExpectTextContaining(`Language "de" is not supported`)
+	conv.Command(string(CmdLanguage), "ru").ExpectTextContaining("Язык интерфейса: русский")
+}
+
+func TestLanguageBeforeLogin(t *testing.T) {
+	newMyGenetics(t)
+
+	// The language is chosen without the login dialog with the AI.
+	conv := servertest.NewConversation(t, Start(), servertest.NewCompleter(t))
+
+	conv.Command(string(CmdLanguage)).
+		ExpectSelect(len(i18n.Languages())).
+		Press(2).
+		ExpectTextContaining("Interface language: English").
+		ExpectCommands(string(CmdStart), string(CmdLanguage))
+
+	user, err := conv.Storage.GetUser(t.Context(), conv.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Language != string(i18n.English) || user.State != chat.UserStateUnauthorized {
+		t.Errorf("user language = %q, state = %v", user.Language, user.State)
+	}
+}
+
+// echoSynthesizer "voices" text by returning it as audio.
+type echoSynthesizer struct{}
+
+func (echoSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	return []byte(text), nil
+}
+
+func TestVoiceReplies(t *testing.T) {
+	// A single codelab is used without asking.
+	fake := servertest.NewMyGenetics(testEmail, testPassword)
+	fake.AddCodelab("WN0000T", "Питание", testFeatures)
+	fake.Install(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	conv.Synthesizer = echoSynthesizer{}
+	authorize(t, conv, fake)
+
+	conv.Command(string(CmdVoice)).ExpectTextContaining("Голосовые ответы включены")
+
+	completer.Expect().Reply("**Кофе** лучше ограничить.")
+
+	turn := conv.Send("Можно ли мне пить кофе?").
+		ExpectNoTextContaining("Кофе")
+
+	voices := turn.Voices()
+	if len(voices) != 1 {
+		t.Fatalf("got %d voice messages, want 1", len(voices))
+	}
+	if got := string(voices[0].Data); !strings.HasSuffix(got, "Кофе лучше ограничить.") {
+		t.Errorf("voiced text = %q", got)
+	}
+
+	conv.Command(string(CmdVoice)).ExpectTextContaining("Голосовые ответы выключены")
+
+	completer.Expect().Reply("Чай можно.")
+	if voices := conv.Send("А чай?").ExpectTextContaining("Чай можно.").Voices(); len(voices) != 0 {
+		t.Errorf("got %d voice messages after disabling voice replies", len(voices))
+	}
+}
+
+func TestSplitSpeech(t *testing.T) {
+	text := strings.Repeat("Первое предложение. ", 5) + strings.Repeat("слово", 30)
+
+	chunks := splitSpeech(text, 50)
+	for _, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 50 {
+			t.Errorf("chunk %q has %d characters", chunk, n)
+		}
+	}
+
+	if got := strings.Join(chunks, ""); strings.ReplaceAll(got, " ", "") != strings.ReplaceAll(text, " ", "") {
+		t.Errorf("text was lost while splitting: %q", chunks)
+	}
+}
+
+// itemByCaption returns the 1-based index of the select item with the caption.
+func itemByCaption(t *testing.T, turn *servertest.Turn, caption string) int {
+	t.Helper()
+
+	var index int
+	turn.ExpectSelectWith(func(s content.Select) bool {
+		for i, item := range s.Items {
+			if item.Caption == caption {
+				index = i + 1
+				return true
+			}
+		}
+		return false
+	})
+
+	return index
+}
+
+func TestExportReport(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	turn := conv.Command(string(CmdExport)).ExpectSelect(2).Press(2)
+
+	docs := turn.Press(itemByCaption(t, turn, "Markdown")).
+		ExpectTextContaining("Готовлю отчет").
+		Documents()
+	if len(docs) != 1 {
+		t.Fatalf("got %d documents, want 1", len(docs))
+	}
+	if docs[0].Name != "report-DX0000T.md" || docs[0].MIME != "text/markdown; charset=utf-8" {
+		t.Errorf("document = %q (%s)", docs[0].Name, docs[0].MIME)
+	}
+	if got := string(docs[0].Data); !strings.Contains(got, "CYP1A2") || !strings.Contains(got, "Анализ DX0000T") {
+		t.Errorf("report does not contain the features:\n%s", got)
+	}
+
+	completer.Expect(servertest.PromptContains("CYP1A2")).Reply("## Итог\n\n**Кофе** лучше ограничить.")
+
+	turn = conv.Command(string(CmdExport)).ExpectSelect(2).Press(1)
+
+	docs = turn.Press(itemByCaption(t, turn, "HTML + ИИ")).Documents()
+	if len(docs) != 1 {
+		t.Fatalf("got %d documents, want 1", len(docs))
+	}
+	if got := string(docs[0].Data); !strings.Contains(got, "Интерпретация ИИ") || !strings.Contains(got, "Кофе") {
+		t.Errorf("report does not contain the interpretation:\n%s", got)
+	}
+}
+
+func TestCompareCodelabs(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	relative := genetics.FeatureSet{{
+		Name: "Метаболизм кофеина",
+		Genes: []genetics.Gene{
+			{Name: "CYP1A2", Interpretations: []string{"Быстрый метаболизм кофеина."}},
+		},
+	}}
+	fake.AddCodelab("RL0000T", "Родственник", relative)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	turn := conv.Command(string(CmdCompare)).
+		ExpectSelect(3).
+		Press(1).
+		ExpectSelect(2).
+		Press(2).
+		ExpectTextContaining("Сравнение анализов: WN0000T → RL0000T").
+		ExpectTextContaining("Быстрый метаболизм кофеина.").
+		ExpectSelect(1)
+
+	completer.Expect(
+		servertest.SystemPromptContains("сравнение двух генетических анализов"),
+		servertest.PromptContains("RL0000T: Быстрый метаболизм кофеина."),
+	).Reply("Кофеин выводится с разной скоростью.")
+
+	turn.Press(1).ExpectTextContaining("Кофеин выводится с разной скоростью.")
+
+	// The same features have no differences to comment on.
+	conv.Command(string(CmdCompare)).
+		ExpectSelect(3).
+		Press(2).
+		ExpectSelect(2).
+		Press(1).
+		ExpectTextContaining("Различий не найдено.")
+}
+
+func TestMyGeneticsAIRiskOrder(t *testing.T) {
+	fake := servertest.NewMyGenetics(testEmail, testPassword)
+	fake.AddCodelab("WN0000T", "Питание", append(genetics.FeatureSet{}, testFeatures[0], genetics.Feature{
+		Name: "Выносливость",
+		Genes: []genetics.Gene{{
+			Name:            "PPARA",
+			Interpretations: []string{"Сниженная выносливость."},
+			RSID:            "rs4253778",
+			Genotype:        "G/C",
+			Risk:            genetics.RiskHigh,
+		}},
+	}))
+	fake.Install(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	completer.Expect(
+		servertest.PromptContains("## Признак 1: Выносливость\n\nРиск: high\nКатегории: sport\n"),
+		servertest.PromptContains("#### PPARA rs4253778 G/C\n"),
+	).Reply("Больше тренируйтесь.")
+
+	conv.Command(string(CmdMyGeneticsAI)).
+		ExpectSelect(1).
+		Press(1).
+		ExpectTextContaining("Больше тренируйтесь.")
+}
+
+func TestFind(t *testing.T) {
+	var features genetics.FeatureSet
+	for i := 1; i <= 7; i++ {
+		features = append(features, genetics.Feature{
+			Name:  fmt.Sprintf("Признак %d", i),
+			Genes: []genetics.Gene{{Name: fmt.Sprintf("GENE%d", i), Interpretations: []string{"Норма."}}},
+		})
+	}
+	features[6].Genes[0].Risk = genetics.RiskHigh
+
+	fake := servertest.NewMyGenetics(testEmail, testPassword)
+	fake.AddCodelab("WN0000T", "Питание", features)
+	fake.Install(t)
+
+	conv := servertest.NewConversation(t, Start(), servertest.NewCompleter(t))
+	authorize(t, conv, fake)
+
+	conv.Command(string(CmdFind), "признак").
+		ExpectTextContaining("Признак 5").
+		ExpectNoTextContaining("Признак 6").
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.HasPrefix(s.Header, "📑 Найдено признаков: 7. Страница 1 из 2.") && len(s.Items) == 1
+		}).
+		Press(1).
+		ExpectTextContaining("Признак 7").
+		ExpectNoTextContaining("Признак 5").
+		ExpectSelect(1)
+
+	turn := conv.Command(string(CmdFind)).ExpectSelect(5)
+	turn.Press(itemByCaption(t, turn, "⚠️ Высокий риск")).
+		ExpectTextContaining("Признак 7").
+		ExpectNoTextContaining("Признак 1")
+
+	conv.Command(string(CmdFind), "витамин").ExpectTextContaining("ничего не найдено")
+}
+
+func TestPlan(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	turn := conv.Command(string(CmdPlan)).ExpectSelectWith(func(s content.Select) bool {
+		return strings.Contains(s.Header, "план действий пока пуст") && len(s.Items) == 2
+	})
+	turn = turn.Press(1).
+		ExpectTextContaining("Добавлен 1 пункт из анализа WN0000T").
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.Contains(s.Header, "выполнено 0 из 1") && len(s.Items) == 2
+		})
+
+	turn.Press(itemByCaption(t, turn, "⬜ Не пить кофе после 14:00")).
+		ExpectTextContaining("✅ Выполнено: Не пить кофе после 14:00").
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.Contains(s.Header, "выполнено 1 из 1")
+		})
+
+	// Adding the same codelab again does not duplicate tasks.
+	turn = conv.Command(string(CmdPlan)).ExpectSelect(2)
+	turn.Press(itemByCaption(t, turn, "➕ Добавить из анализа")).ExpectSelect(2).Press(1).
+		ExpectTextContaining("уже есть в плане")
+
+	// The assistant knows which recommendations are already done.
+	completer.Expect(
+		servertest.PromptContains("уже выполнил:\n- Не пить кофе после 14:00"),
+		servertest.LastUserMessage("Можно ли мне пить кофе?"),
+	).Reply("Отлично, продолжайте.")
+
+	conv.Send("Можно ли мне пить кофе?").ExpectSelect(2).Press(1).ExpectTextContaining("Отлично, продолжайте.")
+
+	// The plan belongs to the user, so it is shared by all their chats.
+	conv.ChatID = 2
+	conv.Command(string(CmdStart)).ExpectTextContaining("Добро пожаловать")
+	conv.Command(string(CmdPlan)).ExpectSelectWith(func(s content.Select) bool {
+		return strings.Contains(s.Header, "выполнено 1 из 1")
+	})
+}
+
+func TestNotifications(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	conv.Transport = server.TransportTelegram
+	authorize(t, conv, fake)
+
+	notify := func(kind chat.JobKind) *servertest.Turn {
+		t.Helper()
+		return conv.Serve(chat.MsgU(content.Notification{Kind: string(kind)}))
+	}
+
+	// Nothing to remind about without a plan.
+	if msgs := notify(chat.JobReminder).Messages(); len(msgs) != 0 {
+		t.Fatalf("reminder without plan sent %v", msgs)
+	}
+
+	// Building a plan schedules daily reminders.
+	conv.Command(string(CmdPlan)).ExpectSelect(2).Press(1).ExpectTextContaining("Добавлен 1 пункт")
+
+	jobs, err := conv.Storage.GetJobs(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Kind != chat.JobReminder || jobs[0].Spec != ReminderSpec ||
+		jobs[0].Transport != string(server.TransportTelegram) {
+		t.Fatalf("scheduled jobs = %+v, want a reminder", jobs)
+	}
+
+	notify(chat.JobReminder).
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.Contains(s.Header, "1 невыполненный пункт") && len(s.Items) == 1
+		}).
+		Press(1).
+		ExpectTextContaining("✅ Выполнено: Не пить кофе после 14:00")
+
+	if msgs := notify(chat.JobReminder).Messages(); len(msgs) != 0 {
+		t.Fatalf("reminder for a completed plan sent %v", msgs)
+	}
+
+	// Enabling notifications again keeps the schedule of the existing job.
+	next := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+	jobs[0].Next = next
+	if err := conv.Storage.SaveJob(t.Context(), jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	turn := conv.Command(string(CmdNotifications)).ExpectSelect(5)
+	turn = turn.Press(itemByCaption(t, turn, "🔔 Напоминания о плане")).ExpectSelect(5)
+	turn.Press(itemByCaption(t, turn, "🔕 Напоминания о плане")).ExpectTextContaining("Уведомления включены")
+
+	jobs, err = conv.Storage.GetJobs(t.Context())
+	if err != nil || len(jobs) != 1 || !jobs[0].Next.Equal(next) {
+		t.Fatalf("jobs = %+v, %v, want the reminder with the same schedule", jobs, err)
+	}
+
+	notify(chat.JobTip).ExpectTextContaining("Совет недели — Метаболизм кофеина:\n\nОграничьте кофе до 1 чашки в день.")
+
+	// Opt out of tips, set quiet hours and the time zone.
+	turn = conv.Command(string(CmdNotifications)).ExpectSelect(5)
+	turn = turn.Press(itemByCaption(t, turn, "🔔 Советы недели")).
+		ExpectTextContaining("Уведомления отключены: Советы недели").
+		ExpectSelect(5)
+	itemByCaption(t, turn, "🔕 Советы недели")
+
+	turn = turn.Press(itemByCaption(t, turn, "🌙 Тихие часы: не заданы")).ExpectSelect(len(quietHoursPresets))
+	turn = turn.Press(itemByCaption(t, turn, "22:00–08:00")).
+		ExpectTextContaining("🌙 Тихие часы: 22:00–08:00.").
+		ExpectSelect(5)
+
+	// One item resets the time zone, the others are offsets from UTC-12 to UTC+14.
+	turn = turn.Press(itemByCaption(t, turn, "🌍 Часовой пояс: как на сервере")).ExpectSelect(28)
+	turn.Press(itemByCaption(t, turn, "UTC+3")).ExpectTextContaining("🌍 Часовой пояс: UTC+3.")
+
+	user, err := conv.Storage.GetUser(t.Context(), conv.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Notifications.Enabled(chat.JobTip) || user.Notifications.QuietHours != (chat.QuietHours{From: 22, To: 8}) ||
+		user.Notifications.TimeZone != "UTC+3" {
+		t.Errorf("notification settings = %+v", user.Notifications)
+	}
+
+	// Signing out removes the jobs, and notifications already due are dropped.
+	completer.Expect().Reply("Введите email и пароль.")
+	conv.Command(string(CmdExit)).ExpectTextContaining("Вы успешно вышли")
+
+	jobs, err = conv.Storage.GetJobs(t.Context())
+	if err != nil || len(jobs) != 0 {
+		t.Errorf("jobs after exit = %+v, %v, want none", jobs, err)
+	}
+
+	for _, kind := range chat.JobKinds {
+		if msgs := notify(kind).Messages(); len(msgs) != 0 {
+			t.Errorf("%s notification after exit sent %v", kind, msgs)
+		}
+	}
+}
+
+func TestWatchCodelabs(t *testing.T) {
+	fake := servertest.NewMyGenetics(testEmail, testPassword)
+	fake.AddCodelab("WN0000T", "Питание", testFeatures)
+	fake.Install(t)
+
+	conv := servertest.NewConversation(t, Start(), servertest.NewCompleter(t))
+	authorize(t, conv, fake)
+
+	// The access token has expired since the user logged in.
+	user, err := conv.Storage.GetUser(t.Context(), conv.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Tokens = fake.ExpiredTokens()
+	if err := conv.Storage.SaveUser(t.Context(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	watch := func() *servertest.Turn {
+		t.Helper()
+		return conv.Serve(chat.MsgU(content.Notification{Kind: string(chat.JobCodelab)}))
+	}
+
+	// The first check only remembers known codelabs.
+	if msgs := watch().Messages(); len(msgs) != 0 {
+		t.Fatalf("first check sent %v", msgs)
+	}
+
+	user, err = conv.Storage.GetUser(t.Context(), conv.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mygenetics.AccessToken(user.Tokens).Expires().After(time.Now()) {
+		t.Errorf("tokens are not renewed: %v", user.Tokens)
+	}
+	if len(user.Codelabs) != 1 || user.CodelabsCheckedAt.IsZero() {
+		t.Errorf("known codelabs = %v, checked at %v", user.Codelabs, user.CodelabsCheckedAt)
+	}
+
+	fake.AddCodelab("DX0000T", "Спорт", testFeatures)
+	fake.SetStatus("WN0000T", "processing", true)
+
+	turn := watch().
+		ExpectSelectWith(func(s content.Select) bool {
+			return s.Header == "🧬 Изменился статус анализа Питание (WN0000T): processing."
+		}).
+		ExpectSelectWith(func(s content.Select) bool {
+			return s.Header == "🧬 Новый анализ Спорт (DX0000T), статус: done."
+		})
+	turn.Press(itemByCaption(t, turn, "📑 Открыть отчет")).ExpectTextContaining("Метаболизм кофеина")
+
+	if msgs := watch().Messages(); len(msgs) != 0 {
+		t.Fatalf("check without changes sent %v", msgs)
+	}
+}
+
+// racingStorage changes the stored user with change right after the user is
+// read for the first time, like a request served concurrently would.
+type racingStorage struct {
+	server.DataStorage
+	change func()
+}
+
+func (s *racingStorage) GetUser(ctx context.Context, id int64) (chat.User, error) {
+	user, err := s.DataStorage.GetUser(ctx, id)
+	if change := s.change; change != nil {
+		s.change = nil
+		change()
+	}
+
+	return user, err
+}
+
+func TestWatchCodelabsKeepsSettings(t *testing.T) {
+	fake := servertest.NewMyGenetics(testEmail, testPassword)
+	fake.AddCodelab("WN0000T", "Питание", testFeatures)
+	fake.Install(t)
+
+	conv := servertest.NewConversation(t, Start(), servertest.NewCompleter(t))
+	authorize(t, conv, fake)
+
+	user, err := conv.Storage.GetUser(t.Context(), conv.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Tokens = fake.ExpiredTokens()
+	if err := conv.Storage.SaveUser(t.Context(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	// The user switches the language while the check talks to MyGenetics.
+	storage := conv.Storage
+	conv.Storage = &racingStorage{DataStorage: storage, change: func() {
+		changed := user
+		changed.Language = string(i18n.English)
+		if err := storage.SaveUser(t.Context(), changed); err != nil {
+			t.Fatal(err)
+		}
+	}}
+
+	conv.Serve(chat.MsgU(content.Notification{Kind: string(chat.JobCodelab)}))
+
+	user, err = storage.GetUser(t.Context(), conv.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Language != string(i18n.English) {
+		t.Errorf("user language = %q, the change is lost", user.Language)
+	}
+	if len(user.Codelabs) != 1 || !mygenetics.AccessToken(user.Tokens).Expires().After(time.Now()) {
+		t.Errorf("codelabs = %v, tokens = %v are not saved", user.Codelabs, user.Tokens)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	step := func(n int, question string) func(content.Select) bool {
+		return func(s content.Select) bool {
+			return strings.HasPrefix(s.Header, fmt.Sprintf("(%d/9) %s", n, question))
+		}
+	}
+
+	conv.Command(string(CmdProfile)).
+		ExpectTextContaining("Ответьте на несколько вопросов").
+		ExpectSelectWith(step(1, "Сколько вам лет?"))
+
+	// Invalid answers are explained and the question is asked again.
+	conv.Send("200").
+		ExpectTextContaining("Укажите возраст целым числом от 1 до 120").
+		ExpectSelectWith(step(1, "Сколько вам лет?"))
+	conv.Send("abc").
+		ExpectTextContaining("Укажите возраст целым числом от 1 до 120").
+		ExpectSelectWith(step(1, "Сколько вам лет?"))
+
+	turn := conv.Send("35").ExpectSelectWith(step(2, "Укажите ваш пол"))
+	turn.Press(itemByCaption(t, turn, "Женский")).ExpectSelectWith(step(3, "Какой у вас рост"))
+	conv.Send("165 см").ExpectSelectWith(step(4, "Какой у вас вес"))
+
+	turn = conv.Send("58,5").ExpectSelectWith(step(5, "Какой у вас уровень"))
+	turn = turn.Press(itemByCaption(t, turn, "⏭ Пропустить")).ExpectSelectWith(step(6, "Как вы питаетесь?"))
+
+	// Options can be typed as well.
+	turn = conv.Send("веганское").ExpectSelectWith(step(7, "Есть ли у вас аллергии"))
+	turn = conv.Send("лактоза").ExpectSelectWith(step(8, "Каких целей"))
+	turn = turn.Press(1).ExpectSelectWith(step(9, "Есть ли у вас хронические"))
+	turn = turn.Press(1).
+		ExpectTextContaining("Анкета заполнена").
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.Contains(s.Header, "- Возраст: 35\n- Пол: Женский\n- Рост, см: 165\n- Вес, кг: 58.5\n") &&
+				strings.Contains(s.Header, "- Физическая активность: не указано\n- Питание: Веганское\n- Аллергии: лактоза\n") &&
+				strings.Contains(s.Header, "- Индекс массы тела: 21.5")
+		})
+
+	turn.Press(itemByCaption(t, turn, "✏️ Возраст")).ExpectSelectWith(func(s content.Select) bool {
+		return strings.HasPrefix(s.Header, "Сколько вам лет?\n\nСейчас: 35") && len(s.Items) == 1
+	})
+	conv.Send("36").
+		ExpectTextContaining("Анкета обновлена").
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.Contains(s.Header, "- Возраст: 36\n")
+		})
+
+	// A question is not taken as the answer to a free-text question.
+	turn = conv.Command(string(CmdProfile)).ExpectSelect(10)
+	turn.Press(itemByCaption(t, turn, "✏️ Аллергии")).ExpectSelect(1)
+
+	completer.Expect(servertest.LastUserMessage("Сколько мне пить воды?")).Reply("Около двух литров.")
+	conv.Send("Сколько мне пить воды?").ExpectSelect(2).Press(1).ExpectTextContaining("Около двух литров.")
+
+	// The assistant takes the questionnaire into account.
+	completer.Expect(
+		servertest.PromptContains("Моя анкета (учитывай ее в рекомендациях):\n- Возраст: 36\n- Пол: Женский"),
+		servertest.PromptContains("- Аллергии: лактоза"),
+		servertest.LastUserMessage("Что мне есть на завтрак?"),
+	).Reply("Овсянку на растительном молоке.")
+
+	conv.Send("Что мне есть на завтрак?").ExpectTextContaining("Овсянку на растительном молоке.")
+
+	completer.Expect(
+		servertest.PromptContains("- Питание: Веганское"),
+	).Reply("Учтите веганское питание.")
+
+	conv.Command(string(CmdMyGeneticsAI)).ExpectSelect(2).Press(1).ExpectTextContaining("Учтите веганское питание.")
+
+	// A command cancels the question, so the next message goes to the chat.
+	turn = conv.Command(string(CmdProfile)).ExpectSelect(10)
+	turn.Press(itemByCaption(t, turn, "✏️ Рост, см")).ExpectSelect(1)
+	conv.Command(string(CmdPlan)).ExpectSelect(2)
+
+	completer.Expect(servertest.LastUserMessage("170")).Reply("Уточните вопрос.")
+	conv.Send("170").ExpectTextContaining("Уточните вопрос.")
+}
+
+func TestDiary(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	turn := conv.Command(string(CmdDiary)).ExpectSelectWith(func(s content.Select) bool {
+		return strings.Contains(s.Header, "нет записей за сегодня") && len(s.Items) == 3
+	})
+	turn.Press(itemByCaption(t, turn, "➕ Записать прием пищи")).ExpectTextContaining("Опишите, что вы съели")
+
+	// The meal is compared with the nutrition recommendations of the codelab.
+	completer.Expect(
+		servertest.SystemPromptContains("ведет дневник питания"),
+		servertest.PromptContains("Прием пищи: овсянка на молоке и кофе"),
+		servertest.PromptContains("- Метаболизм кофеина: Ограничьте кофе до 1 чашки в день."),
+	).Reply("```json\n" + `{"items":[` +
+		`{"name":"Овсяная каша на молоке","amount":"250 г","calories":250,"tags":["lactose"]},` +
+		`{"name":"Кофе","amount":"1 чашка","calories":5,"tags":["caffeine","unknown"]}],` +
+		`"feedback":"Это ваша единственная чашка кофе на сегодня."}` + "\n```")
+
+	conv.Send("овсянка на молоке и кофе").
+		ExpectTextContaining("• Овсяная каша на молоке (250 г, ~250 ккал)\n• Кофе (1 чашка, ~5 ккал)").
+		ExpectTextContaining("Всего за сегодня: ~255 ккал").
+		ExpectTextContaining("Это ваша единственная чашка кофе на сегодня.")
+
+	// Photos are logged as meals.
+	completer.Expect(func(msgs []chat.Message) error {
+		photo, ok := msgs[len(msgs)-1].Content.(content.Photo)
+		if !ok || string(photo.Data) != "JPEG" || !strings.HasPrefix(photo.Caption, "Прием пищи на фото.") {
+			return fmt.Errorf("last message = %v, want the photo", msgs[len(msgs)-1])
+		}
+		return nil
+	}).Reply(`{"items":[{"name":"Латте","amount":"300 мл","calories":180,"tags":["caffeine","lactose"]}]}`)
+
+	conv.Serve(chat.MsgU(content.Photo{Data: []byte("JPEG"), MIME: "image/jpeg"})).
+		ExpectTextContaining("• Латте (300 мл, ~180 ккал)")
+
+	completer.Expect(servertest.PromptContains("Прием пищи: привет")).Reply(`{"items":[]}`)
+	conv.Command(string(CmdDiary), "привет").ExpectTextContaining("Не нашел еды")
+
+	turn = conv.Command(string(CmdDiary)).ExpectSelectWith(func(s content.Select) bool {
+		return strings.Contains(s.Header, "• Латте (300 мл, ~180 ккал)") && strings.Contains(s.Header, "Всего: ~435 ккал.")
+	})
+
+	completer.Expect(
+		servertest.SystemPromptContains("дневник питания пользователя за день или за неделю"),
+		servertest.PromptContains("Мой дневник питания за последние 7 дней"),
+		servertest.PromptContains("  • Латте (300 мл, ~180 ккал)"),
+		servertest.PromptContains("- Метаболизм кофеина: Ограничьте кофе до 1 чашки в день."),
+	).Reply("🍽 Итоги: многовато кофе.")
+
+	turn.Press(itemByCaption(t, turn, "📆 Итоги недели")).
+		ExpectTextContaining("• Приемов пищи: 2 (дней с записями: 1)\n• В среднем за день: ~435 ккал\n• Продукты с лактозой: 2\n• Продукты с кофеином: 2").
+		ExpectTextContaining("многовато кофе")
+}
+
+func TestDiaryWithoutImages(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	// The embedded interface hides SupportsImages of the completer.
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), struct{ server.ChatCompleter }{completer})
+	authorize(t, conv, fake)
+
+	turn := conv.Command(string(CmdDiary)).ExpectSelectWith(func(s content.Select) bool {
+		return strings.HasSuffix(s.Header, "Опишите, что вы съели.")
+	})
+	turn.Press(itemByCaption(t, turn, "➕ Записать прием пищи")).
+		ExpectTextContaining("Опишите, что вы съели (например, «овсянка на молоке и кофе»).").
+		ExpectNoTextContaining("фото")
+
+	// Photos are not sent to the model, and the question stays open.
+	conv.Serve(chat.MsgU(content.Photo{Data: []byte("JPEG"), MIME: "image/jpeg"})).
+		ExpectTextContaining("не распознает фото")
+
+	completer.Expect(servertest.PromptContains("Прием пищи: овсянка")).
+		Reply(`{"items":[{"name":"Овсянка","amount":"250 г","calories":250}]}`)
+	conv.Send("овсянка").ExpectTextContaining("• Овсянка (250 г, ~250 ккал)")
+}
+
+func TestJournal(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	turn := conv.Command(string(CmdJournal)).ExpectSelectWith(func(s content.Select) bool {
+		return strings.Contains(s.Header, "пока нет записей") && len(s.Items) == 1
+	})
+	turn = turn.Press(1).ExpectSelectWith(func(s content.Select) bool {
+		return strings.HasPrefix(s.Header, "😴 Сколько вы спали") && len(s.Items) == 8
+	})
+	turn = turn.Press(itemByCaption(t, turn, "6 ч")).ExpectSelect(6)
+	turn = turn.Press(itemByCaption(t, turn, "😕 2")).ExpectSelect(6)
+	turn.Press(itemByCaption(t, turn, "⏭ Пропустить")).ExpectSelectWith(func(s content.Select) bool {
+		return strings.HasPrefix(s.Header, "⚖️ Напишите ваш вес") && len(s.Items) == 1
+	})
+
+	// Invalid weight is asked again; a question goes to the assistant.
+	conv.Send("много").ExpectTextContaining("Напишите вес числом от 20 до 350 кг")
+	conv.Send("500").ExpectTextContaining("Напишите вес числом от 20 до 350 кг")
+
+	completer.Expect(servertest.LastUserMessage("Сколько мне весить?")).Reply("Уточните вопрос.")
+	conv.Send("Сколько мне весить?").ExpectSelect(2).Press(1).ExpectTextContaining("Уточните вопрос.")
+
+	turn = conv.Command(string(CmdJournal)).ExpectSelect(1).Press(1).ExpectSelect(8)
+	turn = turn.Press(itemByCaption(t, turn, "⏭ Пропустить")).ExpectSelect(6)
+	turn = turn.Press(itemByCaption(t, turn, "⏭ Пропустить")).ExpectSelect(6)
+	turn.Press(itemByCaption(t, turn, "⏭ Пропустить")).ExpectSelect(1)
+
+	// A valid weight also updates the questionnaire.
+	turn = conv.Send("72,5 кг").ExpectSelectWith(func(s content.Select) bool {
+		return strings.HasPrefix(s.Header, "🤒 Отметьте симптомы") && len(s.Items) == len(chat.Symptoms)+1
+	})
+
+	// Symptoms already marked today are not offered again.
+	turn = turn.Press(itemByCaption(t, turn, "Усталость")).ExpectSelect(len(chat.Symptoms))
+	turn.Press(itemByCaption(t, turn, "✅ Готово")).
+		ExpectTextContaining("Самочувствие записано").
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.Contains(s.Header, "• Сон: 6 ч\n• Энергия: 2/5\n• Вес: 72.5 кг\n• Симптомы: Усталость — 1")
+		})
+
+	conv.Command(string(CmdProfile)).ExpectSelectWith(func(s content.Select) bool {
+		return strings.Contains(s.Header, "- Вес, кг: 72.5\n")
+	})
+
+	// The assistant sees the trends next to the genetic data.
+	completer.Expect(
+		servertest.PromptContains("Метаболизм кофеина"),
+		servertest.PromptContains("Мой журнал самочувствия за последние 7 дней"),
+		servertest.PromptContains("• Энергия: 2/5"),
+		servertest.PromptContains("• Симптомы: Усталость — 1"),
+		servertest.LastUserMessage("Почему я устаю после кофе?"),
+	).Reply("Кофеин у вас метаболизируется медленно.")
+
+	conv.Send("Почему я устаю после кофе?").ExpectTextContaining("метаболизируется медленно")
+}
+
+func TestFeedback(t *testing.T) {
+	fake := newMyGenetics(t)
+
+	completer := servertest.NewCompleter(t)
+	conv := servertest.NewConversation(t, Start(), completer)
+	authorize(t, conv, fake)
+
+	completer.Expect(servertest.LastUserMessage("Можно ли мне кофе?")).Reply("Не больше чашки в день.")
+
+	// The rating buttons are attached to the answer.
+	turn := conv.Send("Можно ли мне кофе?").ExpectSelect(2).Press(1).
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.HasSuffix(s.Header, "Не больше чашки в день.") && len(s.Items) == 3
+		})
+
+	turn.Press(itemByCaption(t, turn, "👍")).ExpectTextContaining("Спасибо за оценку")
+	turn.Press(itemByCaption(t, turn, "👎")).ExpectTextContaining("уже оценили")
+
+	// Inaccurate codelab interpretations can be explained.
+	completer.Expect(servertest.PromptContains("Метаболизм кофеина")).Reply("Кофеин выводится быстро.")
+	turn = conv.Command(string(CmdMyGeneticsAI)).ExpectSelect(2).Press(1).
+		ExpectSelectWith(func(s content.Select) bool {
+			return strings.Contains(s.Header, "Кофеин выводится быстро.")
+		})
+
+	turn.Press(itemByCaption(t, turn, "⚠️ Неточно")).ExpectTextContaining("Что в ответе неточно?")
+	conv.Send("У меня медленный метаболизм кофеина.").ExpectTextContaining("пояснение сохранено")
+
+	ratings, err := conv.Storage.GetRatings(t.Context())
+	if err != nil {
+		t.Fatalf("GetRatings: %v", err)
+	}
+
+	history, err := conv.Storage.GetChatHistory(t.Context(), conv.ChatID, 0)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("GetChatHistory = %v, %v, want the question and the answer", history, err)
+	}
+
+	byPrompt := make(map[string]chat.Rating)
+	for _, rating := range ratings {
+		byPrompt[rating.Prompt] = rating
+	}
+
+	if rating := byPrompt[myGeneticsChatPrompt]; rating.Value != chat.RatingUp || rating.Model != completer.ModelName() ||
+		rating.QuestionID != history[0].ID || rating.ID != history[1].ID || rating.RatedAt.IsZero() {
+		t.Errorf("chat rating = %+v, want up", rating)
+	}
+	if rating := byPrompt[myGeneticsCodelabPrompt]; rating.Value != chat.RatingInaccurate ||
+		rating.Comment != "У меня медленный метаболизм кофеина." {
+		t.Errorf("codelab rating = %+v, want inaccurate with comment", rating)
+	}
+
+	// A question instead of the explanation goes to the assistant.
+	completer.Expect(servertest.LastUserMessage("Что такое кофеин?")).Reply("Это алкалоид.")
+	turn = conv.Send("Что такое кофеин?").ExpectSelectWith(func(s content.Select) bool { return strings.HasSuffix(s.Header, "Это алкалоид.") })
+	turn.Press(itemByCaption(t, turn, "⚠️ Неточно")).ExpectTextContaining("Что в ответе неточно?")
+
+	completer.Expect(servertest.LastUserMessage("А чай можно?")).Reply("Зеленый чай тоже содержит кофеин.")
+	conv.Send("А чай можно?").ExpectTextContaining("Зеленый чай тоже содержит кофеин.")
+
+	ratings, err = conv.Storage.GetRatings(t.Context())
+	if err != nil {
+		t.Fatalf("GetRatings: %v", err)
+	}
+	for _, rating := range ratings {
+		if rating.Comment == "А чай можно?" {
+			t.Errorf("rating = %+v, the question is saved as a comment", rating)
+		}
+	}
+}
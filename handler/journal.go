@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// journalPeriod - период, за который считаются тренды самочувствия. Он
+// сравнивается с предыдущим периодом той же длины.
+const journalPeriod = 7 * 24 * time.Hour
+
+// journalSteps перечисляет шаги отметки самочувствия по порядку.
+var journalSteps = append(slices.Clone(chat.Metrics), chat.MetricSymptom)
+
+// journalScale - подписи оценок энергии и настроения от 1 до 5.
+var journalScale = []string{"😫", "😕", "😐", "🙂", "😄"}
+
+// journal показывает тренды самочувствия и предлагает отметить его.
+func journal() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			j, err := r.Storage.GetJournal(ctx, r.From.ID)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.journal.get", err))
+				r.Log.Printf("failed to read journal (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			header := tr(r, "journal.empty")
+			if trends := journalTrends(r, j); trends != "" {
+				header = tr(r, "journal.trends", trends)
+			}
+
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: header,
+				Items: []content.SelectItem{
+					{Caption: tr(r, "journal.start"), Data: PrefixJournal + "start"},
+				},
+			}))
+		},
+	)
+}
+
+// journalAction обрабатывает кнопки журнала самочувствия. Данные кнопки
+// имеют вид "start" или "<показатель>:<значение>"; пустое значение
+// пропускает шаг.
+func journalAction(data SelectItemData) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if data == "start" {
+				journalAsk(journalSteps[0]).Serve(ctx, w, r)
+				return
+			}
+
+			name, value, _ := strings.Cut(data, ":")
+			metric := chat.Metric(name)
+			if !slices.Contains(journalSteps, metric) {
+				w.WriteResponse(msg(r, "error.unknown_command"))
+				return
+			}
+
+			if value == "" {
+				journalNext(metric).Serve(ctx, w, r)
+				return
+			}
+
+			m := chat.Measurement{Time: chat.Now(), Metric: metric}
+			if metric == chat.MetricSymptom {
+				if !slices.Contains(chat.Symptoms, value) {
+					w.WriteResponse(msg(r, "error.unknown_command"))
+					return
+				}
+				m.Symptom = value
+			} else {
+				number, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					w.WriteResponse(msg(r, "error.unknown_command"))
+					return
+				}
+				m.Value = number
+			}
+
+			if err := journalSave(ctx, r, m); err != nil {
+				w.WriteResponse(msg(r, "error.journal.save", err))
+				r.Log.Printf("failed to save journal (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			// Симптомов может быть несколько, поэтому выбор повторяется,
+			// пока пользователь не нажмет "Готово".
+			if metric == chat.MetricSymptom {
+				journalAsk(chat.MetricSymptom).Serve(ctx, w, r)
+				return
+			}
+
+			journalNext(metric).Serve(ctx, w, r)
+		},
+	)
+}
+
+// journalAsk задает вопрос о показателе самочувствия. Вес вводится текстом,
+// остальные показатели выбираются кнопками.
+func journalAsk(metric chat.Metric) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			data := PrefixJournal + string(metric) + ":"
+			msgContent := content.Select{Header: tr(r, "journal.question."+string(metric))}
+
+			switch metric {
+			case chat.MetricSleep:
+				msgContent.Columns = 4
+				for hours := 4; hours <= 10; hours++ {
+					msgContent.Items = append(msgContent.Items, content.SelectItem{
+						Caption: tr(r, "journal.value.sleep", strconv.Itoa(hours)),
+						Data:    data + strconv.Itoa(hours),
+					})
+				}
+
+			case chat.MetricEnergy, chat.MetricMood:
+				msgContent.Columns = len(journalScale)
+				for i, emoji := range journalScale {
+					msgContent.Items = append(msgContent.Items, content.SelectItem{
+						Caption: fmt.Sprintf("%s %d", emoji, i+1),
+						Data:    fmt.Sprintf("%s%d", data, i+1),
+					})
+				}
+
+			case chat.MetricWeight:
+				awaitInput(ctx, r, PrefixJournal+string(metric))
+				if weight := r.From.Profile.Weight; weight > 0 {
+					msgContent.Header += "\n\n" + tr(r, "journal.current", journalValue(r, metric, weight))
+				}
+
+			case chat.MetricSymptom:
+				j, err := r.Storage.GetJournal(ctx, r.From.ID)
+				if err != nil {
+					w.WriteResponse(msg(r, "error.journal.get", err))
+					r.Log.Printf("failed to read journal (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				// Симптомы, уже отмеченные сегодня, не предлагаются снова.
+				now := chat.Now()
+				today := j.SymptomCounts(startOfDay(now), now.Add(time.Nanosecond))
+
+				msgContent.Columns = 2
+				for _, symptom := range chat.Symptoms {
+					if today[symptom] == 0 {
+						msgContent.Items = append(msgContent.Items, content.SelectItem{
+							Caption: tr(r, "journal.symptom."+symptom),
+							Data:    data + symptom,
+						})
+					}
+				}
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: tr(r, "journal.done"),
+					Data:    data,
+				})
+
+				w.WriteResponse(chat.MsgA(msgContent))
+				return
+			}
+
+			msgContent.Items = append(msgContent.Items, content.SelectItem{
+				Caption: tr(r, "journal.skip"),
+				Data:    data,
+			})
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// journalNext задает вопрос о следующем показателе, а после последнего
+// завершает отметку самочувствия и показывает тренды.
+func journalNext(metric chat.Metric) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if i := slices.Index(journalSteps, metric); i >= 0 && i+1 < len(journalSteps) {
+				journalAsk(journalSteps[i+1]).Serve(ctx, w, r)
+				return
+			}
+
+			w.WriteResponse(msg(r, "journal.saved"))
+			journal().Serve(ctx, w, r)
+		},
+	)
+}
+
+// journalWeight обрабатывает текстовое сообщение как вес. Вес также
+// обновляется в анкете пользователя. Неверный вес сопровождается подсказкой,
+// и вопрос задается снова; вопрос пользователя сюда не попадает: его
+// передает ИИ myGenetics.
+func journalWeight(text string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			weight, err := chat.ParseWeight(text)
+			if errors.Is(err, chat.ErrInvalidProfileValue) {
+				w.WriteResponse(msg(r, "journal.invalid.weight"))
+				journalAsk(chat.MetricWeight).Serve(ctx, w, r)
+				return
+			}
+
+			clearInput(ctx, r)
+
+			m := chat.Measurement{Time: chat.Now(), Metric: chat.MetricWeight, Value: weight}
+			if err := journalSave(ctx, r, m); err != nil {
+				w.WriteResponse(msg(r, "error.journal.save", err))
+				r.Log.Printf("failed to save journal (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			err = updateUser(ctx, r, func(user *chat.User) {
+				user.Profile.Weight = weight
+			})
+			if err != nil {
+				r.Log.Printf("failed to save user (chatID: %d): %v", r.ChatID, err)
+			}
+
+			journalNext(chat.MetricWeight).Serve(ctx, w, r)
+		},
+	)
+}
+
+// journalSave добавляет запись в журнал самочувствия пользователя.
+func journalSave(ctx context.Context, r *server.Request, m chat.Measurement) error {
+	j, err := r.Storage.GetJournal(ctx, r.From.ID)
+	if err != nil {
+		return err
+	}
+
+	return r.Storage.SaveJournal(ctx, r.From.ID, j.Add(m))
+}
+
+// journalTrends описывает средние значения показателей за последний период
+// в сравнении с предыдущим и отмеченные симптомы, по одному на строку.
+// Пустая строка, если за период нет записей.
+func journalTrends(r *server.Request, j chat.Journal) string {
+	now := chat.Now()
+
+	var lines []string
+	for _, metric := range chat.Metrics {
+		t := j.Trend(metric, now, journalPeriod)
+		if t.Count == 0 {
+			continue
+		}
+
+		line := tr(r, "journal.trend", tr(r, "journal.metric."+string(metric)), journalValue(r, metric, t.Average))
+		if t.HasPrevious {
+			line += " " + tr(r, "journal.trend.previous", journalArrow(t.Direction()), journalValue(r, metric, t.Previous))
+		}
+		lines = append(lines, line)
+	}
+
+	counts := j.SymptomCounts(now.Add(-journalPeriod), now.Add(time.Nanosecond))
+	if len(counts) > 0 {
+		symptoms := slices.Clone(chat.Symptoms)
+		slices.SortStableFunc(symptoms, func(a, b string) int {
+			return cmp.Compare(counts[b], counts[a])
+		})
+
+		var items []string
+		for _, symptom := range symptoms {
+			if counts[symptom] > 0 {
+				items = append(items, tr(r, "journal.symptom.count", tr(r, "journal.symptom."+symptom), counts[symptom]))
+			}
+		}
+		lines = append(lines, tr(r, "journal.symptoms", strings.Join(items, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// journalContext описывает для ИИ тренды самочувствия пользователя, пустая
+// строка, если за последний период нет записей.
+func journalContext(ctx context.Context, r *server.Request) string {
+	j, err := r.Storage.GetJournal(ctx, r.From.ID)
+	if err != nil {
+		r.Log.Printf("failed to read journal (chatID: %d): %v", r.ChatID, err)
+		return ""
+	}
+
+	trends := journalTrends(r, j)
+	if trends == "" {
+		return ""
+	}
+
+	return tr(r, "journal.context", trends)
+}
+
+// journalValue возвращает значение показателя с единицами измерения.
+func journalValue(r *server.Request, metric chat.Metric, value float64) string {
+	return tr(r, "journal.value."+string(metric), strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// journalArrow обозначает направление изменения показателя.
+func journalArrow(direction int) string {
+	switch {
+	case direction > 0:
+		return "↑"
+	case direction < 0:
+		return "↓"
+	default:
+		return "→"
+	}
+}
+
+// startOfDay возвращает начало дня, которому принадлежит t.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
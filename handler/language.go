@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/i18n"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// lang возвращает язык пользователя: выбранный командой /language,
+// иначе язык клиента, иначе язык по умолчанию.
+func lang(r *server.Request) i18n.Lang {
+	return i18n.Choose(r.From.Language, r.From.LanguageCode)
+}
+
+// promptLang возвращает суффикс языка для промптов. Промпты на языке
+// по умолчанию хранятся без суффикса.
+func promptLang(r *server.Request) string {
+	if l := lang(r); l != i18n.Default {
+		return string(l)
+	}
+
+	return ""
+}
+
+// tr возвращает сообщение на языке пользователя.
+func tr(r *server.Request, id string, args ...any) string {
+	return i18n.T(lang(r), id, args...)
+}
+
+// msg возвращает сообщение ассистента на языке пользователя.
+func msg(r *server.Request, id string, args ...any) chat.Message {
+	return chat.MsgA(tr(r, id, args...))
+}
+
+// language переключает язык пользователя. Без аргумента показывает
+// список доступных языков.
+func language(code string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			code = strings.TrimSpace(code)
+
+			if code == "" {
+				msgContent := content.Select{Header: tr(r, "language.select")}
+				for _, l := range i18n.Languages() {
+					msgContent.Items = append(msgContent.Items, content.SelectItem{
+						Caption: l.Name(),
+						Data:    PrefixLanguage + string(l),
+					})
+				}
+
+				w.WriteResponse(chat.MsgA(msgContent))
+				return
+			}
+
+			l, ok := i18n.Parse(code)
+			if !ok {
+				codes := make([]string, 0, len(i18n.Languages()))
+				for _, l := range i18n.Languages() {
+					codes = append(codes, string(l))
+				}
+
+				w.WriteResponse(msg(r, "language.unsupported", code, strings.Join(codes, ", ")))
+				return
+			}
+
+			user := r.From
+			user.Language = string(l)
+
+			if err := r.Storage.SaveUser(ctx, user); err != nil {
+				w.WriteResponse(msg(r, "error.settings.save", err))
+				r.Log.Printf("failed to save user (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			r.From = user
+
+			// Обновляем список команд на новом языке.
+			w.WriteResponse(msg(r, "language.set"))
+			if user.State == chat.UserStateUnauthorized {
+				w.WriteResponse(unauthorizedCommands(l))
+			} else {
+				w.WriteResponse(commandsMessage(l))
+			}
+		},
+	)
+}
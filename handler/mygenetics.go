@@ -3,7 +3,9 @@ package handler
 import (
 	"context"
 	_ "embed"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/muzykantov/health-gpt/chat"
 	"github.com/muzykantov/health-gpt/chat/content"
@@ -16,38 +18,103 @@ type (
 )
 
 const (
-	PrefixCodelab SelectItemPrefix = "codelab:"
-	PrefixAI      SelectItemPrefix = "ai:"
-	PrefixAIChat  SelectItemPrefix = "ai_chat:"
+	PrefixCodelab      SelectItemPrefix = "codelab:"
+	PrefixAI           SelectItemPrefix = "ai:"
+	PrefixAIChat       SelectItemPrefix = "ai_chat:"
+	PrefixLanguage     SelectItemPrefix = "lang:"
+	PrefixFind         SelectItemPrefix = "find:"
+	PrefixFindQuery    SelectItemPrefix = "find_q:"
+	PrefixPlanAdd      SelectItemPrefix = "plan_add:"
+	PrefixPlanToggle   SelectItemPrefix = "plan_do:"
+	PrefixCompare      SelectItemPrefix = "compare:"
+	PrefixCompareWith  SelectItemPrefix = "compare_with:"
+	PrefixCompareAI    SelectItemPrefix = "compare_ai:"
+	PrefixExport       SelectItemPrefix = "export:"
+	PrefixExportFormat SelectItemPrefix = "export_as:"
+	PrefixNotifyToggle SelectItemPrefix = "notify:"
+	PrefixNotifyQuiet  SelectItemPrefix = "notify_quiet:"
+	PrefixNotifyZone   SelectItemPrefix = "notify_tz:"
+	PrefixProfile      SelectItemPrefix = "profile:"
+	PrefixDiary        SelectItemPrefix = "diary:"
+	PrefixJournal      SelectItemPrefix = "journal:"
+	PrefixFeedback     SelectItemPrefix = "rate:"
 )
 
 // myGenetics создает основной обработчик для работы с генетическими анализами.
 // Обрабатывает все типы запросов (команды, выбор анализа, текстовые сообщения)
-// и маршрутизирует их к соответствующим обработчикам. Поддерживает историю чата.
+// и маршрутизирует их к соответствующим обработчикам. Новый чат (этап онбординга)
+// начинается с приветствия.
 func myGenetics() server.Handler {
 	return server.HandlerFunc(
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
 			if r.From.State == chat.UserStateUnauthorized {
-				w.WriteResponse(chat.MsgA("⛔ Пользователь не авторизован."))
+				w.WriteResponse(msg(r, "error.unauthorized"))
 				return
 			}
 
-			history, err := r.Storage.GetChatHistory(ctx, r.ChatID, 1)
+			state, err := chatState(ctx, r)
 			if err != nil {
-				w.WriteResponse(chat.MsgAf("⚠️ Ошибка получения истории чата: %v", err))
+				w.WriteResponse(msg(r, "error.chat_state.get", err))
 				return
 			}
 
-			if len(history) == 0 {
+			switch state.Stage {
+			case chat.StageChatting, chat.StageCodelabSelected:
+			default:
 				greetings().Serve(ctx, w, r)
 				return
 			}
 
 			switch msgContent := r.Incoming.Content.(type) {
 			case string:
-				myGeneticsChat("").Serve(ctx, w, r)
+				// Ответ на вопрос бота, например анкеты, дневника питания, журнала
+				// самочувствия или пояснение к оценке ответа. Вопрос пользователя
+				// вместо ответа отменяет вопрос бота и передается ИИ.
+				question, _ := state.PendingInput()
+				if question != "" && isQuestion(msgContent) {
+					clearInput(ctx, r)
+					question = ""
+				}
+
+				switch {
+				case strings.HasPrefix(question, PrefixProfile):
+					profileText(strings.TrimPrefix(question, PrefixProfile), msgContent).Serve(ctx, w, r)
+
+				case strings.HasPrefix(question, PrefixDiary):
+					clearInput(ctx, r)
+					diaryAdd(msgContent, nil).Serve(ctx, w, r)
+
+				case strings.HasPrefix(question, PrefixJournal):
+					journalWeight(msgContent).Serve(ctx, w, r)
+
+				case strings.HasPrefix(question, PrefixFeedback):
+					feedbackComment(strings.TrimPrefix(question, PrefixFeedback), msgContent).Serve(ctx, w, r)
+
+				default:
+					myGeneticsChat("").Serve(ctx, w, r)
+				}
+
+			case content.Photo:
+				// Фотографии еды записываются в дневник питания, если модель
+				// понимает изображения. Иначе вопрос бота остается в силе.
+				if !diaryPhotos(r) {
+					w.WriteResponse(msg(r, "diary.photo_unsupported"))
+					return
+				}
+
+				clearInput(ctx, r)
+				diaryAdd("", &msgContent).Serve(ctx, w, r)
 
 			case content.SelectItem:
+				if strings.HasPrefix(msgContent.Data, PrefixProfile) {
+					profileAction(strings.TrimPrefix(msgContent.Data, PrefixProfile)).Serve(ctx, w, r)
+					return
+				}
+
+				// Вопрос бота остается без ответа, если пользователь перешел
+				// к другому действию.
+				clearInput(ctx, r)
+
 				switch {
 				case strings.HasPrefix(msgContent.Data, PrefixAI):
 					myGeneticsCodelab(msgContent.Data).Serve(ctx, w, r)
@@ -57,15 +124,122 @@ func myGenetics() server.Handler {
 
 				case strings.HasPrefix(msgContent.Data, PrefixAIChat):
 					myGeneticsChat(msgContent.Data).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixFindQuery):
+					findQuery(strings.TrimPrefix(msgContent.Data, PrefixFindQuery)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixFind):
+					findResults(msgContent.Data).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixPlanAdd):
+					planAdd(strings.TrimPrefix(msgContent.Data, PrefixPlanAdd)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixPlanToggle):
+					planToggle(strings.TrimPrefix(msgContent.Data, PrefixPlanToggle)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixCompareWith):
+					compareResult(strings.TrimPrefix(msgContent.Data, PrefixCompareWith), false).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixCompareAI):
+					compareResult(strings.TrimPrefix(msgContent.Data, PrefixCompareAI), true).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixCompare):
+					compareSecond(strings.TrimPrefix(msgContent.Data, PrefixCompare)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixExportFormat):
+					exportDocument(msgContent.Data).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixExport):
+					exportFormats(strings.TrimPrefix(msgContent.Data, PrefixExport)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixNotifyToggle):
+					kind := chat.JobKind(strings.TrimPrefix(msgContent.Data, PrefixNotifyToggle))
+					if slices.Contains(chat.JobKinds, kind) {
+						notificationsToggle(kind).Serve(ctx, w, r)
+					}
+
+				case strings.HasPrefix(msgContent.Data, PrefixNotifyQuiet):
+					notificationsQuiet(strings.TrimPrefix(msgContent.Data, PrefixNotifyQuiet)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixNotifyZone):
+					notificationsZone(strings.TrimPrefix(msgContent.Data, PrefixNotifyZone)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixDiary):
+					diaryAction(strings.TrimPrefix(msgContent.Data, PrefixDiary)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixJournal):
+					journalAction(strings.TrimPrefix(msgContent.Data, PrefixJournal)).Serve(ctx, w, r)
+
+				case strings.HasPrefix(msgContent.Data, PrefixFeedback):
+					feedbackAction(strings.TrimPrefix(msgContent.Data, PrefixFeedback)).Serve(ctx, w, r)
+
 				}
 
 			case content.Command:
+				clearInput(ctx, r)
 				commands(Command(msgContent.Name)).Serve(ctx, w, r)
 
 			default:
-				w.WriteResponse(chat.MsgA("⛔ Неизвестная команда. " +
-					"Пожалуйста, выберите действие из предложенного списка."))
+				w.WriteResponse(msg(r, "error.unknown_command"))
 			}
 		},
 	)
 }
+
+// chatState читает состояние чата. Чаты, начатые до появления chat.State,
+// не имеют сохраненного состояния; если в них есть история, они переводятся
+// в свободное общение, чтобы приветствие не стерло историю.
+func chatState(ctx context.Context, r *server.Request) (chat.State, error) {
+	state, err := r.Storage.GetChatState(ctx, r.ChatID)
+	if err != nil || state.Stage != chat.StageOnboarding || !state.UpdatedAt.IsZero() {
+		return state, err
+	}
+
+	history, err := r.Storage.GetChatHistory(ctx, r.ChatID, 1)
+	if err != nil || len(history) == 0 {
+		return state, err
+	}
+
+	if state, err = state.StartChatting(); err != nil {
+		return state, err
+	}
+
+	if err := r.Storage.SaveChatState(ctx, r.ChatID, state); err != nil {
+		r.Log.Printf("failed to save chat state (chatID: %d): %v", r.ChatID, err)
+	}
+
+	return state, nil
+}
+
+// inputTTL - время, в течение которого ожидается ответ на вопрос бота.
+const inputTTL = 10 * time.Minute
+
+// awaitInput запоминает в состоянии чата вопрос, ответ на который ожидается
+// следующим текстовым сообщением. Вопрос начинается с префикса обработчика
+// ответа.
+func awaitInput(ctx context.Context, r *server.Request, question string) {
+	state, err := r.Storage.GetChatState(ctx, r.ChatID)
+	if err == nil {
+		err = r.Storage.SaveChatState(ctx, r.ChatID, state.AwaitInput(question, inputTTL))
+	}
+	if err != nil {
+		r.Log.Printf("failed to save chat state (chatID: %d): %v", r.ChatID, err)
+	}
+}
+
+// clearInput отменяет ожидание ответа на вопрос бота.
+func clearInput(ctx context.Context, r *server.Request) {
+	state, err := r.Storage.GetChatState(ctx, r.ChatID)
+	if err == nil && state.Input != "" {
+		err = r.Storage.SaveChatState(ctx, r.ChatID, state.ClearInput())
+	}
+	if err != nil {
+		r.Log.Printf("failed to save chat state (chatID: %d): %v", r.ChatID, err)
+	}
+}
+
+// isQuestion проверяет, похоже ли сообщение на вопрос ассистенту, а не на
+// ответ на вопрос бота.
+func isQuestion(text string) bool {
+	return strings.HasSuffix(strings.TrimSpace(text), "?")
+}
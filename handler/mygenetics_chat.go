@@ -14,10 +14,7 @@ import (
 	"github.com/muzykantov/health-gpt/server"
 )
 
-const (
-	myGeneticsChatPrompt = "chat"
-	codelabCodeCacheKey  = "chat_codelab_code:%d"
-)
+const myGeneticsChatPrompt = "chat"
 
 // myGeneticsChat создает обработчик для чата с ИИ по вопросам генетических анализов.
 // Обрабатывает текстовые сообщения пользователя и предоставляет ответы на основе
@@ -26,16 +23,21 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 	return server.HandlerFunc(
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
 			var (
-				codelabCodeCacheKey = fmt.Sprintf(codelabCodeCacheKey, r.ChatID)
-				codelabCode         string
-				msgText             string
-				sendCode            bool
+				codelabCode string
+				msgText     string
+				sendCode    bool
 			)
 
 			access := mygenetics.AccessToken(r.From.Tokens)
 			if access == "" {
-				w.WriteResponse(chat.MsgA("⚠️ Для доступа к анализам необходимо авторизоваться. " +
-					"Пожалуйста, введите свой email и пароль."))
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			state, err := r.Storage.GetChatState(ctx, r.ChatID)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.chat_state.get", err))
+				r.Log.Printf("failed to read chat state (chatID: %d): %v", r.ChatID, err)
 				return
 			}
 
@@ -45,30 +47,28 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 
 				msgText, ok = r.Incoming.Content.(string)
 				if !ok {
-					w.WriteResponse(chat.MsgA("⛔ Пожалуйста, отправьте текстовое сообщение."))
+					w.WriteResponse(msg(r, "chat.text_required"))
 					r.Log.Printf("invalid message content type (chatID: %d): expected string, got %T",
 						r.ChatID, r.Incoming.Content)
 					return
 				}
 
-				if cachedCodelab, ok := r.Cache.Get(codelabCodeCacheKey); ok {
-					codelabCode = cachedCodelab.(string)
+				if selected, ok := state.SelectedCodelab(); ok {
+					codelabCode = selected
 					sendCode = true
 					break
 				}
 
 				codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
 				if err != nil {
-					w.WriteResponse(chat.MsgA("⚠️ Не удалось загрузить анализы. " +
-						"Пожалуйста, попробуйте позже или обратитесь в поддержку."))
+					w.WriteResponse(msg(r, "chat.fetch_failed"))
 					r.Log.Printf("failed to fetch codelabs (chatID: %d): %v", r.ChatID, err)
 					return
 				}
 
 				switch len(codelabs) {
 				case 0:
-					w.WriteResponse(chat.MsgA("⚠️ У вас пока нет доступных анализов. " +
-						"Пожалуйста, загрузите анализы, чтобы начать общение."))
+					w.WriteResponse(msg(r, "chat.no_codelabs"))
 					return
 
 				case 1:
@@ -77,9 +77,7 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 
 				default:
 					msgContent := content.Select{
-						Header: "🧬 У вас несколько анализов. Пожалуйста, выберите один. " +
-							"Ассистент будет использовать его, пока выбор не сбросится " +
-							"(например, автоматически или командой /clear).",
+						Header: tr(r, "chat.select_codelab"),
 					}
 					for _, codelab := range codelabs {
 						msgContent.Items = append(msgContent.Items, content.SelectItem{
@@ -102,42 +100,50 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 			case strings.HasPrefix(data, PrefixAIChat):
 				parts := strings.SplitN(strings.TrimPrefix(data, PrefixAIChat), ":", 2)
 				if len(parts) != 2 {
-					w.WriteResponse(chat.MsgA("⛔ Неверный формат анализа. Пожалуйста, выберите ответ из списка."))
+					w.WriteResponse(msg(r, "chat.invalid_codelab"))
 					r.Log.Printf("invalid message parts (chatID: %d): %v",
 						r.ChatID, parts)
 					return
 				}
 
-				msg, ok := r.Cache.Get(PrefixAIChat + parts[1])
+				cached, ok := r.Cache.Get(PrefixAIChat + parts[1])
 				if !ok {
-					w.WriteResponse(chat.MsgA("⛔ Сообщение устарело."))
+					w.WriteResponse(msg(r, "chat.expired"))
 					r.Log.Printf("invalid message cache id (chatID: %d): %s",
 						r.ChatID, parts[1])
 					return
 				}
 
 				codelabCode = parts[0]
-				msgText = msg.(string)
+				msgText = cached.(string)
 				sendCode = true
 
-				r.Cache.Add(codelabCodeCacheKey, codelabCode)
-				if err := r.Storage.SaveChatHistory(ctx, r.ChatID, []chat.Message{
-					chat.MsgU(DefaultFirstMessage),
-				}); err != nil {
-					w.WriteResponse(chat.MsgAf("⚠️ Ошибка сохранения истории чата: %v", err))
+				if state, err = state.SelectCodelab(codelabCode); err != nil {
+					w.WriteResponse(msg(r, "error.chat_state.change", err))
+					r.Log.Printf("failed to select codelab (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				if err := r.Storage.SaveChatState(ctx, r.ChatID, state); err != nil {
+					w.WriteResponse(msg(r, "error.chat_state.save", err))
+					r.Log.Printf("failed to write chat state (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+
+				if err := r.Storage.SaveChatHistory(ctx, r.ChatID, []chat.Message{}); err != nil {
+					w.WriteResponse(msg(r, "error.history.save", err))
 					r.Log.Printf("failed to write chat history (chatID: %d): %v", r.ChatID, err)
 					return
 				}
 
 			default:
-				w.WriteResponse(chat.MsgA("⛔ Неизвестная команда. " +
-					"Пожалуйста, выберите действие из предложенного списка."))
+				w.WriteResponse(msg(r, "error.unknown_command"))
 				return
 			}
 
 			featureSet, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, codelabCode)
 			if err != nil {
-				w.WriteResponse(chat.MsgAf("⚠️ Не удалось загрузить результаты анализа %s: %v",
+				w.WriteResponse(msg(r, "chat.features_failed",
 					codelabCode, err))
 				r.Log.Printf("failed to fetch features for codelab %s (chatID: %d): %v",
 					codelabCode, r.ChatID, err)
@@ -149,7 +155,7 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 				for _, codelab := range codelabs {
 					features, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, codelab.Code)
 					if err != nil {
-						w.WriteResponse(chat.MsgAf("⚠️ Не удалось загрузить результаты анализа %s: %v",
+						w.WriteResponse(msg(r, "chat.features_failed",
 							codelab.Code, err))
 						r.Log.Printf("failed to fetch features for codelab %s (chatID: %d): %v",
 							codelab.Code, r.ChatID, err)
@@ -166,7 +172,7 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 
 			history, err := r.Storage.GetChatHistory(ctx, r.ChatID, 100)
 			if err != nil {
-				w.WriteResponse(chat.MsgAf("⚠️ Ошибка получения истории чата: %v", err))
+				w.WriteResponse(msg(r, "error.history.get", err))
 				r.Log.Printf("failed to read chat history (chatID: %d): %v", r.ChatID, err)
 				return
 			}
@@ -183,23 +189,42 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 				}
 			}
 
-			prompt := prompts.Get(myGeneticsChatPrompt, r.Completer.ModelName())
+			prompt := prompts.Get(myGeneticsChatPrompt, promptLang(r), r.Completer.ModelName())
 			if prompt == prompts.Default {
-				w.WriteResponse(chat.MsgA("⛔ Промпт не найден."))
+				w.WriteResponse(msg(r, "error.prompt_not_found"))
 				return
 			}
 
-			contextMsg := "Следующие данные генетического анализа должны использоваться для ответа на мои вопросы:\n\n" +
-				featureSet.BuildLLMContext() +
-				"\n\nТеперь я буду задавать вопросы, опираясь на эти данные."
+			featuresContext, err := llmContext(r, featureSet)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.report", err))
+				r.Log.Printf("failed to build llm context (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			contextMsg := tr(r, "chat.context", featuresContext)
+
+			// План действий, чтобы ассистент учитывал уже выполненное.
+			if p, err := r.Storage.GetPlan(ctx, r.From.ID); err != nil {
+				r.Log.Printf("failed to read plan (chatID: %d): %v", r.ChatID, err)
+			} else if !p.IsEmpty() {
+				contextMsg += "\n\n" + planContext(r, p)
+			}
+
+			if profileMsg := profileContext(r); profileMsg != "" {
+				contextMsg += "\n\n" + profileMsg
+			}
+
+			if journalMsg := journalContext(ctx, r); journalMsg != "" {
+				contextMsg += "\n\n" + journalMsg
+			}
 
 			msgs := make([]chat.Message, 0, 3+len(filteredHistory))
 			msgs = append(msgs, chat.MsgS(prompt))     // Системный промпт
 			msgs = append(msgs, chat.MsgU(contextMsg)) // Данные как сообщение пользователя
 
 			// Подтверждающий ответ ассистента после контекста
-			confirmationMsg := "Я изучил предоставленные генетические данные. " +
-				"Теперь я готов ответить на ваши вопросы, опираясь на эту информацию."
+			confirmationMsg := tr(r, "chat.confirmation")
 			msgs = append(msgs, chat.MsgA(confirmationMsg))
 
 			// История чата
@@ -210,7 +235,7 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 
 			// -----------------------------------------------------------------
 
-			w.WriteResponse(chat.MsgA("🤔 Анализирую ваш вопрос..."))
+			w.WriteResponse(msg(r, "chat.thinking"))
 
 			done := make(chan struct{})
 			go func() {
@@ -228,8 +253,7 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 
 			response, err := r.Completer.CompleteChat(ctx, msgs)
 			if err != nil {
-				w.WriteResponse(chat.MsgA("⚠️ Не удалось получить ответ. " +
-					"Пожалуйста, попробуйте позже или переформулируйте вопрос."))
+				w.WriteResponse(msg(r, "chat.failed"))
 				r.Log.Printf("failed to complete chat (chatID: %d): %v", r.ChatID, err)
 				return
 			}
@@ -239,24 +263,22 @@ func myGeneticsChat(data SelectItemData) server.Handler {
 			// Сохраняем всю историю плюс новые сообщения
 			newHistory := make([]chat.Message, len(history)+2)
 			copy(newHistory, history)
-			newHistory[len(history)] = chat.MsgU(msgText)
-			newHistory[len(history)+1] = chat.MsgA(response.Content)
+			questionMsg, answerMsg := chat.MsgU(msgText), chat.MsgA(response.Content)
+			newHistory[len(history)] = questionMsg
+			newHistory[len(history)+1] = answerMsg
 
 			if err := r.Storage.SaveChatHistory(ctx, r.ChatID, newHistory); err != nil {
-				w.WriteResponse(chat.MsgAf("⚠️ Ошибка сохранения истории чата: %v", err))
+				w.WriteResponse(msg(r, "error.history.save", err))
 				r.Log.Printf("failed to write chat history (chatID: %d): %v", r.ChatID, err)
 				return
 			}
 
-			if !sendCode {
-				w.WriteResponse(chat.MsgA(response.Content))
-			} else {
-				w.WriteResponse(chat.MsgAf(
-					"🧠 Вот, что показывают данные из анализа %s.\n\n%s",
-					codelabCode,
-					response.Content,
-				))
+			answer := fmt.Sprint(response.Content)
+			if sendCode {
+				answer = tr(r, "chat.answer", codelabCode, answer)
 			}
+
+			writeRatedAnswer(ctx, w, r, answer, myGeneticsChatPrompt, questionMsg.ID, answerMsg.ID, response.Score)
 		},
 	)
 }
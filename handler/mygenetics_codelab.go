@@ -2,27 +2,32 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
-	"time"
 
 	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/genetics"
 	"github.com/muzykantov/health-gpt/handler/prompts"
+	"github.com/muzykantov/health-gpt/i18n"
 	"github.com/muzykantov/health-gpt/mygenetics"
 	"github.com/muzykantov/health-gpt/server"
 )
 
 const myGeneticsCodelabPrompt = "codelab"
 
+// errPromptNotFound означает, что промпт не найден.
+var errPromptNotFound = errors.New("prompt not found")
+
 // myGeneticsCodelab создает обработчик для отображения результатов конкретного анализа.
 // Если код начинается с "ai:", предоставляет интерпретацию через ИИ, в противном случае
-// показывает детальные результаты. Требует авторизации пользователя.
+// показывает детальные результаты постранично. Требует авторизации пользователя.
 func myGeneticsCodelab(data SelectItemData) server.Handler {
 	return server.HandlerFunc(
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
 			access := mygenetics.AccessToken(r.From.Tokens)
 			if access == "" {
-				w.WriteResponse(chat.MsgA("⚠️ Для доступа к анализам необходимо авторизоваться. " +
-					"Пожалуйста, введите свой email и пароль."))
+				w.WriteResponse(msg(r, "auth.required"))
 				return
 			}
 
@@ -35,65 +40,74 @@ func myGeneticsCodelab(data SelectItemData) server.Handler {
 				data = strings.TrimPrefix(data, PrefixCodelab)
 				useAI = false
 			default:
-				w.WriteResponse(chat.MsgAf("⛔ Неизвестный префикс: %s.", data))
+				w.WriteResponse(msg(r, "codelab.unknown_prefix", data))
 				return
 			}
 
-			w.WriteResponse(chat.MsgAf("🔍 Загружаю результаты анализа %s. "+
-				"Это займёт несколько секунд...", data))
+			w.WriteResponse(msg(r, "codelab.loading", data))
+
+			if !useAI {
+				findResults(findData("", data, 0)).Serve(ctx, w, r)
+				return
+			}
 
 			features, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, data)
 			if err != nil {
-				w.WriteResponse(chat.MsgA("⚠️ Не удалось получить информацию об анализе. " +
-					"Пожалуйста, попробуйте позже или обратитесь в поддержку."))
+				w.WriteResponse(msg(r, "codelab.fetch_failed"))
 
 				r.Log.Printf("failed to fetch features (chatID: %d): %v", r.ChatID, err)
 				return
 			}
 
-			if !useAI {
-				for i, feature := range features {
-					time.Sleep(time.Millisecond * 300)
-					select {
-					case <-ctx.Done():
-						return
-
-					default:
-						w.WriteResponse(chat.MsgAf("%s\n📑 Показываю результат %d из %d.",
-							feature.ToHTML(), i+1, len(features)))
-					}
-				}
-
+			msgs, err := codelabMessages(r, features)
+			if errors.Is(err, errPromptNotFound) {
+				w.WriteResponse(msg(r, "error.prompt_not_found"))
 				return
 			}
-
-			prompt := prompts.Get(myGeneticsCodelabPrompt, r.Completer.ModelName())
-			if prompt == prompts.Default {
-				w.WriteResponse(chat.MsgA("⛔ Промпт не найден."))
+			if err != nil {
+				w.WriteResponse(msg(r, "error.report", err))
+				r.Log.Printf("failed to build llm context (chatID: %d): %v", r.ChatID, err)
 				return
 			}
 
-			msgs := make([]chat.Message, 0, 2)
-			msgs = append(msgs, chat.MsgS(prompt))
-			msgs = append(msgs, chat.MsgU(features.BuildLLMContext()))
+			w.WriteResponse(chat.MsgA(i18n.N(lang(r), "codelab.loaded", len(features), len(features))))
 
-			w.WriteResponse(chat.MsgAf("📑 Загружено %d параметров анализа. "+
-				"Приступаю к обработке...", len(features)))
-
-			w.WriteResponse(chat.MsgA("⌛ Анализирую результаты с помощью ИИ. " +
-				"Это может занять до минуты..."))
+			w.WriteResponse(msg(r, "codelab.analyzing"))
 
 			response, err := r.Completer.CompleteChat(ctx, msgs)
 			if err != nil {
-				w.WriteResponse(chat.MsgA("⚠️ Не удалось получить интерпретацию результатов. " +
-					"Пожалуйста, попробуйте позже или " +
-					"просмотрите результаты без анализа ИИ."))
+				w.WriteResponse(msg(r, "codelab.ai_failed"))
 
 				r.Log.Printf("failed to complete chat (chatID: %d): %v", r.ChatID, err)
 				return
 			}
 
-			w.WriteResponse(chat.MsgA(response.Content))
+			// Ответ не попадает в историю, идентификатор нужен для его оценки.
+			answer := chat.MsgA(response.Content)
+			writeRatedAnswer(ctx, w, r, fmt.Sprint(answer.Content), myGeneticsCodelabPrompt, r.Incoming.ID, answer.ID, response.Score)
 		},
 	)
 }
+
+// codelabMessages формирует запрос к ИИ для интерпретации результатов анализа.
+func codelabMessages(r *server.Request, features genetics.FeatureSet) ([]chat.Message, error) {
+	prompt := prompts.Get(myGeneticsCodelabPrompt, promptLang(r), r.Completer.ModelName())
+	if prompt == prompts.Default {
+		return nil, errPromptNotFound
+	}
+
+	featuresContext, err := llmContext(r, features)
+	if err != nil {
+		return nil, err
+	}
+
+	// Анкета, чтобы рекомендации учитывали возраст, питание и ограничения.
+	if profileMsg := profileContext(r); profileMsg != "" {
+		featuresContext += "\n\n" + profileMsg
+	}
+
+	return []chat.Message{
+		chat.MsgS(prompt),
+		chat.MsgU(featuresContext),
+	}, nil
+}
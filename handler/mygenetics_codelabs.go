@@ -15,21 +15,18 @@ func myGeneticsCodelabs(cmd Command) server.Handler {
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
 			access := mygenetics.AccessToken(r.From.Tokens)
 			if access == "" {
-				w.WriteResponse(chat.MsgA("⚠️ Для доступа к анализам необходимо авторизоваться. " +
-					"Пожалуйста, введите свой email и пароль."))
+				w.WriteResponse(msg(r, "auth.required"))
 				return
 			}
 
 			codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
 			if err != nil {
-				w.WriteResponse(chat.MsgA("⚠️ Не удалось загрузить список анализов. " +
-					"Пожалуйста, попробуйте позже или обратитесь в поддержку."))
+				w.WriteResponse(msg(r, "codelabs.fetch_failed"))
 				return
 			}
 
 			if len(codelabs) == 0 {
-				w.WriteResponse(chat.MsgA("⚠️ У вас пока нет доступных анализов. " +
-					"Новые результаты появятся здесь автоматически."))
+				w.WriteResponse(msg(r, "codelabs.empty"))
 				return
 			}
 
@@ -40,7 +37,7 @@ func myGeneticsCodelabs(cmd Command) server.Handler {
 
 			if cmdMyGenetics {
 				msgContent := content.Select{
-					Header: "🧪 Выберите анализ, чтобы просмотреть детальные результаты:",
+					Header: tr(r, "codelabs.select"),
 				}
 				for _, codelab := range codelabs {
 					msgContent.Items = append(msgContent.Items, content.SelectItem{
@@ -54,8 +51,7 @@ func myGeneticsCodelabs(cmd Command) server.Handler {
 
 			if cmdMyGeneticsAI {
 				msgContent := content.Select{
-					Header: "🧪 Выберите анализ для получения " +
-						"развёрнутой интерпретации результатов с помощью ИИ:",
+					Header: tr(r, "codelabs.select_ai"),
 				}
 				for _, codelab := range codelabs {
 					msgContent.Items = append(msgContent.Items, content.SelectItem{
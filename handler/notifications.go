@@ -0,0 +1,383 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/i18n"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// Расписания повторяющихся уведомлений в формате cron. Задаются из
+// конфигурации при запуске.
+var (
+	ReminderSpec = "0 10 * * *"   // Напоминания о плане: ежедневно в 10:00.
+	TipSpec      = "0 12 * * 1"   // Советы: по понедельникам в 12:00.
+	WatchSpec    = "*/30 * * * *" // Проверка новых результатов: каждые полчаса.
+)
+
+// timeZoneDefault - данные кнопки, возвращающей часовой пояс сервера.
+const timeZoneDefault = "server"
+
+// quietHoursPresets - варианты тихих часов, из которых выбирает пользователь.
+var quietHoursPresets = []chat.QuietHours{
+	{},
+	{From: 22, To: 8},
+	{From: 23, To: 7},
+	{From: 21, To: 9},
+}
+
+// notification обрабатывает срабатывание задания планировщика. Если
+// уведомлять не о чем или пользователь вышел, ничего не отправляет.
+func notification(n content.Notification) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if r.From.State != chat.UserStateAuthorized {
+				return
+			}
+
+			switch chat.JobKind(n.Kind) {
+			case chat.JobReminder:
+				notifyReminder().Serve(ctx, w, r)
+
+			case chat.JobCodelab:
+				watchCodelabs().Serve(ctx, w, r)
+
+			case chat.JobTip:
+				notifyTip().Serve(ctx, w, r)
+
+			default:
+				r.Log.Printf("unknown notification kind (chatID: %d): %s", r.ChatID, n.Kind)
+			}
+		},
+	)
+}
+
+// notifyReminder напоминает о невыполненных пунктах плана и предлагает
+// отметить их.
+func notifyReminder() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			p, err := r.Storage.GetPlan(ctx, r.From.ID)
+			if err != nil {
+				r.Log.Printf("failed to read plan (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			var items []content.SelectItem
+			for _, task := range p.Tasks {
+				if !task.Done {
+					items = append(items, content.SelectItem{
+						Caption: "⬜ " + task.Text,
+						Data:    PrefixPlanToggle + task.ID,
+					})
+				}
+			}
+
+			if len(items) == 0 {
+				return
+			}
+
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: i18n.N(lang(r), "notify.reminder", len(items), len(items)),
+				Items:  items,
+			}))
+		},
+	)
+}
+
+// notifyTip отправляет совет недели из рекомендаций анализа. Признаки
+// с высоким риском идут первыми, каждую неделю выбирается следующий совет.
+func notifyTip() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access, err := renewTokens(ctx, r)
+			if err != nil {
+				r.Log.Printf("failed to renew tokens for tip (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			code, err := notifyCodelabCode(ctx, r, access)
+			if err != nil {
+				r.Log.Printf("failed to choose codelab for tip (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+			if code == "" {
+				return
+			}
+
+			features, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, code)
+			if err != nil {
+				r.Log.Printf("failed to fetch features for tip (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			type tip struct{ feature, text string }
+
+			var tips []tip
+			for _, feature := range features.SortByRisk() {
+				for _, text := range append(append([]string{}, feature.Nutrition...), feature.Additional...) {
+					tips = append(tips, tip{feature.Name, text})
+				}
+			}
+
+			if len(tips) == 0 {
+				return
+			}
+
+			_, week := chat.Now().ISOWeek()
+			t := tips[week%len(tips)]
+
+			w.WriteResponse(msg(r, "notify.tip", t.feature, t.text))
+		},
+	)
+}
+
+// notifyCodelabCode возвращает анализ, выбранный в чате, иначе первый
+// из анализов пользователя.
+func notifyCodelabCode(ctx context.Context, r *server.Request, access mygenetics.Token) (string, error) {
+	state, err := r.Storage.GetChatState(ctx, r.ChatID)
+	if err != nil {
+		return "", err
+	}
+
+	if code, ok := state.SelectedCodelab(); ok {
+		return code, nil
+	}
+
+	codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
+	if err != nil || len(codelabs) == 0 {
+		return "", err
+	}
+
+	return codelabs[0].Code, nil
+}
+
+// scheduleNotifications создает повторяющиеся задания планировщика для
+// включенных пользователем уведомлений. Планировщик вычисляет время
+// выполнения нового задания сам и доставляет его только через транспорт
+// запроса. Существующие задания не изменяются, чтобы не сбивать их
+// расписание.
+func scheduleNotifications(ctx context.Context, r *server.Request, kinds ...chat.JobKind) {
+	jobs, err := r.Storage.GetJobs(ctx)
+	if err != nil {
+		r.Log.Printf("failed to read jobs (chatID: %d): %v", r.ChatID, err)
+		return
+	}
+
+	for _, kind := range kinds {
+		id := chat.JobID(r.ChatID, kind, "")
+		if slices.ContainsFunc(jobs, func(job chat.Job) bool { return job.ID == id }) {
+			continue
+		}
+
+		var spec string
+		switch kind {
+		case chat.JobReminder:
+			spec = ReminderSpec
+		case chat.JobCodelab:
+			spec = WatchSpec
+		case chat.JobTip:
+			spec = TipSpec
+		}
+
+		if spec == "" || !r.From.Notifications.Enabled(kind) {
+			continue
+		}
+
+		if err := r.Storage.SaveJob(ctx, chat.Job{
+			ID:        id,
+			ChatID:    r.ChatID,
+			UserID:    r.From.ID,
+			Kind:      kind,
+			Transport: string(r.Transport),
+			Spec:      spec,
+		}); err != nil {
+			r.Log.Printf("failed to schedule %s notifications (chatID: %d): %v", kind, r.ChatID, err)
+		}
+	}
+}
+
+// notifications показывает настройки уведомлений: кнопки для включения
+// и отключения каждого вида уведомлений и выбора тихих часов.
+func notifications() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			settings := r.From.Notifications
+
+			msgContent := content.Select{Header: tr(r, "notifications.header")}
+			for _, kind := range chat.JobKinds {
+				mark := "🔔"
+				if !settings.Enabled(kind) {
+					mark = "🔕"
+				}
+
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: fmt.Sprintf("%s %s", mark, tr(r, "notifications.kind."+string(kind))),
+					Data:    PrefixNotifyToggle + string(kind),
+				})
+			}
+			msgContent.Items = append(msgContent.Items,
+				content.SelectItem{
+					Caption: tr(r, "notifications.quiet", quietHoursName(r, settings.QuietHours)),
+					Data:    PrefixNotifyQuiet,
+				},
+				content.SelectItem{
+					Caption: tr(r, "notifications.timezone", timeZoneName(r, settings.TimeZone)),
+					Data:    PrefixNotifyZone,
+				},
+			)
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// notificationsToggle включает или отключает уведомления вида kind.
+func notificationsToggle(kind chat.JobKind) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			user := r.From
+			user.Notifications = user.Notifications.Toggle(kind)
+
+			if err := r.Storage.SaveUser(ctx, user); err != nil {
+				w.WriteResponse(msg(r, "error.settings.save", err))
+				r.Log.Printf("failed to save user (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			r.From = user
+
+			name := tr(r, "notifications.kind."+string(kind))
+			if user.Notifications.Enabled(kind) {
+				scheduleNotifications(ctx, r, kind)
+				w.WriteResponse(msg(r, "notifications.enabled", name))
+			} else {
+				w.WriteResponse(msg(r, "notifications.disabled", name))
+			}
+
+			notifications().Serve(ctx, w, r)
+		},
+	)
+}
+
+// notificationsQuiet задает тихие часы в виде "22-8". Без значения
+// предлагает выбрать один из вариантов.
+func notificationsQuiet(value string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if value == "" {
+				msgContent := content.Select{Header: tr(r, "notifications.quiet.select")}
+				for _, preset := range quietHoursPresets {
+					msgContent.Items = append(msgContent.Items, content.SelectItem{
+						Caption: quietHoursName(r, preset),
+						Data:    fmt.Sprintf("%s%d-%d", PrefixNotifyQuiet, preset.From, preset.To),
+					})
+				}
+
+				w.WriteResponse(chat.MsgA(msgContent))
+				return
+			}
+
+			from, to, _ := strings.Cut(value, "-")
+			fromHour, errFrom := strconv.Atoi(from)
+			toHour, errTo := strconv.Atoi(to)
+			if errFrom != nil || errTo != nil || fromHour < 0 || fromHour > 23 || toHour < 0 || toHour > 23 {
+				w.WriteResponse(msg(r, "notifications.quiet.invalid"))
+				r.Log.Printf("invalid quiet hours (chatID: %d): %s", r.ChatID, value)
+				return
+			}
+
+			user := r.From
+			user.Notifications.QuietHours = chat.QuietHours{From: fromHour, To: toHour}
+
+			if err := r.Storage.SaveUser(ctx, user); err != nil {
+				w.WriteResponse(msg(r, "error.settings.save", err))
+				r.Log.Printf("failed to save user (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			r.From = user
+
+			w.WriteResponse(msg(r, "notifications.quiet.set", quietHoursName(r, user.Notifications.QuietHours)))
+			notifications().Serve(ctx, w, r)
+		},
+	)
+}
+
+// quietHoursName возвращает описание тихих часов на языке пользователя.
+func quietHoursName(r *server.Request, q chat.QuietHours) string {
+	if q.IsEmpty() {
+		return tr(r, "notifications.quiet.none")
+	}
+
+	return q.String()
+}
+
+// notificationsZone задает часовой пояс тихих часов в виде "UTC+3". Без
+// значения предлагает выбрать смещение от UTC.
+func notificationsZone(value string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if value == "" {
+				msgContent := content.Select{Header: tr(r, "notifications.timezone.select"), Columns: 4}
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: timeZoneName(r, ""),
+					Data:    PrefixNotifyZone + timeZoneDefault,
+				})
+				for offset := -12; offset <= 14; offset++ {
+					zone := fmt.Sprintf("UTC%+d", offset)
+					if offset == 0 {
+						zone = "UTC"
+					}
+
+					msgContent.Items = append(msgContent.Items, content.SelectItem{
+						Caption: zone,
+						Data:    PrefixNotifyZone + zone,
+					})
+				}
+
+				w.WriteResponse(chat.MsgA(msgContent))
+				return
+			}
+
+			user := r.From
+			if value == timeZoneDefault {
+				user.Notifications.TimeZone = ""
+			} else {
+				if _, err := chat.ParseTimeZone(value); err != nil {
+					w.WriteResponse(msg(r, "notifications.timezone.invalid"))
+					r.Log.Printf("invalid time zone (chatID: %d): %s", r.ChatID, value)
+					return
+				}
+				user.Notifications.TimeZone = value
+			}
+
+			if err := r.Storage.SaveUser(ctx, user); err != nil {
+				w.WriteResponse(msg(r, "error.settings.save", err))
+				r.Log.Printf("failed to save user (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			r.From = user
+
+			w.WriteResponse(msg(r, "notifications.timezone.set", timeZoneName(r, user.Notifications.TimeZone)))
+			notifications().Serve(ctx, w, r)
+		},
+	)
+}
+
+// timeZoneName возвращает описание часового пояса на языке пользователя.
+func timeZoneName(r *server.Request, zone string) string {
+	if zone == "" {
+		return tr(r, "notifications.timezone.server")
+	}
+
+	return zone
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/i18n"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// plan показывает план действий с прогрессом и кнопками для отметки
+// пунктов. Если план пуст, предлагает составить его из анализа.
+func plan() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			p, err := r.Storage.GetPlan(ctx, r.From.ID)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.plan.get", err))
+				r.Log.Printf("failed to read plan (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			if p.IsEmpty() {
+				planCodelabs(tr(r, "plan.empty")).Serve(ctx, w, r)
+				return
+			}
+
+			done, total := p.Progress()
+			msgContent := content.Select{Header: tr(r, "plan.progress", done, total)}
+			for _, task := range p.Tasks {
+				mark := "⬜"
+				if task.Done {
+					mark = "✅"
+				}
+
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: fmt.Sprintf("%s %s", mark, task.Text),
+					Data:    PrefixPlanToggle + task.ID,
+				})
+			}
+			msgContent.Items = append(msgContent.Items, content.SelectItem{
+				Caption: tr(r, "plan.add"),
+				Data:    PrefixPlanAdd,
+			})
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// planCodelabs предлагает выбрать анализ, из чеклистов которого будет
+// составлен план.
+func planCodelabs(header string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelabs.fetch_failed"))
+				r.Log.Printf("failed to fetch codelabs (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			if len(codelabs) == 0 {
+				w.WriteResponse(msg(r, "codelabs.empty"))
+				return
+			}
+
+			msgContent := content.Select{Header: header}
+			for _, codelab := range codelabs {
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: fmt.Sprintf("%s (%s)", codelab.Name, codelab.Code),
+					Data:    PrefixPlanAdd + codelab.Code,
+				})
+			}
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// planAdd добавляет в план пункты чеклистов анализа. Без кода анализа
+// предлагает его выбрать.
+func planAdd(code string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if code == "" {
+				planCodelabs(tr(r, "plan.select_codelab")).Serve(ctx, w, r)
+				return
+			}
+
+			access := mygenetics.AccessToken(r.From.Tokens)
+			if access == "" {
+				w.WriteResponse(msg(r, "auth.required"))
+				return
+			}
+
+			features, err := mygenetics.DefaultClient.FetchFeatures(ctx, access, code)
+			if err != nil {
+				w.WriteResponse(msg(r, "codelab.fetch_failed"))
+				r.Log.Printf("failed to fetch features (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			var tasks []chat.Task
+			for _, feature := range features.SortByRisk() {
+				for _, item := range feature.Checklist {
+					tasks = append(tasks, chat.Task{
+						Codelab: code,
+						Feature: feature.Name,
+						Text:    item,
+					})
+				}
+			}
+
+			p, err := r.Storage.GetPlan(ctx, r.From.ID)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.plan.get", err))
+				r.Log.Printf("failed to read plan (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			p, added := p.Add(tasks...)
+			if added == 0 {
+				w.WriteResponse(msg(r, "plan.nothing_added", code))
+				return
+			}
+
+			if err := r.Storage.SavePlan(ctx, r.From.ID, p); err != nil {
+				w.WriteResponse(msg(r, "error.plan.save", err))
+				r.Log.Printf("failed to write plan (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			w.WriteResponse(chat.MsgA(i18n.N(lang(r), "plan.added", added, added, code)))
+			scheduleNotifications(ctx, r, chat.JobReminder)
+			plan().Serve(ctx, w, r)
+		},
+	)
+}
+
+// planToggle отмечает пункт плана выполненным или снимает отметку.
+func planToggle(id string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			p, err := r.Storage.GetPlan(ctx, r.From.ID)
+			if err != nil {
+				w.WriteResponse(msg(r, "error.plan.get", err))
+				r.Log.Printf("failed to read plan (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			p, ok := p.Toggle(id)
+			if !ok {
+				w.WriteResponse(msg(r, "plan.task_not_found"))
+				return
+			}
+
+			if err := r.Storage.SavePlan(ctx, r.From.ID, p); err != nil {
+				w.WriteResponse(msg(r, "error.plan.save", err))
+				r.Log.Printf("failed to write plan (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			if task, _ := p.Task(id); task.Done {
+				w.WriteResponse(msg(r, "plan.done", task.Text))
+			} else {
+				w.WriteResponse(msg(r, "plan.undone", task.Text))
+			}
+
+			plan().Serve(ctx, w, r)
+		},
+	)
+}
+
+// planContext описывает для ИИ выполненные и оставшиеся пункты плана.
+func planContext(r *server.Request, p chat.Plan) string {
+	var done, todo []string
+	for _, task := range p.Tasks {
+		item := fmt.Sprintf("- %s (%s)", task.Text, task.Feature)
+		if task.Done {
+			done = append(done, item)
+		} else {
+			todo = append(todo, item)
+		}
+	}
+
+	var sb strings.Builder
+	if len(done) > 0 {
+		sb.WriteString(tr(r, "plan.context.done", strings.Join(done, "\n")))
+	}
+	if len(todo) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(tr(r, "plan.context.todo", strings.Join(todo, "\n")))
+	}
+
+	return sb.String()
+}
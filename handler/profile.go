@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// Режимы заполнения анкеты: по порядку все вопросы или один выбранный.
+const (
+	profileModeNext = "next"
+	profileModeOne  = "one"
+)
+
+// profile показывает анкету пользователя и предлагает изменить ответы.
+// Пустую анкету предлагает заполнить с первого вопроса.
+func profile() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			p := r.From.Profile
+			if p.IsEmpty() {
+				w.WriteResponse(msg(r, "profile.intro"))
+				profileAsk(chat.ProfileFields[0], profileModeNext).Serve(ctx, w, r)
+				return
+			}
+
+			msgContent := content.Select{Header: tr(r, "profile.summary", profileSummary(r, p))}
+			for _, field := range chat.ProfileFields {
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: tr(r, "profile.edit", tr(r, "profile.field."+string(field))),
+					Data:    PrefixProfile + "edit:" + string(field),
+				})
+			}
+			msgContent.Items = append(msgContent.Items, content.SelectItem{
+				Caption: tr(r, "profile.refill"),
+				Data:    PrefixProfile + "fill",
+			})
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// profileOffer предлагает заполнить анкету, если пользователь еще не
+// отвечал на вопросы.
+func profileOffer() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if !r.From.Profile.IsEmpty() {
+				return
+			}
+
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: tr(r, "profile.offer"),
+				Items: []content.SelectItem{
+					{Caption: tr(r, "profile.fill"), Data: PrefixProfile + "fill"},
+					{Caption: tr(r, "profile.later"), Data: PrefixProfile + "later"},
+				},
+			}))
+		},
+	)
+}
+
+// profileAction обрабатывает кнопки анкеты. Данные кнопки имеют вид
+// "fill", "later", "edit:<вопрос>" или "set:<режим>:<вопрос>:<ответ>".
+func profileAction(data SelectItemData) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			action, args, _ := strings.Cut(data, ":")
+			switch action {
+			case "fill":
+				profileAsk(chat.ProfileFields[0], profileModeNext).Serve(ctx, w, r)
+
+			case "later":
+				clearInput(ctx, r)
+				w.WriteResponse(msg(r, "profile.postponed"))
+
+			case "edit":
+				profileAsk(chat.ProfileField(args), profileModeOne).Serve(ctx, w, r)
+
+			case "set":
+				parts := strings.SplitN(args, ":", 3)
+				if len(parts) != 3 {
+					w.WriteResponse(msg(r, "error.unknown_command"))
+					return
+				}
+
+				profileAnswer(parts[0], chat.ProfileField(parts[1]), parts[2]).Serve(ctx, w, r)
+
+			default:
+				w.WriteResponse(msg(r, "error.unknown_command"))
+			}
+		},
+	)
+}
+
+// profileAsk задает вопрос анкеты и запоминает, что следующее текстовое
+// сообщение - ответ на него. Для вопросов с выбором показывает варианты.
+func profileAsk(field chat.ProfileField, mode string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			step := slices.Index(chat.ProfileFields, field)
+			if step < 0 {
+				w.WriteResponse(msg(r, "error.unknown_command"))
+				return
+			}
+
+			awaitInput(ctx, r, PrefixProfile+mode+":"+string(field))
+
+			header := tr(r, "profile.question."+string(field))
+			if mode == profileModeNext {
+				header = tr(r, "profile.step", step+1, len(chat.ProfileFields), header)
+			}
+			if value := r.From.Profile.Value(field); value != "" {
+				header += "\n\n" + tr(r, "profile.current", profileValue(r, field, value))
+			}
+
+			data := fmt.Sprintf("%sset:%s:%s:", PrefixProfile, mode, field)
+
+			msgContent := content.Select{Header: header}
+			for _, option := range field.Options() {
+				msgContent.Items = append(msgContent.Items, content.SelectItem{
+					Caption: profileValue(r, field, option),
+					Data:    data + option,
+				})
+			}
+
+			// Пропуск оставляет прежний ответ, а при изменении одного вопроса
+			// удаляет его.
+			skip := tr(r, "profile.skip")
+			if mode == profileModeOne {
+				skip = tr(r, "profile.reset")
+			}
+			msgContent.Items = append(msgContent.Items, content.SelectItem{Caption: skip, Data: data})
+
+			w.WriteResponse(chat.MsgA(msgContent))
+		},
+	)
+}
+
+// profileText обрабатывает текстовое сообщение как ответ на заданный вопрос
+// анкеты. Вопрос задается в виде "<режим>:<вопрос>". Вопрос пользователя
+// вместо ответа сюда не попадает: его передает ИИ myGenetics.
+func profileText(question, text string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			mode, name, _ := strings.Cut(question, ":")
+			field := chat.ProfileField(name)
+
+			text = strings.TrimSpace(text)
+
+			// Вариант ответа можно написать так же, как на кнопке.
+			for _, option := range field.Options() {
+				if strings.EqualFold(text, profileValue(r, field, option)) {
+					text = option
+				}
+			}
+
+			// Пустой ответ не пропускает вопрос: для этого есть кнопка.
+			if text == "" {
+				w.WriteResponse(msg(r, profileInvalid(field)))
+				profileAsk(field, mode).Serve(ctx, w, r)
+				return
+			}
+
+			profileAnswer(mode, field, text).Serve(ctx, w, r)
+		},
+	)
+}
+
+// profileAnswer сохраняет ответ на вопрос анкеты и задает следующий вопрос.
+// Неверный ответ сопровождается подсказкой, и вопрос задается снова.
+func profileAnswer(mode string, field chat.ProfileField, value string) server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if value != "" || mode == profileModeOne {
+				if _, err := r.From.Profile.Set(field, value); errors.Is(err, chat.ErrInvalidProfileValue) {
+					w.WriteResponse(msg(r, profileInvalid(field)))
+					profileAsk(field, mode).Serve(ctx, w, r)
+					return
+				}
+
+				err := updateUser(ctx, r, func(user *chat.User) {
+					user.Profile, _ = user.Profile.Set(field, value)
+				})
+				if err != nil {
+					w.WriteResponse(msg(r, "error.settings.save", err))
+					r.Log.Printf("failed to save user (chatID: %d): %v", r.ChatID, err)
+					return
+				}
+			}
+
+			if mode == profileModeNext {
+				if i := slices.Index(chat.ProfileFields, field); i >= 0 && i+1 < len(chat.ProfileFields) {
+					profileAsk(chat.ProfileFields[i+1], mode).Serve(ctx, w, r)
+					return
+				}
+			}
+
+			clearInput(ctx, r)
+
+			if mode == profileModeNext {
+				w.WriteResponse(msg(r, "profile.done"))
+			} else {
+				w.WriteResponse(msg(r, "profile.saved"))
+			}
+
+			if !r.From.Profile.IsEmpty() {
+				profile().Serve(ctx, w, r)
+			}
+		},
+	)
+}
+
+// profileInvalid возвращает подсказку для неверного ответа на вопрос.
+func profileInvalid(field chat.ProfileField) string {
+	switch {
+	case field.IsNumeric():
+		return "profile.invalid." + string(field)
+	case field.Options() != nil:
+		return "profile.invalid.option"
+	default:
+		return "profile.invalid.text"
+	}
+}
+
+// profileValue возвращает ответ на языке пользователя.
+func profileValue(r *server.Request, field chat.ProfileField, value string) string {
+	if field.Options() != nil {
+		return tr(r, "profile.option."+string(field)+"."+value)
+	}
+
+	return value
+}
+
+// profileSummary перечисляет ответы анкеты по одному на строку.
+func profileSummary(r *server.Request, p chat.Profile) string {
+	lines := make([]string, 0, len(chat.ProfileFields)+1)
+	for _, field := range chat.ProfileFields {
+		value := p.Value(field)
+		if value == "" {
+			value = tr(r, "profile.no_answer")
+		} else {
+			value = profileValue(r, field, value)
+		}
+
+		lines = append(lines, fmt.Sprintf("- %s: %s", tr(r, "profile.field."+string(field)), value))
+	}
+
+	if bmi := p.BMI(); bmi > 0 {
+		lines = append(lines, "- "+tr(r, "profile.bmi", bmi))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// profileContext описывает для ИИ анкету пользователя, пустая строка, если
+// анкета не заполнена.
+func profileContext(r *server.Request) string {
+	p := r.From.Profile
+	if p.IsEmpty() {
+		return ""
+	}
+
+	var lines []string
+	for _, field := range chat.ProfileFields {
+		if value := p.Value(field); value != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", tr(r, "profile.field."+string(field)), profileValue(r, field, value)))
+		}
+	}
+
+	if bmi := p.BMI(); bmi > 0 {
+		lines = append(lines, "- "+tr(r, "profile.bmi", bmi))
+	}
+
+	return tr(r, "profile.context", strings.Join(lines, "\n"))
+}
@@ -11,20 +11,31 @@ var fs embed.FS
 // Default prompt.
 const Default = "You are helpful assistant."
 
-// Get returns prompt for the specified model.
-func Get(name, model string) string {
-	var filename string
-
-	// example: auth_anthropic_claude-3-7-sonnet-latest.txt
-	filename = fmt.Sprintf("%s_%s.txt", name, model)
-	if prompt, err := fs.ReadFile(filename); err == nil {
-		return string(prompt)
+// Get returns prompt for the specified language and model. Files are
+// resolved in order (the default language has no language suffix):
+//
+//	name_<lang>_<model>.txt → name_<lang>.txt → name_<model>.txt → name.txt
+func Get(name, lang, model string) string {
+	var candidates []string
+
+	if lang != "" {
+		// example: chat_en_openai_gpt-4o.txt, chat_en.txt
+		candidates = append(candidates,
+			fmt.Sprintf("%s_%s_%s.txt", name, lang, model),
+			fmt.Sprintf("%s_%s.txt", name, lang),
+		)
 	}
 
-	// example: auth.txt
-	filename = fmt.Sprintf("%s.txt", name)
-	if prompt, err := fs.ReadFile(filename); err == nil {
-		return string(prompt)
+	// example: auth_anthropic_claude-3-7-sonnet-latest.txt, auth.txt
+	candidates = append(candidates,
+		fmt.Sprintf("%s_%s.txt", name, model),
+		fmt.Sprintf("%s.txt", name),
+	)
+
+	for _, filename := range candidates {
+		if prompt, err := fs.ReadFile(filename); err == nil {
+			return string(prompt)
+		}
 	}
 
 	return Default
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"github.com/muzykantov/health-gpt/genetics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// reportRenderer возвращает генератор отчетов в разметке транспорта
+// и на языке пользователя.
+func reportRenderer(r *server.Request) (*genetics.Renderer, error) {
+	format := genetics.Format(r.Format)
+	if format == "" {
+		format = genetics.FormatHTML
+	}
+
+	return genetics.NewRenderer(format, genetics.LocaleFor(string(lang(r))))
+}
+
+// llmContext формирует контекст для ИИ с заголовками на языке пользователя.
+// Признаки с наибольшим риском идут первыми.
+func llmContext(r *server.Request, features genetics.FeatureSet) (string, error) {
+	renderer, err := genetics.NewRenderer(genetics.FormatLLM, genetics.LocaleFor(string(lang(r))))
+	if err != nil {
+		return "", err
+	}
+
+	return renderer.FeatureSet(features.SortByRisk())
+}
@@ -2,26 +2,41 @@ package handler
 
 import (
 	"context"
+	"strings"
 
 	"github.com/muzykantov/health-gpt/chat"
 	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/i18n"
 	"github.com/muzykantov/health-gpt/server"
 )
 
 func Start() server.Handler {
 	return server.HandlerFunc(
 		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			// Уведомления планировщика не требуют ответа пользователя
+			// и отправляются без списка команд и входа.
+			if n, ok := r.Incoming.Content.(content.Notification); ok {
+				notification(n).Serve(ctx, w, r)
+				return
+			}
+
+			// Язык можно выбрать до входа, чтобы пройти его на своем языке.
+			switch msgContent := r.Incoming.Content.(type) {
+			case content.Command:
+				if Command(msgContent.Name) == CmdLanguage {
+					language(msgContent.Args).Serve(ctx, w, r)
+					return
+				}
+
+			case content.SelectItem:
+				if strings.HasPrefix(msgContent.Data, PrefixLanguage) {
+					language(strings.TrimPrefix(msgContent.Data, PrefixLanguage)).Serve(ctx, w, r)
+					return
+				}
+			}
+
 			if r.From.State == chat.UserStateUnauthorized {
-				msg := chat.MsgA(content.Commands{
-					Items: []content.Command{
-						{
-							Name:        string(CmdStart),
-							Description: "Начать общение с ботом",
-						},
-					},
-				})
-
-				w.WriteResponse(msg)
+				w.WriteResponse(unauthorizedCommands(lang(r)))
 			} else {
 				commands(CmdUnspecified).Serve(ctx, w, r) // List of commands
 			}
@@ -30,3 +45,20 @@ func Start() server.Handler {
 		},
 	)
 }
+
+// unauthorizedCommands возвращает список команд на языке lang для
+// пользователя, который еще не вошел.
+func unauthorizedCommands(lang i18n.Lang) chat.Message {
+	return chat.MsgA(content.Commands{
+		Items: []content.Command{
+			{
+				Name:        string(CmdStart),
+				Description: i18n.T(lang, "command.begin"),
+			},
+			{
+				Name:        string(CmdLanguage),
+				Description: i18n.T(lang, "command.language"),
+			},
+		},
+	})
+}
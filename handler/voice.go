@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// maxVoiceChunk ограничивает длину текста одного голосового сообщения
+// (примерно минута речи).
+const maxVoiceChunk = 1000
+
+var (
+	speechTags     = regexp.MustCompile(`<[^<>]+>`)
+	speechHeadings = regexp.MustCompile(`(?m)^[ \t]*#{1,6}[ \t]+`)
+	speechBullets  = regexp.MustCompile(`(?m)^[ \t]*(?:[-*+•])[ \t]+`)
+	speechMarkup   = regexp.MustCompile("\\*\\*|__|~~|`+")
+	speechLinks    = regexp.MustCompile(`\[([^\[\]\n]+)\]\([^\s()]+\)`)
+	speechSentence = regexp.MustCompile(`[^.!?…\n]+(?:[.!?…]+|\n+|$)`)
+)
+
+// voice включает и выключает голосовые ответы пользователя.
+func voice() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if r.Synthesizer == nil {
+				w.WriteResponse(msg(r, "voice.unavailable"))
+				return
+			}
+
+			user := r.From
+			user.VoiceReplies = !user.VoiceReplies
+
+			if err := r.Storage.SaveUser(ctx, user); err != nil {
+				w.WriteResponse(msg(r, "error.settings.save", err))
+				r.Log.Printf("failed to save user (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			if user.VoiceReplies {
+				w.WriteResponse(msg(r, "voice.enabled"))
+			} else {
+				w.WriteResponse(msg(r, "voice.disabled"))
+			}
+		},
+	)
+}
+
+// writeAnswer отправляет ответ ассистента. Если пользователь включил голосовые
+// ответы, длинный ответ озвучивается частями; если озвучить не удалось,
+// отправляется текст.
+func writeAnswer(ctx context.Context, w server.ResponseWriter, r *server.Request, text string) {
+	if !r.From.VoiceReplies || r.Synthesizer == nil {
+		w.WriteResponse(chat.MsgA(text))
+		return
+	}
+
+	chunks := splitSpeech(speechText(text), maxVoiceChunk)
+
+	voices := make([][]byte, 0, len(chunks))
+	for _, chunk := range chunks {
+		audio, err := r.Synthesizer.Synthesize(ctx, chunk)
+		if err != nil {
+			r.Log.Printf("failed to synthesize answer (chatID: %d): %v", r.ChatID, err)
+			w.WriteResponse(chat.MsgA(text))
+			return
+		}
+
+		voices = append(voices, audio)
+	}
+
+	for i, audio := range voices {
+		var caption string
+		if len(voices) > 1 {
+			caption = fmt.Sprintf("🔊 %d/%d", i+1, len(voices))
+		}
+
+		w.WriteResponse(chat.MsgA(content.Voice{Data: audio, Caption: caption}))
+	}
+}
+
+// speechText убирает из ответа разметку, которую не нужно произносить.
+func speechText(s string) string {
+	s = speechTags.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = speechLinks.ReplaceAllString(s, "$1")
+	s = speechHeadings.ReplaceAllString(s, "")
+	s = speechBullets.ReplaceAllString(s, "")
+	s = speechMarkup.ReplaceAllString(s, "")
+
+	return strings.TrimSpace(s)
+}
+
+// splitSpeech делит текст на части не длиннее limit символов по границам
+// предложений, а слишком длинные предложения — по словам.
+func splitSpeech(s string, limit int) []string {
+	var (
+		chunks  []string
+		current strings.Builder
+	)
+
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+	}
+
+	add := func(part string) {
+		if utf8.RuneCountInString(current.String())+utf8.RuneCountInString(part) > limit {
+			flush()
+		}
+		current.WriteString(part)
+	}
+
+	for _, sentence := range speechSentence.FindAllString(s, -1) {
+		if utf8.RuneCountInString(sentence) <= limit {
+			add(sentence)
+			continue
+		}
+
+		for _, word := range strings.Fields(sentence) {
+			for utf8.RuneCountInString(word) > limit {
+				runes := []rune(word)
+				add(string(runes[:limit]))
+				word = string(runes[limit:])
+			}
+			add(word + " ")
+		}
+	}
+	flush()
+
+	return chunks
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/mygenetics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// watchCodelabs проверяет анализы пользователя и сообщает о новых анализах
+// и изменении статуса. При первой проверке только запоминает анализы, чтобы
+// не сообщать о давно известных результатах.
+func watchCodelabs() server.Handler {
+	return server.HandlerFunc(
+		func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+			if r.From.State != chat.UserStateAuthorized {
+				return
+			}
+
+			access, err := renewTokens(ctx, r)
+			if err != nil {
+				r.Log.Printf("failed to renew tokens for watch (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			codelabs, err := mygenetics.DefaultClient.FetchCodelabs(ctx, access)
+			if err != nil {
+				r.Log.Printf("failed to fetch codelabs for watch (chatID: %d): %v", r.ChatID, err)
+				return
+			}
+
+			if !r.From.CodelabsCheckedAt.IsZero() {
+				known := make(map[string]bool, len(r.From.Codelabs))
+				for _, codelab := range r.From.Codelabs {
+					known[codelab.Code] = true
+				}
+
+				for _, codelab := range mygenetics.ChangedCodelabs(r.From.Codelabs, codelabs) {
+					header := tr(r, "notify.codelab.status", codelab.Name, codelab.Code, codelabStatus(r, codelab))
+					if !known[codelab.Code] {
+						header = tr(r, "notify.codelab.new", codelab.Name, codelab.Code, codelabStatus(r, codelab))
+					}
+
+					w.WriteResponse(chat.MsgA(codelabNotice(r, header, codelab.Code)))
+				}
+			}
+
+			// Токены сохранены при обновлении, поэтому меняются только анализы.
+			if err := updateUser(ctx, r, func(user *chat.User) {
+				user.Codelabs = codelabs
+				user.CodelabsCheckedAt = chat.Now().UTC()
+			}); err != nil {
+				r.Log.Printf("failed to save user (chatID: %d): %v", r.ChatID, err)
+			}
+		},
+	)
+}
+
+// codelabStatus описывает статус анализа на языке пользователя.
+func codelabStatus(r *server.Request, codelab mygenetics.Codelab) string {
+	status := codelab.Status
+	if status == "" {
+		status = tr(r, "notify.codelab.status_unknown")
+	}
+
+	if !codelab.Activated {
+		return tr(r, "notify.codelab.not_activated", status)
+	}
+
+	return status
+}
+
+// codelabNotice возвращает уведомление об анализе с кнопками для просмотра
+// отчета и интерпретации ИИ.
+func codelabNotice(r *server.Request, header, code string) content.Select {
+	return content.Select{
+		Header: header,
+		Items: []content.SelectItem{
+			{Caption: tr(r, "notify.codelab.open"), Data: PrefixCodelab + code},
+			{Caption: tr(r, "notify.codelab.ai"), Data: PrefixAI + code},
+		},
+	}
+}
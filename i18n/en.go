@@ -0,0 +1,359 @@
+package i18n
+
+var english = map[string]Message{
+	// Errors.
+	"error.chat_state.get":    {Other: "⚠️ Failed to read the chat state: %v"},
+	"error.chat_state.change": {Other: "⚠️ Failed to change the chat state: %v"},
+	"error.chat_state.save":   {Other: "⚠️ Failed to save the chat state: %v"},
+	"error.history.get":       {Other: "⚠️ Failed to read the chat history: %v"},
+	"error.history.save":      {Other: "⚠️ Failed to save the chat history: %v"},
+	"error.plan.get":          {Other: "⚠️ Failed to read the plan: %v"},
+	"error.plan.save":         {Other: "⚠️ Failed to save the plan: %v"},
+	"error.diary.get":         {Other: "⚠️ Failed to read the food diary: %v"},
+	"error.diary.save":        {Other: "⚠️ Failed to save the food diary: %v"},
+	"error.journal.get":       {Other: "⚠️ Failed to read the wellbeing journal: %v"},
+	"error.journal.save":      {Other: "⚠️ Failed to save the wellbeing journal: %v"},
+	"error.feedback.save":     {Other: "⚠️ Failed to save the rating: %v"},
+	"error.user.save":         {Other: "⛔ Failed to save the user: %v"},
+	"error.settings.save":     {Other: "⚠️ Failed to save the settings: %v"},
+	"error.completion":        {Other: "⛔ Failed to generate a reply: %v"},
+	"error.parse":             {Other: "⛔ Failed to parse the reply: %v"},
+	"error.report":            {Other: "⚠️ Failed to build the report: %v"},
+	"error.prompt_not_found":  {Other: "⛔ Prompt not found."},
+	"error.unauthorized":      {Other: "⛔ You are not signed in."},
+	"error.unknown_command": {
+		Other: "⛔ Unknown command. Please choose an action from the list.",
+	},
+	"error.unsupported_type": {Other: "❌ This message type is not supported."},
+	"error.transcription": {
+		Other: "❌ Failed to recognize the voice message. Please try again or type your question.",
+	},
+
+	// Authentication.
+	"auth.required": {
+		Other: "⚠️ Please sign in to access your tests. Send your email and password.",
+	},
+	"auth.failed":     {Other: "⛔ MyGenetics authentication failed: %v"},
+	"auth.hello":      {Other: "Hello."},
+	"auth.invalid":    {Other: "❌ The email or password is incorrect. Please try again."},
+	"auth.success":    {Other: "✅ You have signed in successfully! Thank you."},
+	"auth.signed_out": {Other: "👋 You have signed out. See you soon!"},
+
+	// Commands.
+	"command.begin":         {Other: "Start talking to the bot"},
+	"command.start":         {Other: "Start a new dialog and show options"},
+	"command.clear":         {Other: "Start a new session (forget the dialog)"},
+	"command.mygenetics":    {Other: "Show your tests"},
+	"command.mygenetics_ai": {Other: "Show your tests with AI interpretation"},
+	"command.voice":         {Other: "Turn voice replies on or off"},
+	"command.language":      {Other: "Choose a language"},
+	"command.export":        {Other: "Download a test report"},
+	"command.compare":       {Other: "Compare two tests"},
+	"command.find":          {Other: "Find a feature or a gene in a test"},
+	"command.plan":          {Other: "Action plan based on your tests"},
+	"command.diary":         {Other: "Food diary: log a meal or get a summary"},
+	"command.journal":       {Other: "Wellbeing journal: sleep, energy, mood, weight and symptoms"},
+	"command.profile":       {Other: "Health questionnaire"},
+	"command.notifications": {Other: "Set up reminders and notifications"},
+	"command.exit":          {Other: "Sign out"},
+
+	// Greetings and session.
+	"greetings.welcome": {
+		Other: "👋 Welcome! Choose a test from the list to get its interpretation " +
+			"by artificial intelligence. You can also ask questions about your tests.",
+	},
+	"clear.done": {Other: "🧹 Chat history cleared."},
+
+	// Codelabs.
+	"codelabs.fetch_failed": {
+		Other: "⚠️ Failed to load your tests. Please try again later or contact support.",
+	},
+	"codelabs.empty": {
+		Other: "⚠️ You have no tests yet. New results will appear here automatically.",
+	},
+	"codelabs.select": {Other: "🧪 Choose a test to see its detailed results:"},
+	"codelabs.select_ai": {
+		Other: "🧪 Choose a test to get a detailed AI interpretation of its results:",
+	},
+	"codelab.unknown_prefix": {Other: "⛔ Unknown prefix: %s."},
+	"codelab.loading":        {Other: "🔍 Loading the results of test %s. This takes a few seconds..."},
+	"codelab.fetch_failed": {
+		Other: "⚠️ Failed to load the test. Please try again later or contact support.",
+	},
+	"codelab.loaded": {
+		One:   "📑 Loaded %d test parameter. Processing...",
+		Other: "📑 Loaded %d test parameters. Processing...",
+	},
+	"codelab.analyzing": {Other: "⌛ Analyzing the results with AI. This may take up to a minute..."},
+	"codelab.ai_failed": {
+		Other: "⚠️ Failed to interpret the results. " +
+			"Please try again later or view the results without AI.",
+	},
+
+	// Chat with the assistant.
+	"chat.text_required": {Other: "⛔ Please send a text message."},
+	"chat.fetch_failed": {
+		Other: "⚠️ Failed to load your tests. Please try again later or contact support.",
+	},
+	"chat.no_codelabs": {
+		Other: "⚠️ You have no tests yet. Please upload your tests to start the conversation.",
+	},
+	"chat.select_codelab": {
+		Other: "🧬 You have several tests. Please choose one. The assistant will use it " +
+			"until the choice is reset (automatically or with the /clear command).",
+	},
+	"chat.invalid_codelab": {Other: "⛔ Invalid test format. Please choose an option from the list."},
+	"chat.expired":         {Other: "⛔ The message has expired."},
+	"chat.features_failed": {Other: "⚠️ Failed to load the results of test %s: %v"},
+	"chat.context": {
+		Other: "Use the following genetic test data to answer my questions:\n\n%s\n\n" +
+			"Now I will ask questions based on this data.",
+	},
+	"chat.confirmation": {
+		Other: "I have studied the provided genetic data. " +
+			"Now I am ready to answer your questions based on it.",
+	},
+	"chat.thinking": {Other: "🤔 Analyzing your question..."},
+	"chat.failed": {
+		Other: "⚠️ Failed to get a reply. Please try again later or rephrase the question.",
+	},
+	"chat.answer": {Other: "🧠 Here is what the data of test %s shows.\n\n%s"},
+
+	// Voice replies.
+	"voice.unavailable": {Other: "🔇 Voice replies are not available in this chat."},
+	"voice.enabled": {
+		Other: "🔊 Voice replies are on. The assistant will answer with voice messages. " +
+			"Send /voice again to turn them off.",
+	},
+	"voice.disabled": {Other: "🔇 Voice replies are off."},
+
+	// Language.
+	"language.select":      {Other: "🌐 Choose a language:"},
+	"language.set":         {Other: "✅ Interface language: English."},
+	"language.unsupported": {Other: "⛔ Language %q is not supported. Available languages: %s."},
+
+	// Report export.
+	"export.select_codelab": {Other: "📄 Choose a test for the report:"},
+	"export.select_format":  {Other: "📄 Choose the report format for test %s:"},
+	"export.with_ai":        {Other: "%s + AI"},
+	"export.preparing":      {Other: "⏳ Preparing the report. This may take up to a minute..."},
+	"export.invalid":        {Other: "⛔ Invalid report format. Please choose an option from the list."},
+	"export.failed": {
+		Other: "⚠️ Failed to build the report. Please try again later or choose another format.",
+	},
+	"export.title":          {Other: "Genetic report"},
+	"export.subtitle":       {Other: "Test %s, %s"},
+	"export.date_layout":    {Other: "Jan 2, 2006"},
+	"export.interpretation": {Other: "AI interpretation"},
+	"export.note": {
+		Other: "This report is for information only and is not a medical diagnosis. " +
+			"Discuss the results with your doctor.",
+	},
+	"export.caption": {Other: "📄 Report for test %s"},
+
+	// Comparison of codelabs.
+	"compare.not_enough":    {Other: "⚠️ At least two tests are needed for a comparison."},
+	"compare.select_first":  {Other: "⚖️ Choose the first test to compare:"},
+	"compare.select_second": {Other: "⚖️ Choose the test to compare %s with:"},
+	"compare.loading":       {Other: "🔍 Comparing tests %s and %s..."},
+	"compare.invalid":       {Other: "⛔ Invalid comparison format. Please choose tests from the list."},
+	"compare.ai_offer":      {Other: "🤖 Would you like the AI to explain the differences?"},
+	"compare.ai":            {Other: "Comment with AI"},
+
+	// Search in codelabs.
+	"find.usage": {
+		Other: "🔎 Send /find with a query: a feature, gene or rsID. " +
+			"You can add filters category:nutrition, category:sport, category:metabolism " +
+			"or risk:high, risk:moderate. Or choose a ready filter:",
+	},
+	"find.category.nutrition":  {Other: "🍎 Nutrition"},
+	"find.category.sport":      {Other: "🏃 Sport"},
+	"find.category.metabolism": {Other: "🔥 Metabolism"},
+	"find.risk.high":           {Other: "⚠️ High risk"},
+	"find.risk.moderate":       {Other: "⚠️ Moderate risk and above"},
+	"find.select_codelab":      {Other: "🔎 Choose a test to search in:"},
+	"find.invalid":             {Other: "⛔ Invalid search format. Please choose an option from the list."},
+	"find.expired":             {Other: "⛔ The search has expired. Please repeat the query."},
+	"find.nothing":             {Other: "🔎 Nothing found for “%s” in test %s."},
+	"find.page":                {Other: "📑 Features found: %d. Page %d of %d."},
+	"find.prev":                {Other: "◀️ Back"},
+	"find.next":                {Other: "Next ▶️"},
+
+	// Action plan.
+	"plan.empty": {
+		Other: "📋 Your action plan is empty. Choose a test and I will build a plan " +
+			"from its recommendations:",
+	},
+	"plan.select_codelab": {Other: "📋 Choose a test to add its recommendations to the plan:"},
+	"plan.progress":       {Other: "📋 Your action plan: %d of %d done. Tap an item to mark it:"},
+	"plan.add":            {Other: "➕ Add from a test"},
+	"plan.added": {
+		One:   "✅ Added %d item from test %s.",
+		Other: "✅ Added %d items from test %s.",
+	},
+	"plan.nothing_added":  {Other: "📋 All recommendations of test %s are already in the plan."},
+	"plan.task_not_found": {Other: "⛔ The item is not in the plan. Open the plan again with /plan."},
+	"plan.done":           {Other: "✅ Done: %s"},
+	"plan.undone":         {Other: "⬜ Unmarked: %s"},
+	"plan.context.done":   {Other: "From my action plan I have already done:\n%s"},
+	"plan.context.todo":   {Other: "Not done yet:\n%s"},
+
+	// Notifications.
+	"notifications.header":           {Other: "🔔 Notifications. Tap to turn on or off:"},
+	"notifications.kind.reminder":    {Other: "Plan reminders"},
+	"notifications.kind.codelab":     {Other: "New test results"},
+	"notifications.kind.tip":         {Other: "Weekly tips"},
+	"notifications.enabled":          {Other: "🔔 Notifications on: %s."},
+	"notifications.disabled":         {Other: "🔕 Notifications off: %s."},
+	"notifications.quiet":            {Other: "🌙 Quiet hours: %s"},
+	"notifications.quiet.none":       {Other: "not set"},
+	"notifications.quiet.select":     {Other: "🌙 Notifications are postponed until quiet hours end. Choose the time:"},
+	"notifications.quiet.set":        {Other: "🌙 Quiet hours: %s."},
+	"notifications.quiet.invalid":    {Other: "⛔ Invalid quiet hours. Choose an option from the list."},
+	"notifications.timezone":         {Other: "🌍 Time zone: %s"},
+	"notifications.timezone.server":  {Other: "server time"},
+	"notifications.timezone.select":  {Other: "🌍 Quiet hours follow your time zone. Choose the offset from UTC:"},
+	"notifications.timezone.set":     {Other: "🌍 Time zone: %s."},
+	"notifications.timezone.invalid": {Other: "⛔ Invalid time zone. Choose an option from the list."},
+	"notify.reminder": {
+		One:   "⏰ Your plan has %d unfinished item. Mark it if you have done it:",
+		Other: "⏰ Your plan has %d unfinished items. Mark the ones you have done:",
+	},
+	"notify.codelab.new":            {Other: "🧬 New test %s (%s), status: %s."},
+	"notify.codelab.status":         {Other: "🧬 Status of test %s (%s) changed: %s."},
+	"notify.codelab.status_unknown": {Other: "unknown"},
+	"notify.codelab.not_activated":  {Other: "%s, the kit is not activated"},
+	"notify.codelab.open":           {Other: "📑 Open the report"},
+	"notify.codelab.ai":             {Other: "🤖 AI interpretation"},
+	"notify.tip":                    {Other: "💡 Tip of the week — %s:\n\n%s"},
+
+	// Health questionnaire.
+	"profile.intro":                    {Other: "👤 Answer a few questions about yourself so that recommendations take your age, lifestyle and restrictions into account. You can skip any question."},
+	"profile.offer":                    {Other: "👤 Fill in a short health questionnaire to get more accurate recommendations."},
+	"profile.fill":                     {Other: "📝 Fill in the questionnaire"},
+	"profile.later":                    {Other: "⏭ Later"},
+	"profile.postponed":                {Other: "👌 OK. You can fill in the questionnaire at any time with /profile."},
+	"profile.step":                     {Other: "(%d/%d) %s"},
+	"profile.current":                  {Other: "Current answer: %s"},
+	"profile.skip":                     {Other: "⏭ Skip"},
+	"profile.reset":                    {Other: "🗑 Delete the answer"},
+	"profile.summary":                  {Other: "👤 Your questionnaire:\n%s\n\nTap to change an answer:"},
+	"profile.edit":                     {Other: "✏️ %s"},
+	"profile.refill":                   {Other: "🔄 Fill in again"},
+	"profile.no_answer":                {Other: "not specified"},
+	"profile.bmi":                      {Other: "Body mass index: %.1f"},
+	"profile.done":                     {Other: "✅ The questionnaire is complete. I will take it into account in answers and recommendations."},
+	"profile.saved":                    {Other: "✅ The questionnaire is updated."},
+	"profile.context":                  {Other: "My questionnaire (take it into account in recommendations):\n%s"},
+	"profile.invalid.age":              {Other: "⛔ Enter your age as a whole number from 1 to 120."},
+	"profile.invalid.height":           {Other: "⛔ Enter your height in centimeters, from 50 to 250."},
+	"profile.invalid.weight":           {Other: "⛔ Enter your weight in kilograms, from 20 to 350."},
+	"profile.invalid.option":           {Other: "⛔ Choose an option from the list."},
+	"profile.invalid.text":             {Other: "⛔ The answer is too long. Please keep it under 500 characters."},
+	"profile.question.age":             {Other: "How old are you?"},
+	"profile.question.sex":             {Other: "What is your sex?"},
+	"profile.question.height":          {Other: "What is your height in centimeters?"},
+	"profile.question.weight":          {Other: "What is your weight in kilograms?"},
+	"profile.question.activity":        {Other: "What is your physical activity level?"},
+	"profile.question.diet":            {Other: "What is your diet?"},
+	"profile.question.allergies":       {Other: "Do you have any allergies or food intolerances? List them."},
+	"profile.question.goals":           {Other: "What goals do you want to achieve (for example, lose weight, sleep better)?"},
+	"profile.question.conditions":      {Other: "Do you have any chronic conditions? List them."},
+	"profile.field.age":                {Other: "Age"},
+	"profile.field.sex":                {Other: "Sex"},
+	"profile.field.height":             {Other: "Height, cm"},
+	"profile.field.weight":             {Other: "Weight, kg"},
+	"profile.field.activity":           {Other: "Physical activity"},
+	"profile.field.diet":               {Other: "Diet"},
+	"profile.field.allergies":          {Other: "Allergies"},
+	"profile.field.goals":              {Other: "Goals"},
+	"profile.field.conditions":         {Other: "Chronic conditions"},
+	"profile.option.sex.male":          {Other: "Male"},
+	"profile.option.sex.female":        {Other: "Female"},
+	"profile.option.activity.low":      {Other: "Low"},
+	"profile.option.activity.moderate": {Other: "Moderate"},
+	"profile.option.activity.high":     {Other: "High"},
+	"profile.option.diet.omnivore":     {Other: "No restrictions"},
+	"profile.option.diet.vegetarian":   {Other: "Vegetarian"},
+	"profile.option.diet.vegan":        {Other: "Vegan"},
+	"profile.option.diet.other":        {Other: "Other"},
+
+	// Food diary.
+	"diary.today_empty":       {Other: "🍽 Your food diary has no entries for today yet. Describe what you ate or send a photo of your food."},
+	"diary.today_empty.text":  {Other: "🍽 Your food diary has no entries for today yet. Describe what you ate."},
+	"diary.today":             {Other: "🍽 Food diary for today:\n%s\n\nTotal: ~%d kcal."},
+	"diary.add":               {Other: "➕ Log a meal"},
+	"diary.summary.day":       {Other: "📊 Daily summary"},
+	"diary.summary.week":      {Other: "📆 Weekly summary"},
+	"diary.ask":               {Other: "🍽 Describe what you ate (for example, \"oatmeal with milk and coffee\") or send a photo of your food."},
+	"diary.ask.text":          {Other: "🍽 Describe what you ate (for example, \"oatmeal with milk and coffee\")."},
+	"diary.failed":            {Other: "⚠️ Failed to recognize the meal. Try describing it in text."},
+	"diary.no_food":           {Other: "🤔 I found no food in the message. Describe what you ate or send a photo of your food."},
+	"diary.no_food.text":      {Other: "🤔 I found no food in the message. Describe what you ate."},
+	"diary.photo_unsupported": {Other: "📷 The current AI model does not recognize photos. Describe what you ate in text."},
+	"diary.added":             {Other: "🍽 Logged to the diary:\n%s\n\nTotal for today: ~%d kcal."},
+	"diary.calories":          {Other: "~%d kcal"},
+	"diary.period.day":        {Other: "today"},
+	"diary.period.week":       {Other: "the last 7 days"},
+	"diary.empty":             {Other: "🍽 Your food diary has no entries for %s. Log a meal with /diary."},
+	"diary.stats":             {Other: "📊 Nutrition for %s:\n%s"},
+	"diary.stats.meals":       {Other: "• Meals: %d (days with entries: %d)"},
+	"diary.stats.calories":    {Other: "• Daily average: ~%d kcal"},
+	"diary.stats.tag":         {Other: "• %s: %d"},
+	"diary.tag.lactose":       {Other: "Foods with lactose"},
+	"diary.tag.caffeine":      {Other: "Foods with caffeine"},
+	"diary.tag.saturated_fat": {Other: "Foods with saturated fat"},
+	"diary.tag.gluten":        {Other: "Foods with gluten"},
+	"diary.tag.sugar":         {Other: "Foods with added sugar"},
+	"diary.tag.salt":          {Other: "Salty foods"},
+	"diary.tag.alcohol":       {Other: "Alcohol"},
+	"diary.context.meal":      {Other: "Meal: %s"},
+	"diary.context.photo":     {Other: "The meal is in the photo."},
+	"diary.context.nutrition": {Other: "Nutrition recommendations from my genetic test:\n%s"},
+	"diary.context.summary":   {Other: "My food diary for %s:\n\n%s"},
+
+	// Wellbeing journal.
+	"journal.empty":                {Other: "📝 Your wellbeing journal has no entries for the last week yet. Log sleep, energy, mood, weight and symptoms to see how they change."},
+	"journal.trends":               {Other: "📝 Wellbeing over the last 7 days (previous 7 days in brackets):\n%s"},
+	"journal.start":                {Other: "📝 Log wellbeing"},
+	"journal.question.sleep":       {Other: "😴 How long did you sleep last night?"},
+	"journal.question.energy":      {Other: "⚡️ Rate your energy from 1 to 5."},
+	"journal.question.mood":        {Other: "🙂 Rate your mood from 1 to 5."},
+	"journal.question.weight":      {Other: "⚖️ Write your weight in kilograms, e.g. 72.5."},
+	"journal.question.symptom":     {Other: "🤒 Mark any symptoms you had today and press \"Done\"."},
+	"journal.current":              {Other: "Last weight: %s."},
+	"journal.skip":                 {Other: "⏭ Skip"},
+	"journal.done":                 {Other: "✅ Done"},
+	"journal.saved":                {Other: "✅ Wellbeing saved to the journal."},
+	"journal.invalid.weight":       {Other: "⚠️ Write your weight as a number from 20 to 350 kg."},
+	"journal.trend":                {Other: "• %s: %s"},
+	"journal.trend.previous":       {Other: "%s (was %s)"},
+	"journal.symptoms":             {Other: "• Symptoms: %s"},
+	"journal.symptom.count":        {Other: "%s — %d"},
+	"journal.metric.sleep":         {Other: "Sleep"},
+	"journal.metric.energy":        {Other: "Energy"},
+	"journal.metric.mood":          {Other: "Mood"},
+	"journal.metric.weight":        {Other: "Weight"},
+	"journal.value.sleep":          {Other: "%s h"},
+	"journal.value.energy":         {Other: "%s/5"},
+	"journal.value.mood":           {Other: "%s/5"},
+	"journal.value.weight":         {Other: "%s kg"},
+	"journal.symptom.headache":     {Other: "Headache"},
+	"journal.symptom.fatigue":      {Other: "Fatigue"},
+	"journal.symptom.bloating":     {Other: "Bloating"},
+	"journal.symptom.heartburn":    {Other: "Heartburn"},
+	"journal.symptom.palpitations": {Other: "Palpitations"},
+	"journal.symptom.joint_pain":   {Other: "Joint pain"},
+	"journal.context":              {Other: "My wellbeing journal for the last 7 days (averages; the arrow and the value in brackets show the change compared to the previous 7 days):\n%s"},
+
+	// Answer feedback.
+	"feedback.header":        {Other: "Rate the answer:"},
+	"feedback.up":            {Other: "👍"},
+	"feedback.down":          {Other: "👎"},
+	"feedback.inaccurate":    {Other: "⚠️ Inaccurate"},
+	"feedback.thanks":        {Other: "🙏 Thanks for the rating! It helps improve the answers."},
+	"feedback.ask_comment":   {Other: "✍️ What is inaccurate in the answer? Describe it briefly in one message — it will help fix the mistake."},
+	"feedback.comment_saved": {Other: "🙏 Thanks, your comment has been saved."},
+	"feedback.already":       {Other: "You have already rated this answer."},
+	"feedback.unknown":       {Other: "⚠️ The answer was not found and cannot be rated."},
+}
@@ -0,0 +1,168 @@
+// Package i18n provides the catalogue of user-facing messages in supported
+// languages with plural forms.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lang is a supported language identified by its ISO 639-1 code.
+type Lang string
+
+// Supported languages.
+const (
+	Russian Lang = "ru"
+	English Lang = "en"
+)
+
+// Default is used when the user's language is not supported.
+const Default = Russian
+
+// languages lists supported languages in display order.
+var languages = []Lang{Russian, English}
+
+// names holds language names in the language itself.
+var names = map[Lang]string{
+	Russian: "Русский",
+	English: "English",
+}
+
+// Message is a translated message. Simple messages only set Other;
+// messages depending on a count set the plural forms of the language.
+type Message struct {
+	One   string // 1, 21, 31 in Russian; 1 in English.
+	Few   string // 2-4, 22-24 in Russian.
+	Many  string // 0, 5-20, 25-30 in Russian.
+	Other string // Everything else, also the fallback for missing forms.
+}
+
+// catalogue holds messages by language and message ID.
+var catalogue = map[Lang]map[string]Message{
+	Russian: russian,
+	English: english,
+}
+
+// Languages returns supported languages.
+func Languages() []Lang {
+	return append([]Lang(nil), languages...)
+}
+
+// Parse returns the supported language for a code like "en", "en-US" or an
+// Accept-Language header value (only the first language is considered) and
+// false if the language is not supported.
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_,;"); i != -1 {
+		code = code[:i]
+	}
+
+	lang := Lang(code)
+	if _, ok := catalogue[lang]; !ok {
+		return Default, false
+	}
+
+	return lang, true
+}
+
+// Choose returns the first supported language among codes, skipping empty
+// ones, or Default if none is supported. Codes are parsed like Parse.
+func Choose(codes ...string) Lang {
+	for _, code := range codes {
+		if lang, ok := Parse(code); ok {
+			return lang
+		}
+	}
+
+	return Default
+}
+
+// Name returns the language name in the language itself.
+func (l Lang) Name() string {
+	if name, ok := names[l]; ok {
+		return name
+	}
+
+	return string(l)
+}
+
+// T returns the translated message formatted with args.
+func T(lang Lang, id string, args ...any) string {
+	return format(lookup(lang, id).Other, id, args)
+}
+
+// N returns the plural form of the translated message for count n,
+// formatted with args. The count itself is not passed to the format.
+func N(lang Lang, id string, n int, args ...any) string {
+	msg := lookup(lang, id)
+
+	var form string
+	switch plural(lang, n) {
+	case formOne:
+		form = msg.One
+	case formFew:
+		form = msg.Few
+	case formMany:
+		form = msg.Many
+	}
+	if form == "" {
+		form = msg.Other
+	}
+
+	return format(form, id, args)
+}
+
+// lookup finds the message in the language, then in the default language.
+func lookup(lang Lang, id string) Message {
+	if msg, ok := catalogue[lang][id]; ok {
+		return msg
+	}
+
+	return catalogue[Default][id]
+}
+
+func format(s, id string, args []any) string {
+	if s == "" {
+		return id
+	}
+
+	if len(args) == 0 {
+		return s
+	}
+
+	return fmt.Sprintf(s, args...)
+}
+
+type form int
+
+const (
+	formOther form = iota
+	formOne
+	formFew
+	formMany
+)
+
+// plural returns the CLDR plural category of n for the language.
+func plural(lang Lang, n int) form {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case Russian:
+		switch mod10, mod100 := n%10, n%100; {
+		case mod10 == 1 && mod100 != 11:
+			return formOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return formFew
+		default:
+			return formMany
+		}
+
+	default:
+		if n == 1 {
+			return formOne
+		}
+		return formOther
+	}
+}
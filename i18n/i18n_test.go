@@ -0,0 +1,97 @@
+package i18n
+
+import "testing"
+
+func TestCatalogueComplete(t *testing.T) {
+	for _, lang := range Languages() {
+		for id := range catalogue[Default] {
+			if _, ok := catalogue[lang][id]; !ok {
+				t.Errorf("%s: message %q is missing", lang, id)
+			}
+		}
+
+		for id := range catalogue[lang] {
+			if _, ok := catalogue[Default][id]; !ok {
+				t.Errorf("%s: message %q is not in the default catalogue", lang, id)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code string
+		want Lang
+		ok   bool
+	}{
+		{"ru", Russian, true},
+		{"en", English, true},
+		{"en-US", English, true},
+		{" EN_gb ", English, true},
+		{"en,ru;q=0.9", English, true},
+		{"de", Default, false},
+		{"", Default, false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := Parse(tt.code); got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestN(t *testing.T) {
+	tests := []struct {
+		lang Lang
+		n    int
+		want string
+	}{
+		{Russian, 1, "📑 Загружен 1 параметр анализа. Приступаю к обработке..."},
+		{Russian, 21, "📑 Загружен 21 параметр анализа. Приступаю к обработке..."},
+		{Russian, 3, "📑 Загружено 3 параметра анализа. Приступаю к обработке..."},
+		{Russian, 11, "📑 Загружено 11 параметров анализа. Приступаю к обработке..."},
+		{Russian, 14, "📑 Загружено 14 параметров анализа. Приступаю к обработке..."},
+		{Russian, 0, "📑 Загружено 0 параметров анализа. Приступаю к обработке..."},
+		{English, 1, "📑 Loaded 1 test parameter. Processing..."},
+		{English, 2, "📑 Loaded 2 test parameters. Processing..."},
+	}
+
+	for _, tt := range tests {
+		if got := N(tt.lang, "codelab.loaded", tt.n, tt.n); got != tt.want {
+			t.Errorf("N(%s, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestChoose(t *testing.T) {
+	tests := []struct {
+		codes []string
+		want  Lang
+	}{
+		{[]string{"", "en-US"}, English},
+		{[]string{"ru", "en"}, Russian},
+		{[]string{"de", "en"}, English},
+		{[]string{"de"}, Default},
+		{nil, Default},
+	}
+
+	for _, tt := range tests {
+		if got := Choose(tt.codes...); got != tt.want {
+			t.Errorf("Choose(%q) = %s, want %s", tt.codes, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(English, "auth.hello"); got != "Hello." {
+		t.Errorf("T() = %q", got)
+	}
+
+	if got := T(Lang("de"), "auth.hello"); got != "Привет." {
+		t.Errorf("unsupported language: T() = %q", got)
+	}
+
+	if got := T(English, "missing.id"); got != "missing.id" {
+		t.Errorf("missing message: T() = %q", got)
+	}
+}
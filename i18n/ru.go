@@ -0,0 +1,372 @@
+package i18n
+
+var russian = map[string]Message{
+	// Errors.
+	"error.chat_state.get":    {Other: "⚠️ Ошибка получения состояния чата: %v"},
+	"error.chat_state.change": {Other: "⚠️ Ошибка смены состояния чата: %v"},
+	"error.chat_state.save":   {Other: "⚠️ Ошибка сохранения состояния чата: %v"},
+	"error.history.get":       {Other: "⚠️ Ошибка получения истории чата: %v"},
+	"error.history.save":      {Other: "⚠️ Ошибка сохранения истории чата: %v"},
+	"error.plan.get":          {Other: "⚠️ Ошибка получения плана: %v"},
+	"error.plan.save":         {Other: "⚠️ Ошибка сохранения плана: %v"},
+	"error.diary.get":         {Other: "⚠️ Ошибка получения дневника питания: %v"},
+	"error.diary.save":        {Other: "⚠️ Ошибка сохранения дневника питания: %v"},
+	"error.journal.get":       {Other: "⚠️ Ошибка получения журнала самочувствия: %v"},
+	"error.journal.save":      {Other: "⚠️ Ошибка сохранения журнала самочувствия: %v"},
+	"error.feedback.save":     {Other: "⚠️ Ошибка сохранения оценки: %v"},
+	"error.user.save":         {Other: "⛔ Ошибка сохранения пользователя: %v"},
+	"error.settings.save":     {Other: "⚠️ Ошибка сохранения настроек: %v"},
+	"error.completion":        {Other: "⛔ Ошибка генерации ответа: %v"},
+	"error.parse":             {Other: "⛔ Ошибка парсинга ответа: %v"},
+	"error.report":            {Other: "⚠️ Ошибка формирования отчета: %v"},
+	"error.prompt_not_found":  {Other: "⛔ Промпт не найден."},
+	"error.unauthorized":      {Other: "⛔ Пользователь не авторизован."},
+	"error.unknown_command": {
+		Other: "⛔ Неизвестная команда. Пожалуйста, выберите действие из предложенного списка.",
+	},
+	"error.unsupported_type": {Other: "❌ Тип сообщения не поддерживается."},
+	"error.transcription": {
+		Other: "❌ Не удалось распознать голосовое сообщение. Попробуйте еще раз или напишите вопрос текстом.",
+	},
+
+	// Authentication.
+	"auth.required": {
+		Other: "⚠️ Для доступа к анализам необходимо авторизоваться. " +
+			"Пожалуйста, введите свой email и пароль.",
+	},
+	"auth.failed":     {Other: "⛔ Ошибка аутентификации mygenetics: %v"},
+	"auth.hello":      {Other: "Привет."},
+	"auth.invalid":    {Other: "❌ Имя пользователя или пароль не подходят. Попробуйте ещё раз."},
+	"auth.success":    {Other: "✅ Вы успешно вошли в систему! Благодарим за предоставленные данные."},
+	"auth.signed_out": {Other: "👋 Вы успешно вышли из системы. До новых встреч!"},
+
+	// Commands.
+	"command.begin":         {Other: "Начать общение с ботом"},
+	"command.start":         {Other: "Начать новый диалог и предложить варианты"},
+	"command.clear":         {Other: "Начать новую сессию (забыть диалог)"},
+	"command.mygenetics":    {Other: "Показать список анализов"},
+	"command.mygenetics_ai": {Other: "Показать список анализов с интерпретацией ИИ"},
+	"command.voice":         {Other: "Включить или выключить голосовые ответы"},
+	"command.language":      {Other: "Выбрать язык"},
+	"command.export":        {Other: "Скачать отчет по анализу"},
+	"command.compare":       {Other: "Сравнить два анализа"},
+	"command.find":          {Other: "Найти признак или ген в анализе"},
+	"command.plan":          {Other: "План действий по результатам анализов"},
+	"command.diary":         {Other: "Дневник питания: записать прием пищи или подвести итоги"},
+	"command.journal":       {Other: "Журнал самочувствия: сон, энергия, настроение, вес и симптомы"},
+	"command.profile":       {Other: "Анкета о здоровье"},
+	"command.notifications": {Other: "Настроить напоминания и уведомления"},
+	"command.exit":          {Other: "Выйти из аккаунта"},
+
+	// Greetings and session.
+	"greetings.welcome": {
+		Other: "👋 Добро пожаловать! Вы можете выбрать анализы из списка и получить " +
+			"их интерпретацию с помощью искусственного интеллекта. Также вы можете " +
+			"задавать вопросы относительно имеющихся анализов в базе.",
+	},
+	"clear.done": {Other: "🧹 История чата очищена."},
+
+	// Codelabs.
+	"codelabs.fetch_failed": {
+		Other: "⚠️ Не удалось загрузить список анализов. " +
+			"Пожалуйста, попробуйте позже или обратитесь в поддержку.",
+	},
+	"codelabs.empty": {
+		Other: "⚠️ У вас пока нет доступных анализов. " +
+			"Новые результаты появятся здесь автоматически.",
+	},
+	"codelabs.select": {Other: "🧪 Выберите анализ, чтобы просмотреть детальные результаты:"},
+	"codelabs.select_ai": {
+		Other: "🧪 Выберите анализ для получения развёрнутой интерпретации результатов с помощью ИИ:",
+	},
+	"codelab.unknown_prefix": {Other: "⛔ Неизвестный префикс: %s."},
+	"codelab.loading":        {Other: "🔍 Загружаю результаты анализа %s. Это займёт несколько секунд..."},
+	"codelab.fetch_failed": {
+		Other: "⚠️ Не удалось получить информацию об анализе. " +
+			"Пожалуйста, попробуйте позже или обратитесь в поддержку.",
+	},
+	"codelab.loaded": {
+		One:  "📑 Загружен %d параметр анализа. Приступаю к обработке...",
+		Few:  "📑 Загружено %d параметра анализа. Приступаю к обработке...",
+		Many: "📑 Загружено %d параметров анализа. Приступаю к обработке...",
+	},
+	"codelab.analyzing": {Other: "⌛ Анализирую результаты с помощью ИИ. Это может занять до минуты..."},
+	"codelab.ai_failed": {
+		Other: "⚠️ Не удалось получить интерпретацию результатов. " +
+			"Пожалуйста, попробуйте позже или просмотрите результаты без анализа ИИ.",
+	},
+
+	// Chat with the assistant.
+	"chat.text_required": {Other: "⛔ Пожалуйста, отправьте текстовое сообщение."},
+	"chat.fetch_failed": {
+		Other: "⚠️ Не удалось загрузить анализы. " +
+			"Пожалуйста, попробуйте позже или обратитесь в поддержку.",
+	},
+	"chat.no_codelabs": {
+		Other: "⚠️ У вас пока нет доступных анализов. " +
+			"Пожалуйста, загрузите анализы, чтобы начать общение.",
+	},
+	"chat.select_codelab": {
+		Other: "🧬 У вас несколько анализов. Пожалуйста, выберите один. " +
+			"Ассистент будет использовать его, пока выбор не сбросится " +
+			"(например, автоматически или командой /clear).",
+	},
+	"chat.invalid_codelab": {Other: "⛔ Неверный формат анализа. Пожалуйста, выберите ответ из списка."},
+	"chat.expired":         {Other: "⛔ Сообщение устарело."},
+	"chat.features_failed": {Other: "⚠️ Не удалось загрузить результаты анализа %s: %v"},
+	"chat.context": {
+		Other: "Следующие данные генетического анализа должны использоваться для ответа " +
+			"на мои вопросы:\n\n%s\n\nТеперь я буду задавать вопросы, опираясь на эти данные.",
+	},
+	"chat.confirmation": {
+		Other: "Я изучил предоставленные генетические данные. " +
+			"Теперь я готов ответить на ваши вопросы, опираясь на эту информацию.",
+	},
+	"chat.thinking": {Other: "🤔 Анализирую ваш вопрос..."},
+	"chat.failed": {
+		Other: "⚠️ Не удалось получить ответ. " +
+			"Пожалуйста, попробуйте позже или переформулируйте вопрос.",
+	},
+	"chat.answer": {Other: "🧠 Вот, что показывают данные из анализа %s.\n\n%s"},
+
+	// Voice replies.
+	"voice.unavailable": {Other: "🔇 Голосовые ответы недоступны в этом чате."},
+	"voice.enabled": {
+		Other: "🔊 Голосовые ответы включены. Ответы ассистента будут приходить " +
+			"голосовыми сообщениями. Чтобы выключить, снова отправьте /voice.",
+	},
+	"voice.disabled": {Other: "🔇 Голосовые ответы выключены."},
+
+	// Language.
+	"language.select":      {Other: "🌐 Выберите язык:"},
+	"language.set":         {Other: "✅ Язык интерфейса: русский."},
+	"language.unsupported": {Other: "⛔ Язык %q не поддерживается. Доступные языки: %s."},
+
+	// Report export.
+	"export.select_codelab": {Other: "📄 Выберите анализ для отчета:"},
+	"export.select_format":  {Other: "📄 Выберите формат отчета по анализу %s:"},
+	"export.with_ai":        {Other: "%s + ИИ"},
+	"export.preparing":      {Other: "⏳ Готовлю отчет. Это может занять до минуты..."},
+	"export.invalid":        {Other: "⛔ Неверный формат отчета. Пожалуйста, выберите вариант из списка."},
+	"export.failed": {
+		Other: "⚠️ Не удалось сформировать отчет. " +
+			"Пожалуйста, попробуйте позже или выберите другой формат.",
+	},
+	"export.title":          {Other: "Генетический отчет"},
+	"export.subtitle":       {Other: "Анализ %s, %s"},
+	"export.date_layout":    {Other: "02.01.2006"},
+	"export.interpretation": {Other: "Интерпретация ИИ"},
+	"export.note": {
+		Other: "Отчет носит информационный характер и не является медицинским заключением. " +
+			"Обсудите результаты с врачом.",
+	},
+	"export.caption": {Other: "📄 Отчет по анализу %s"},
+
+	// Comparison of codelabs.
+	"compare.not_enough":    {Other: "⚠️ Для сравнения нужно хотя бы два анализа."},
+	"compare.select_first":  {Other: "⚖️ Выберите первый анализ для сравнения:"},
+	"compare.select_second": {Other: "⚖️ Выберите анализ, с которым сравнить %s:"},
+	"compare.loading":       {Other: "🔍 Сравниваю анализы %s и %s..."},
+	"compare.invalid":       {Other: "⛔ Неверный формат сравнения. Пожалуйста, выберите анализы из списка."},
+	"compare.ai_offer":      {Other: "🤖 Хотите, чтобы ИИ объяснил различия?"},
+	"compare.ai":            {Other: "Прокомментировать с ИИ"},
+
+	// Search in codelabs.
+	"find.usage": {
+		Other: "🔎 Отправьте /find и запрос: название признака, гена или rsID. " +
+			"Можно добавить фильтры category:nutrition, category:sport, category:metabolism " +
+			"или risk:high, risk:moderate. Или выберите готовый фильтр:",
+	},
+	"find.category.nutrition":  {Other: "🍎 Питание"},
+	"find.category.sport":      {Other: "🏃 Спорт"},
+	"find.category.metabolism": {Other: "🔥 Метаболизм"},
+	"find.risk.high":           {Other: "⚠️ Высокий риск"},
+	"find.risk.moderate":       {Other: "⚠️ Умеренный риск и выше"},
+	"find.select_codelab":      {Other: "🔎 Выберите анализ для поиска:"},
+	"find.invalid":             {Other: "⛔ Неверный формат поиска. Пожалуйста, выберите вариант из списка."},
+	"find.expired":             {Other: "⛔ Поиск устарел. Пожалуйста, повторите запрос."},
+	"find.nothing":             {Other: "🔎 По запросу «%s» в анализе %s ничего не найдено."},
+	"find.page":                {Other: "📑 Найдено признаков: %d. Страница %d из %d."},
+	"find.prev":                {Other: "◀️ Назад"},
+	"find.next":                {Other: "Вперёд ▶️"},
+
+	// Action plan.
+	"plan.empty": {
+		Other: "📋 Ваш план действий пока пуст. Выберите анализ, и я составлю план " +
+			"из его рекомендаций:",
+	},
+	"plan.select_codelab": {Other: "📋 Выберите анализ, рекомендации которого добавить в план:"},
+	"plan.progress":       {Other: "📋 Ваш план действий: выполнено %d из %d. Нажмите на пункт, чтобы отметить его:"},
+	"plan.add":            {Other: "➕ Добавить из анализа"},
+	"plan.added": {
+		One:  "✅ Добавлен %d пункт из анализа %s.",
+		Few:  "✅ Добавлено %d пункта из анализа %s.",
+		Many: "✅ Добавлено %d пунктов из анализа %s.",
+	},
+	"plan.nothing_added":  {Other: "📋 Все рекомендации анализа %s уже есть в плане."},
+	"plan.task_not_found": {Other: "⛔ Пункт не найден в плане. Откройте план заново командой /plan."},
+	"plan.done":           {Other: "✅ Выполнено: %s"},
+	"plan.undone":         {Other: "⬜ Отметка снята: %s"},
+	"plan.context.done":   {Other: "Из плана действий я уже выполнил:\n%s"},
+	"plan.context.todo":   {Other: "Еще не выполнено:\n%s"},
+
+	// Notifications.
+	"notifications.header":           {Other: "🔔 Уведомления. Нажмите, чтобы включить или отключить:"},
+	"notifications.kind.reminder":    {Other: "Напоминания о плане"},
+	"notifications.kind.codelab":     {Other: "Новые результаты анализов"},
+	"notifications.kind.tip":         {Other: "Советы недели"},
+	"notifications.enabled":          {Other: "🔔 Уведомления включены: %s."},
+	"notifications.disabled":         {Other: "🔕 Уведомления отключены: %s."},
+	"notifications.quiet":            {Other: "🌙 Тихие часы: %s"},
+	"notifications.quiet.none":       {Other: "не заданы"},
+	"notifications.quiet.select":     {Other: "🌙 В тихие часы уведомления откладываются до утра. Выберите время:"},
+	"notifications.quiet.set":        {Other: "🌙 Тихие часы: %s."},
+	"notifications.quiet.invalid":    {Other: "⛔ Неверные тихие часы. Выберите вариант из списка."},
+	"notifications.timezone":         {Other: "🌍 Часовой пояс: %s"},
+	"notifications.timezone.server":  {Other: "как на сервере"},
+	"notifications.timezone.select":  {Other: "🌍 Тихие часы отсчитываются в вашем часовом поясе. Выберите смещение от UTC:"},
+	"notifications.timezone.set":     {Other: "🌍 Часовой пояс: %s."},
+	"notifications.timezone.invalid": {Other: "⛔ Неверный часовой пояс. Выберите вариант из списка."},
+	"notify.reminder": {
+		One:  "⏰ В вашем плане %d невыполненный пункт. Отметьте, если уже сделали:",
+		Few:  "⏰ В вашем плане %d невыполненных пункта. Отметьте, что уже сделали:",
+		Many: "⏰ В вашем плане %d невыполненных пунктов. Отметьте, что уже сделали:",
+	},
+	"notify.codelab.new":            {Other: "🧬 Новый анализ %s (%s), статус: %s."},
+	"notify.codelab.status":         {Other: "🧬 Изменился статус анализа %s (%s): %s."},
+	"notify.codelab.status_unknown": {Other: "неизвестен"},
+	"notify.codelab.not_activated":  {Other: "%s, набор не активирован"},
+	"notify.codelab.open":           {Other: "📑 Открыть отчет"},
+	"notify.codelab.ai":             {Other: "🤖 Интерпретация ИИ"},
+	"notify.tip":                    {Other: "💡 Совет недели — %s:\n\n%s"},
+
+	// Health questionnaire.
+	"profile.intro":                    {Other: "👤 Ответьте на несколько вопросов о себе, чтобы рекомендации учитывали ваш возраст, образ жизни и ограничения. Любой вопрос можно пропустить."},
+	"profile.offer":                    {Other: "👤 Заполните короткую анкету о здоровье, чтобы рекомендации были точнее."},
+	"profile.fill":                     {Other: "📝 Заполнить анкету"},
+	"profile.later":                    {Other: "⏭ Позже"},
+	"profile.postponed":                {Other: "👌 Хорошо. Заполнить анкету можно в любой момент командой /profile."},
+	"profile.step":                     {Other: "(%d/%d) %s"},
+	"profile.current":                  {Other: "Сейчас: %s"},
+	"profile.skip":                     {Other: "⏭ Пропустить"},
+	"profile.reset":                    {Other: "🗑 Удалить ответ"},
+	"profile.summary":                  {Other: "👤 Ваша анкета:\n%s\n\nНажмите, чтобы изменить ответ:"},
+	"profile.edit":                     {Other: "✏️ %s"},
+	"profile.refill":                   {Other: "🔄 Заполнить заново"},
+	"profile.no_answer":                {Other: "не указано"},
+	"profile.bmi":                      {Other: "Индекс массы тела: %.1f"},
+	"profile.done":                     {Other: "✅ Анкета заполнена. Я буду учитывать ее в ответах и рекомендациях."},
+	"profile.saved":                    {Other: "✅ Анкета обновлена."},
+	"profile.context":                  {Other: "Моя анкета (учитывай ее в рекомендациях):\n%s"},
+	"profile.invalid.age":              {Other: "⛔ Укажите возраст целым числом от 1 до 120."},
+	"profile.invalid.height":           {Other: "⛔ Укажите рост в сантиметрах, от 50 до 250."},
+	"profile.invalid.weight":           {Other: "⛔ Укажите вес в килограммах, от 20 до 350."},
+	"profile.invalid.option":           {Other: "⛔ Выберите вариант из списка."},
+	"profile.invalid.text":             {Other: "⛔ Слишком длинный ответ. Опишите короче, до 500 символов."},
+	"profile.question.age":             {Other: "Сколько вам лет?"},
+	"profile.question.sex":             {Other: "Укажите ваш пол:"},
+	"profile.question.height":          {Other: "Какой у вас рост в сантиметрах?"},
+	"profile.question.weight":          {Other: "Какой у вас вес в килограммах?"},
+	"profile.question.activity":        {Other: "Какой у вас уровень физической активности?"},
+	"profile.question.diet":            {Other: "Как вы питаетесь?"},
+	"profile.question.allergies":       {Other: "Есть ли у вас аллергии или непереносимость продуктов? Перечислите их."},
+	"profile.question.goals":           {Other: "Каких целей вы хотите достичь (например, снизить вес, лучше спать)?"},
+	"profile.question.conditions":      {Other: "Есть ли у вас хронические заболевания? Перечислите их."},
+	"profile.field.age":                {Other: "Возраст"},
+	"profile.field.sex":                {Other: "Пол"},
+	"profile.field.height":             {Other: "Рост, см"},
+	"profile.field.weight":             {Other: "Вес, кг"},
+	"profile.field.activity":           {Other: "Физическая активность"},
+	"profile.field.diet":               {Other: "Питание"},
+	"profile.field.allergies":          {Other: "Аллергии"},
+	"profile.field.goals":              {Other: "Цели"},
+	"profile.field.conditions":         {Other: "Хронические заболевания"},
+	"profile.option.sex.male":          {Other: "Мужской"},
+	"profile.option.sex.female":        {Other: "Женский"},
+	"profile.option.activity.low":      {Other: "Низкая"},
+	"profile.option.activity.moderate": {Other: "Умеренная"},
+	"profile.option.activity.high":     {Other: "Высокая"},
+	"profile.option.diet.omnivore":     {Other: "Без ограничений"},
+	"profile.option.diet.vegetarian":   {Other: "Вегетарианское"},
+	"profile.option.diet.vegan":        {Other: "Веганское"},
+	"profile.option.diet.other":        {Other: "Другое"},
+
+	// Food diary.
+	"diary.today_empty":       {Other: "🍽 В дневнике питания пока нет записей за сегодня. Опишите, что вы съели, или пришлите фото еды."},
+	"diary.today_empty.text":  {Other: "🍽 В дневнике питания пока нет записей за сегодня. Опишите, что вы съели."},
+	"diary.today":             {Other: "🍽 Дневник питания за сегодня:\n%s\n\nВсего: ~%d ккал."},
+	"diary.add":               {Other: "➕ Записать прием пищи"},
+	"diary.summary.day":       {Other: "📊 Итоги дня"},
+	"diary.summary.week":      {Other: "📆 Итоги недели"},
+	"diary.ask":               {Other: "🍽 Опишите, что вы съели (например, «овсянка на молоке и кофе»), или пришлите фото еды."},
+	"diary.ask.text":          {Other: "🍽 Опишите, что вы съели (например, «овсянка на молоке и кофе»)."},
+	"diary.failed":            {Other: "⚠️ Не удалось распознать прием пищи. Попробуйте описать его текстом."},
+	"diary.no_food":           {Other: "🤔 Не нашел еды в сообщении. Опишите, что вы съели, или пришлите фото еды."},
+	"diary.no_food.text":      {Other: "🤔 Не нашел еды в сообщении. Опишите, что вы съели."},
+	"diary.photo_unsupported": {Other: "📷 Текущая модель ИИ не распознает фото. Опишите, что вы съели, текстом."},
+	"diary.added":             {Other: "🍽 Записал в дневник:\n%s\n\nВсего за сегодня: ~%d ккал."},
+	"diary.calories":          {Other: "~%d ккал"},
+	"diary.period.day":        {Other: "сегодня"},
+	"diary.period.week":       {Other: "последние 7 дней"},
+	"diary.empty":             {Other: "🍽 В дневнике питания нет записей за %s. Запишите прием пищи командой /diary."},
+	"diary.stats":             {Other: "📊 Питание за %s:\n%s"},
+	"diary.stats.meals":       {Other: "• Приемов пищи: %d (дней с записями: %d)"},
+	"diary.stats.calories":    {Other: "• В среднем за день: ~%d ккал"},
+	"diary.stats.tag":         {Other: "• %s: %d"},
+	"diary.tag.lactose":       {Other: "Продукты с лактозой"},
+	"diary.tag.caffeine":      {Other: "Продукты с кофеином"},
+	"diary.tag.saturated_fat": {Other: "Продукты с насыщенными жирами"},
+	"diary.tag.gluten":        {Other: "Продукты с глютеном"},
+	"diary.tag.sugar":         {Other: "Продукты с добавленным сахаром"},
+	"diary.tag.salt":          {Other: "Соленые продукты"},
+	"diary.tag.alcohol":       {Other: "Алкоголь"},
+	"diary.context.meal":      {Other: "Прием пищи: %s"},
+	"diary.context.photo":     {Other: "Прием пищи на фото."},
+	"diary.context.nutrition": {Other: "Рекомендации по питанию из моего генетического анализа:\n%s"},
+	"diary.context.summary":   {Other: "Мой дневник питания за %s:\n\n%s"},
+
+	// Wellbeing journal.
+	"journal.empty":                {Other: "📝 В журнале самочувствия пока нет записей за последнюю неделю. Отмечайте сон, энергию, настроение, вес и симптомы, чтобы видеть, как они меняются."},
+	"journal.trends":               {Other: "📝 Самочувствие за последние 7 дней (в скобках — предыдущие 7 дней):\n%s"},
+	"journal.start":                {Other: "📝 Отметить самочувствие"},
+	"journal.question.sleep":       {Other: "😴 Сколько вы спали прошлой ночью?"},
+	"journal.question.energy":      {Other: "⚡️ Оцените уровень энергии от 1 до 5."},
+	"journal.question.mood":        {Other: "🙂 Оцените настроение от 1 до 5."},
+	"journal.question.weight":      {Other: "⚖️ Напишите ваш вес в килограммах, например 72,5."},
+	"journal.question.symptom":     {Other: "🤒 Отметьте симптомы, если они были сегодня, и нажмите «Готово»."},
+	"journal.current":              {Other: "Последний вес: %s."},
+	"journal.skip":                 {Other: "⏭ Пропустить"},
+	"journal.done":                 {Other: "✅ Готово"},
+	"journal.saved":                {Other: "✅ Самочувствие записано в журнал."},
+	"journal.invalid.weight":       {Other: "⚠️ Напишите вес числом от 20 до 350 кг."},
+	"journal.trend":                {Other: "• %s: %s"},
+	"journal.trend.previous":       {Other: "%s (было %s)"},
+	"journal.symptoms":             {Other: "• Симптомы: %s"},
+	"journal.symptom.count":        {Other: "%s — %d"},
+	"journal.metric.sleep":         {Other: "Сон"},
+	"journal.metric.energy":        {Other: "Энергия"},
+	"journal.metric.mood":          {Other: "Настроение"},
+	"journal.metric.weight":        {Other: "Вес"},
+	"journal.value.sleep":          {Other: "%s ч"},
+	"journal.value.energy":         {Other: "%s/5"},
+	"journal.value.mood":           {Other: "%s/5"},
+	"journal.value.weight":         {Other: "%s кг"},
+	"journal.symptom.headache":     {Other: "Головная боль"},
+	"journal.symptom.fatigue":      {Other: "Усталость"},
+	"journal.symptom.bloating":     {Other: "Вздутие"},
+	"journal.symptom.heartburn":    {Other: "Изжога"},
+	"journal.symptom.palpitations": {Other: "Сердцебиение"},
+	"journal.symptom.joint_pain":   {Other: "Боль в суставах"},
+	"journal.context":              {Other: "Мой журнал самочувствия за последние 7 дней (средние значения; стрелка и значение в скобках — изменение по сравнению с предыдущими 7 днями):\n%s"},
+
+	// Answer feedback.
+	"feedback.header":        {Other: "Оцените ответ:"},
+	"feedback.up":            {Other: "👍"},
+	"feedback.down":          {Other: "👎"},
+	"feedback.inaccurate":    {Other: "⚠️ Неточно"},
+	"feedback.thanks":        {Other: "🙏 Спасибо за оценку! Она помогает улучшать ответы."},
+	"feedback.ask_comment":   {Other: "✍️ Что в ответе неточно? Напишите коротко одним сообщением — это поможет исправить ошибку."},
+	"feedback.comment_saved": {Other: "🙏 Спасибо, пояснение сохранено."},
+	"feedback.already":       {Other: "Вы уже оценили этот ответ."},
+	"feedback.unknown":       {Other: "⚠️ Ответ не найден, оценить его не получится."},
+}
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
@@ -12,6 +13,7 @@ import (
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
 	"github.com/muzykantov/health-gpt/metrics"
 	"golang.org/x/net/proxy"
 )
@@ -140,6 +142,11 @@ func (c *Anthropic) ModelName() string {
 	return fmt.Sprintf("anthropic_%s", c.model)
 }
 
+// SupportsImages reports that photos can be sent in user messages.
+func (c *Anthropic) SupportsImages() bool {
+	return true
+}
+
 // CompleteChat implements the Completion interface.
 func (c *Anthropic) CompleteChat(ctx context.Context, msgs []chat.Message) (chat.Message, error) {
 	var (
@@ -172,6 +179,19 @@ func (c *Anthropic) CompleteChat(ctx context.Context, msgs []chat.Message) (chat
 			continue
 		}
 
+		// Photos are sent as image blocks followed by their caption.
+		if photo, ok := msg.Content.(content.Photo); ok && msg.Sender == chat.RoleUser {
+			blocks := []anthropic.ContentBlockParamUnion{
+				anthropic.NewImageBlockBase64(photo.MIME, base64.StdEncoding.EncodeToString(photo.Data)),
+			}
+			if photo.Caption != "" {
+				blocks = append(blocks, anthropic.NewTextBlock(photo.Caption))
+			}
+
+			anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(blocks...))
+			continue
+		}
+
 		content, ok := msg.Content.(string)
 		if !ok {
 			return chat.EmptyMessage, fmt.Errorf(
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
 	"github.com/muzykantov/health-gpt/metrics"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -140,6 +142,11 @@ func (c *OpenAI) ModelName() string {
 	return fmt.Sprintf("openai_%s", c.model)
 }
 
+// SupportsImages reports that photos can be sent in user messages.
+func (c *OpenAI) SupportsImages() bool {
+	return true
+}
+
 // CompleteChat implements the Completion interface.
 func (c *OpenAI) CompleteChat(ctx context.Context, msgs []chat.Message) (chat.Message, error) {
 	var (
@@ -151,6 +158,21 @@ func (c *OpenAI) CompleteChat(ctx context.Context, msgs []chat.Message) (chat.Me
 
 	openAIMessages := make([]openai.ChatCompletionMessageParamUnion, len(msgs))
 	for i, msg := range msgs {
+		// Photos are sent as data URLs followed by their caption.
+		if photo, ok := msg.Content.(content.Photo); ok && msg.Sender == chat.RoleUser {
+			parts := []openai.ChatCompletionContentPartUnionParam{
+				openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+					URL: "data:" + photo.MIME + ";base64," + base64.StdEncoding.EncodeToString(photo.Data),
+				}),
+			}
+			if photo.Caption != "" {
+				parts = append(parts, openai.TextContentPart(photo.Caption))
+			}
+
+			openAIMessages[i] = openai.UserMessage(parts)
+			continue
+		}
+
 		content, ok := msg.Content.(string)
 		if !ok {
 			status = "type_error"
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/muzykantov/health-gpt/chat"
+)
+
+// ErrScriptExhausted is returned when all scripted responses were used.
+var ErrScriptExhausted = errors.New("script exhausted")
+
+// scriptSeparator separates responses in a script file.
+const scriptSeparator = "---"
+
+// Script replays predefined responses in order, for offline development.
+type Script struct {
+	mu        sync.Mutex
+	responses []string
+	next      int
+}
+
+// NewScript creates a completer returning the given responses in order.
+func NewScript(responses ...string) *Script {
+	return &Script{responses: responses}
+}
+
+// ScriptFromFile reads responses separated by lines containing only "---".
+func ScriptFromFile(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	var (
+		responses []string
+		current   []string
+	)
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == scriptSeparator {
+			responses = append(responses, strings.TrimSpace(strings.Join(current, "\n")))
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+
+	if last := strings.TrimSpace(strings.Join(current, "\n")); last != "" {
+		responses = append(responses, last)
+	}
+
+	return NewScript(responses...), nil
+}
+
+// ModelName returns LLM's model name.
+func (s *Script) ModelName() string {
+	return "script"
+}
+
+// CompleteChat returns the next scripted response.
+func (s *Script) CompleteChat(ctx context.Context, msgs []chat.Message) (chat.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next >= len(s.responses) {
+		return chat.EmptyMessage, ErrScriptExhausted
+	}
+
+	response := s.responses[s.next]
+	s.next++
+
+	return chat.MsgA(response), nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
 	"github.com/muzykantov/health-gpt/metrics"
 )
 
@@ -117,6 +118,13 @@ func (v *Validator) ModelName() string {
 	return v.model.ModelName()
 }
 
+// SupportsImages reports whether the original model accepts photos in user
+// messages.
+func (v *Validator) SupportsImages() bool {
+	model, ok := v.model.(interface{ SupportsImages() bool })
+	return ok && model.SupportsImages()
+}
+
 // CompleteChat requests a response from LLM and validates the result.
 func (v *Validator) CompleteChat(ctx context.Context, msgs []chat.Message) (chat.Message, error) {
 	var (
@@ -175,6 +183,8 @@ func (v *Validator) CompleteChat(ctx context.Context, msgs []chat.Message) (chat
 
 		isJSON := json.Valid([]byte(content))
 
+		response.Score = valid.ReliabilityScore
+
 		// If response is valid, return it
 		if valid.CanSendToUser && valid.FollowsPrompt {
 			if !isJSON && v.debug {
@@ -302,8 +312,15 @@ func formatChatHistory(msgs []chat.Message) string {
 			continue
 		}
 
-		content, ok := msg.Content.(string)
-		if !ok {
+		var text string
+		switch msgContent := msg.Content.(type) {
+		case string:
+			text = msgContent
+		case content.Photo:
+			// The validator sees only text, so photos are replaced with a
+			// placeholder that keeps the caption.
+			text = strings.TrimSpace("[photo] " + msgContent.Caption)
+		default:
 			continue
 		}
 
@@ -315,7 +332,7 @@ func formatChatHistory(msgs []chat.Message) string {
 			role = "Assistant"
 		}
 
-		sb.WriteString(fmt.Sprintf("[%d] %s: %s\n\n", i+1, role, content))
+		sb.WriteString(fmt.Sprintf("[%d] %s: %s\n\n", i+1, role, text))
 	}
 
 	return sb.String()
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// FeedbackTotal counts user ratings of AI answers
+	FeedbackTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "llm_feedback_total",
+			Help: "Total number of user ratings of AI answers",
+		},
+		[]string{"prompt", "model", "rating"}, // up, down, inaccurate
+	)
+)
+
+// RecordFeedback records a user rating of an AI answer
+func RecordFeedback(prompt, model, rating string) {
+	FeedbackTotal.WithLabelValues(prompt, model, rating).Inc()
+}
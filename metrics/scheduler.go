@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// SchedulerJobsTotal counts processed scheduler jobs
+	SchedulerJobsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scheduler_jobs_total",
+			Help: "Total number of scheduler jobs processed",
+		},
+		[]string{"kind", "status"}, // sent, disabled, postponed, failed
+	)
+)
+
+// RecordSchedulerJob records a processed scheduler job metric
+func RecordSchedulerJob(kind, status string) {
+	SchedulerJobsTotal.WithLabelValues(kind, status).Inc()
+}
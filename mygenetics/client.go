@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/muzykantov/health-gpt/genetics"
 	"github.com/muzykantov/health-gpt/mygenetics/generated"
@@ -21,17 +22,32 @@ var DefaultClient = &Client{Client: http.DefaultClient}
 var (
 	ErrUnexpectedType = fmt.Errorf("unexpected type")
 	ErrNoFeatures     = fmt.Errorf("no features found")
+	ErrTokenExpired   = fmt.Errorf("token expired")
 )
 
+// renewBefore is how long before expiration the access token is renewed.
+const renewBefore = 5 * time.Minute
+
 type Client struct {
 	*http.Client
+
+	BaseURL string // API base URL, mygenetics.BaseURL if empty.
+}
+
+// baseURL returns API base URL of the client.
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+
+	return BaseURL
 }
 
 func (c *Client) Authenticate(
 	ctx context.Context,
 	email, password string,
 ) ([]Token, error) {
-	const loginURL = BaseURL + "/api/v2/auth/login"
+	loginURL := c.baseURL() + "/api/v2/auth/login"
 
 	loginReq := struct {
 		Email    string `json:"email"`
@@ -87,7 +103,7 @@ func (c *Client) Authenticate(
 }
 
 func (c *Client) Refresh(ctx context.Context, refresh Token) ([]Token, error) {
-	const refreshURL = BaseURL + "/api/v2/auth/renew"
+	refreshURL := c.baseURL() + "/api/v2/auth/renew"
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, refreshURL, nil)
 	if err != nil {
@@ -124,12 +140,57 @@ func (c *Client) Refresh(ctx context.Context, refresh Token) ([]Token, error) {
 	return out, nil
 }
 
+// Renew returns tokens with a valid access token: tokens as is if the access
+// token is valid for at least five minutes, otherwise tokens renewed with the
+// refresh token or, if it fails, obtained by logging in with the credentials.
+func (c *Client) Renew(ctx context.Context, tokens []Token, email, password string) ([]Token, error) {
+	if AccessToken(tokens).Expires().After(time.Now().Add(renewBefore)) {
+		return tokens, nil
+	}
+
+	var refreshErr error
+	if refresh := RefreshToken(tokens); refresh.Expires().After(time.Now()) {
+		renewed, err := c.Refresh(ctx, refresh)
+		if err == nil {
+			return mergeTokens(tokens, renewed), nil
+		}
+		refreshErr = err
+	}
+
+	if email == "" || password == "" {
+		if refreshErr != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTokenExpired, refreshErr)
+		}
+		return nil, ErrTokenExpired
+	}
+
+	return c.Authenticate(ctx, email, password)
+}
+
+// mergeTokens replaces tokens of the same types with renewed ones and keeps
+// the rest, e.g. the refresh token if only the access token was renewed.
+func mergeTokens(tokens, renewed []Token) []Token {
+	types := make(map[string]bool, len(renewed))
+	for _, token := range renewed {
+		types[token.Type()] = true
+	}
+
+	out := append([]Token{}, renewed...)
+	for _, token := range tokens {
+		if !types[token.Type()] {
+			out = append(out, token)
+		}
+	}
+
+	return out
+}
+
 func (c *Client) FetchFeatures(
 	ctx context.Context,
 	access Token,
 	codeLab string,
 ) (genetics.FeatureSet, error) {
-	const reportURL = BaseURL + "/api/v2/codelabs/%s?includeGenes=true&markerFileKey=ru"
+	reportURL := c.baseURL() + "/api/v2/codelabs/%s?includeGenes=true&markerFileKey=ru"
 
 	var codelabResponse generated.CodelabResponse
 	if err := c.doRequest(
@@ -164,7 +225,7 @@ func (c *Client) FetchFeatures(
 	}
 
 	if len(features) > 0 {
-		return features, nil
+		return sortFeatures(features), nil
 	}
 
 	for _, subFeature := range codelabResponse.Files.Payload.Signs {
@@ -191,7 +252,7 @@ func (c *Client) FetchFeatures(
 	}
 
 	if len(features) > 0 {
-		return features, nil
+		return sortFeatures(features), nil
 	}
 
 	return nil, ErrNoFeatures
@@ -201,7 +262,7 @@ func (c *Client) FetchCodelabs(
 	ctx context.Context,
 	access Token,
 ) ([]Codelab, error) {
-	const reportURL = BaseURL + "/api/v2/tests/"
+	reportURL := c.baseURL() + "/api/v2/tests/"
 
 	var testsResponse generated.TestsResponse
 	if err := c.doRequest(
@@ -217,8 +278,10 @@ func (c *Client) FetchCodelabs(
 	var codelabs []Codelab
 	for _, item := range testsResponse {
 		codelabs = append(codelabs, Codelab{
-			Code: item.CodeLab,
-			Name: item.Profile.Name,
+			Code:      item.CodeLab,
+			Name:      item.Profile.Name,
+			Status:    item.SystemStatus,
+			Activated: item.Activated,
 		})
 	}
 
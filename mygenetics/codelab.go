@@ -2,6 +2,27 @@ package mygenetics
 
 // Codelab contains MyGenetics test code.
 type Codelab struct {
-	Code string
-	Name string
+	Code      string
+	Name      string
+	Status    string // System status of the test, e.g. "done"
+	Activated bool   // The test kit is activated
+}
+
+// ChangedCodelabs returns codelabs of cur that are absent in prev or whose
+// status or activation differ from prev.
+func ChangedCodelabs(prev, cur []Codelab) []Codelab {
+	known := make(map[string]Codelab, len(prev))
+	for _, codelab := range prev {
+		known[codelab.Code] = codelab
+	}
+
+	var changed []Codelab
+	for _, codelab := range cur {
+		old, ok := known[codelab.Code]
+		if !ok || old.Status != codelab.Status || old.Activated != codelab.Activated {
+			changed = append(changed, codelab)
+		}
+	}
+
+	return changed
 }
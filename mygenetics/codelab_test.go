@@ -0,0 +1,42 @@
+package mygenetics
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChangedCodelabs(t *testing.T) {
+	prev := []Codelab{
+		{Code: "WN0000T", Name: "Питание", Status: "processing", Activated: true},
+		{Code: "DX0000T", Name: "Спорт", Status: "done", Activated: true},
+		{Code: "VM0000T", Name: "Витамины", Status: "new"},
+	}
+	cur := []Codelab{
+		{Code: "WN0000T", Name: "Питание", Status: "done", Activated: true},
+		{Code: "DX0000T", Name: "Спорт", Status: "done", Activated: true},
+		{Code: "VM0000T", Name: "Витамины", Status: "new", Activated: true},
+		{Code: "KD0000T", Name: "Детский", Status: "new"},
+	}
+
+	var codes []string
+	for _, codelab := range ChangedCodelabs(prev, cur) {
+		codes = append(codes, codelab.Code)
+	}
+
+	if want := []string{"WN0000T", "VM0000T", "KD0000T"}; !slices.Equal(codes, want) {
+		t.Errorf("ChangedCodelabs = %v, want %v", codes, want)
+	}
+
+	if changed := ChangedCodelabs(cur, cur); len(changed) != 0 {
+		t.Errorf("ChangedCodelabs of the same codelabs = %v", changed)
+	}
+}
+
+func TestMergeTokens(t *testing.T) {
+	tokens := []Token{"accessToken=old; path=/", "refreshToken=refresh; path=/"}
+
+	merged := mergeTokens(tokens, []Token{"accessToken=new; path=/"})
+	if AccessToken(merged) != "accessToken=new; path=/" || RefreshToken(merged) != "refreshToken=refresh; path=/" {
+		t.Errorf("mergeTokens = %v", merged)
+	}
+}
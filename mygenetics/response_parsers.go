@@ -3,6 +3,9 @@ package mygenetics
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/muzykantov/health-gpt/genetics"
 )
@@ -56,6 +59,8 @@ func (c *Client) parseWithConclusion(
 		feature.Conclusions = append(feature.Conclusions, conclusionStr)
 	}
 
+	feature.Risk = genetics.ParseRisk(payloadString(conclusions, riskKeys...))
+
 	genes, ok := featureValue["genes"].(map[string]any)
 	if !ok {
 		return genetics.Feature{}, fmt.Errorf(
@@ -92,6 +97,8 @@ func (c *Client) parseWithConclusion(
 	feature.Additional = r.Additional
 	feature.Checklist = r.Checklist
 
+	parseFeatureMarkers(&feature, featureValue)
+
 	return feature, nil
 }
 
@@ -116,6 +123,7 @@ func (c *Client) parseWithoutConclusion(
 		if conclusionRisk, ok := featureValue["conclusion_risk"]; ok {
 			if conclusionRiskStr, ok := conclusionRisk.(string); ok {
 				conclusion = fmt.Sprintf("%s %s", conclusion, conclusionRiskStr)
+				feature.Risk = genetics.ParseRisk(conclusionRiskStr)
 			}
 		}
 
@@ -158,6 +166,8 @@ func (c *Client) parseWithoutConclusion(
 	feature.Additional = r.Additional
 	feature.Checklist = r.Checklist
 
+	parseFeatureMarkers(&feature, featureValue)
+
 	return feature, nil
 }
 
@@ -204,12 +214,80 @@ func (c *Client) parseGenes(genes map[string]any) ([]genetics.Gene, error) {
 		parsedGenes = append(parsedGenes, genetics.Gene{
 			Name:            geneName,
 			Interpretations: interpretationsStr,
+			RSID:            payloadString(geneValue, "rsid", "rs", "snp", "marker"),
+			Genotype:        payloadString(geneValue, "genotype", "alleles"),
+			Effect:          genetics.ParseEffect(payloadString(geneValue, "effect", "direction")),
+			Risk:            genetics.ParseRisk(payloadString(geneValue, riskKeys...)),
+			Evidence:        genetics.ParseEvidence(payloadString(geneValue, "evidence", "evidence_level")),
 		})
 	}
 
 	return parsedGenes, nil
 }
 
+// sortFeatures orders features and their genes by name, since the payload
+// keeps them in maps and their order changes between requests.
+func sortFeatures(features genetics.FeatureSet) genetics.FeatureSet {
+	for _, f := range features {
+		slices.SortFunc(f.Genes, func(a, b genetics.Gene) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+
+	slices.SortFunc(features, func(a, b genetics.Feature) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return features
+}
+
+// riskKeys are payload keys of risk levels.
+var riskKeys = []string{"risk", "risk_level", "riskLevel"}
+
+// parseFeatureMarkers fills optional risk level and categories of the
+// feature. Categories are guessed from the feature name if the payload
+// has none.
+func parseFeatureMarkers(feature *genetics.Feature, featureValue map[string]any) {
+	if risk := genetics.ParseRisk(payloadString(featureValue, riskKeys...)); risk != genetics.RiskUnknown {
+		feature.Risk = risk
+	}
+
+	feature.Categories = genetics.ParseCategories(payloadString(featureValue, "category", "categories"))
+	if len(feature.Categories) == 0 {
+		feature.Categories = genetics.ParseCategories(feature.Name)
+	}
+}
+
+// payloadString returns the first present value of the keys as a string.
+// Lists are joined with "/", so alleles ["A", "C"] become "A/C".
+func payloadString(value map[string]any, keys ...string) string {
+	for _, key := range keys {
+		switch v := value[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				if s, ok := item.(string); ok && s != "" {
+					items = append(items, s)
+				}
+			}
+
+			if len(items) > 0 {
+				return strings.Join(items, "/")
+			}
+		}
+	}
+
+	return ""
+}
+
 func (c *Client) parseRecommendation(
 	recommendation map[string]any,
 	skipChecklist bool,
@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// Main CLI transport errors
+var (
+	ErrHandlerNotProvided   = errors.New("cli handler not provided")
+	ErrStorageNotProvided   = errors.New("cli storage not provided")
+	ErrCompleterNotProvided = errors.New("cli llm not provided")
+)
+
+// Default request cache TTL.
+const defaultCacheTTL = time.Hour * 24
+
+// quitCommand ends the session.
+const quitCommand = ":q"
+
+// tagPattern matches HTML tags of Telegram-formatted messages.
+var tagPattern = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+// Server is a terminal REPL transport for offline development.
+//
+// Input lines are translated into requests:
+//
+//	/name args  - command
+//	N           - pick option N of the last shown selects
+//	:q          - quit
+//	anything    - text message
+type Server struct {
+	In         io.Reader
+	Out        io.Writer
+	ChatID     int64
+	Language   string // Client language code of the user.
+	Handler    server.Handler
+	Completion server.ChatCompleter
+	Storage    server.DataStorage
+	Cache      server.Cache
+	Log        *log.Logger
+}
+
+// ListenAndServe reads stdin-like input until EOF, ":q" or ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if s.Handler == nil {
+		return ErrHandlerNotProvided
+	}
+
+	if s.Storage == nil {
+		return ErrStorageNotProvided
+	}
+
+	if s.Completion == nil {
+		return ErrCompleterNotProvided
+	}
+
+	cache := s.Cache
+	if cache == nil {
+		cache = expirable.NewLRU[string, any](0, nil, defaultCacheTTL)
+	}
+
+	logger := s.Log
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	out := &writer{out: s.Out}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(s.In)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out.prompt()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case line, ok := <-lines:
+			if !ok {
+				return nil
+			}
+
+			line = strings.TrimSpace(line)
+			if line == quitCommand {
+				return nil
+			}
+
+			if line == "" {
+				out.prompt()
+				continue
+			}
+
+			from, err := s.Storage.GetUser(ctx, s.ChatID)
+			if err != nil {
+				if !errors.Is(err, storage.ErrUserNotFound) {
+					return fmt.Errorf("failed to get user: %w", err)
+				}
+
+				from = chat.User{ID: s.ChatID, FirstName: "CLI"}
+			}
+			from.LanguageCode = s.Language
+
+			incoming := out.parse(line)
+
+			// Handlers may keep writing from background goroutines (typing
+			// indicators), so the writer is swapped for every request.
+			rw := out.begin()
+			s.Handler.Serve(ctx, rw, &server.Request{
+				ChatID:   s.ChatID,
+				Incoming: incoming,
+				From:     from,
+				Format:   server.FormatPlain,
+
+				Transport: server.TransportCLI,
+
+				Completer: s.Completion,
+				Storage:   s.Storage,
+				Cache:     cache,
+				Log:       logger,
+			})
+			rw.close()
+
+			out.prompt()
+		}
+	}
+}
+
+// writer renders messages to the terminal and remembers select options.
+type writer struct {
+	mu      sync.Mutex
+	out     io.Writer
+	options []chat.Message // Messages sent when an option number is entered.
+	fresh   bool
+}
+
+// parse converts an input line into an incoming message.
+func (w *writer) parse(line string) chat.Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if strings.HasPrefix(line, "/") {
+		name, args, _ := strings.Cut(strings.TrimPrefix(line, "/"), " ")
+		return chat.MsgU(content.Command{Name: name, Args: strings.TrimSpace(args)})
+	}
+
+	if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(w.options) {
+		return w.options[n-1]
+	}
+
+	return chat.MsgU(line)
+}
+
+// begin starts a new response batch.
+func (w *writer) begin() *requestWriter {
+	w.mu.Lock()
+	w.fresh = true
+	w.mu.Unlock()
+
+	return &requestWriter{w: w}
+}
+
+func (w *writer) prompt() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fmt.Fprint(w.out, "> ")
+}
+
+func (w *writer) write(m chat.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch msgContent := m.Content.(type) {
+	case string:
+		fmt.Fprintf(w.out, "%s\n\n", plain(msgContent))
+
+	case content.Select:
+		w.resetOptions()
+
+		fmt.Fprintf(w.out, "%s\n", plain(msgContent.Header))
+		for _, item := range msgContent.Items {
+			if item.URL != "" {
+				fmt.Fprintf(w.out, "  - %s: %s\n", item.Caption, item.URL)
+				continue
+			}
+
+			w.options = append(w.options, chat.MsgU(item))
+			fmt.Fprintf(w.out, "  [%d] %s\n", len(w.options), item.Caption)
+		}
+		fmt.Fprintln(w.out)
+
+	case content.Keyboard:
+		// Reply buttons are numbered like select options and send their text.
+		w.resetOptions()
+
+		fmt.Fprintf(w.out, "%s\n", plain(msgContent.Header))
+		for _, row := range msgContent.Buttons {
+			for _, text := range row {
+				w.options = append(w.options, chat.MsgU(text))
+				fmt.Fprintf(w.out, "  [%d] %s\n", len(w.options), text)
+			}
+		}
+		fmt.Fprintln(w.out)
+
+	case content.RemoveKeyboard:
+		w.options = nil
+		if msgContent.Text != "" {
+			fmt.Fprintf(w.out, "%s\n\n", plain(msgContent.Text))
+		}
+
+	case content.Voice:
+		fmt.Fprintf(w.out, "(voice message, %d bytes) %s\n\n", len(msgContent.Data), plain(msgContent.Caption))
+
+	case content.Document:
+		fmt.Fprintf(w.out, "(document %s, %d bytes) %s\n\n", msgContent.Name, len(msgContent.Data), plain(msgContent.Caption))
+
+	case content.Commands:
+		names := make([]string, 0, len(msgContent.Items))
+		for _, item := range msgContent.Items {
+			names = append(names, "/"+item.Name)
+		}
+		fmt.Fprintf(w.out, "(commands: %s)\n", strings.Join(names, " "))
+
+	case content.Typing:
+		fmt.Fprintln(w.out, "(typing...)")
+
+	default:
+		return fmt.Errorf("unsupported message type: %T", m.Content)
+	}
+
+	return nil
+}
+
+// resetOptions drops options of previous responses. Options of all selects
+// within one response are numbered together.
+func (w *writer) resetOptions() {
+	if w.fresh {
+		w.options = nil
+		w.fresh = false
+	}
+}
+
+// requestWriter implements server.ResponseWriter for a single request.
+type requestWriter struct {
+	mu     sync.Mutex
+	w      *writer
+	closed bool
+}
+
+func (rw *requestWriter) WriteResponse(m chat.Message) error {
+	if m.IsEmpty() {
+		return nil
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.closed {
+		return nil
+	}
+
+	return rw.w.write(m)
+}
+
+func (rw *requestWriter) close() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.closed = true
+}
+
+// plain strips Telegram HTML markup for terminal output.
+func plain(s string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(s, ""))
+}
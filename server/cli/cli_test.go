@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/llm"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+func TestServerSession(t *testing.T) {
+	fs, err := storage.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		switch c := r.Incoming.Content.(type) {
+		case content.Command:
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: "<b>Pick</b>",
+				Items:  []content.SelectItem{{Caption: "One", Data: "1"}},
+			}))
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: "Or",
+				Items:  []content.SelectItem{{Caption: "Two", Data: "2"}},
+			}))
+
+		case content.SelectItem:
+			w.WriteResponse(chat.MsgAf("picked %s", c.Data))
+
+		case string:
+			response, err := r.Completer.CompleteChat(ctx, []chat.Message{r.Incoming})
+			if err != nil {
+				w.WriteResponse(chat.MsgAf("error: %v", err))
+				return
+			}
+			w.WriteResponse(response)
+		}
+	})
+
+	out := new(bytes.Buffer)
+	srv := &Server{
+		In:         strings.NewReader("/start\n2\nhello\n:q\nignored\n"),
+		Out:        out,
+		ChatID:     1,
+		Handler:    h,
+		Completion: llm.NewScript("scripted answer"),
+		Storage:    fs,
+	}
+
+	if err := srv.ListenAndServe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"Pick\n  [1] One", "Or\n  [2] Two", "picked 2", "scripted answer"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	if strings.Contains(out.String(), "ignored") {
+		t.Errorf("input after :q was processed")
+	}
+}
+
+func TestServerKeyboard(t *testing.T) {
+	fs, err := storage.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		switch c := r.Incoming.Content.(type) {
+		case content.Command:
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: "Links",
+				Items: []content.SelectItem{
+					{Caption: "Site", URL: "https://example.com"},
+					{Caption: "More", Data: "more"},
+				},
+			}))
+			w.WriteResponse(chat.MsgA(content.Keyboard{
+				Header:  "Answer",
+				Buttons: [][]string{{"Yes", "No"}},
+			}))
+
+		case string:
+			w.WriteResponse(chat.MsgA(content.RemoveKeyboard{Text: "got " + c}))
+		}
+	})
+
+	out := new(bytes.Buffer)
+	srv := &Server{
+		In:         strings.NewReader("/start\n3\n"),
+		Out:        out,
+		ChatID:     1,
+		Handler:    h,
+		Completion: llm.NewScript(),
+		Storage:    fs,
+	}
+
+	if err := srv.ListenAndServe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"  - Site: https://example.com\n  [1] More",
+		"Answer\n  [2] Yes\n  [3] No",
+		"got No",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -2,9 +2,10 @@ package server
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log"
 
-	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/muzykantov/health-gpt/chat"
 )
 
@@ -14,37 +15,159 @@ type ChatCompleter interface {
 	CompleteChat(ctx context.Context, msgs []chat.Message) (chat.Message, error)
 }
 
+// ImageCompleter реализуется ChatCompleter, модель которого может понимать
+// изображения (content.Photo) в сообщениях пользователя.
+type ImageCompleter interface {
+	SupportsImages() bool
+}
+
+// Transcriber распознает речь в голосовых сообщениях.
+type Transcriber interface {
+	// Transcribe возвращает текст аудиозаписи. Расширение имени файла
+	// указывает формат записи (например, voice.ogg).
+	Transcribe(ctx context.Context, audio io.Reader, filename string) (string, error)
+}
+
+// Synthesizer озвучивает текст для голосовых ответов.
+type Synthesizer interface {
+	// Synthesize возвращает аудио в формате OGG/Opus.
+	Synthesize(ctx context.Context, text string) ([]byte, error)
+}
+
 // ChatHistoryStorage объединяет чтение и запись истории диалога.
 type ChatHistoryStorage interface {
 	GetChatHistory(ctx context.Context, chatID int64, limit uint64) ([]chat.Message, error)
 	SaveChatHistory(ctx context.Context, chatID int64, msgs []chat.Message) error
 }
 
+// ChatStateStorage хранит состояние диалога в чате.
+type ChatStateStorage interface {
+	GetChatState(ctx context.Context, chatID int64) (chat.State, error)
+	SaveChatState(ctx context.Context, chatID int64, state chat.State) error
+}
+
 // UserStorage хранит информацию о пользователях.
 type UserStorage interface {
 	GetUser(ctx context.Context, userID int64) (chat.User, error)
 	SaveUser(ctx context.Context, user chat.User) error
 }
 
-// Storage отвечает за получение и хранение данных.
+// PlanStorage хранит планы действий пользователей по идентификатору
+// пользователя.
+type PlanStorage interface {
+	GetPlan(ctx context.Context, userID int64) (chat.Plan, error)
+	SavePlan(ctx context.Context, userID int64, plan chat.Plan) error
+}
+
+// DiaryStorage хранит дневники питания пользователей по дням. Дневник
+// задается идентификатором пользователя и днем в формате
+// chat.DiaryDateLayout.
+type DiaryStorage interface {
+	GetDiary(ctx context.Context, userID int64, date string) (chat.Diary, error)
+	SaveDiary(ctx context.Context, userID int64, diary chat.Diary) error
+}
+
+// JournalStorage хранит журналы самочувствия пользователей по идентификатору
+// пользователя.
+type JournalStorage interface {
+	GetJournal(ctx context.Context, userID int64) (chat.Journal, error)
+	SaveJournal(ctx context.Context, userID int64, journal chat.Journal) error
+}
+
+// RatingStorage хранит оценки ответов ИИ.
+type RatingStorage interface {
+	GetRating(ctx context.Context, id string) (chat.Rating, error)
+	GetRatings(ctx context.Context) ([]chat.Rating, error)
+	SaveRating(ctx context.Context, rating chat.Rating) error
+}
+
+// JobStorage хранит задания планировщика, чтобы они переживали перезапуск.
+type JobStorage interface {
+	GetJobs(ctx context.Context) ([]chat.Job, error)
+	SaveJob(ctx context.Context, job chat.Job) error
+	DeleteJob(ctx context.Context, id string) error
+}
+
+// Storage отвечает за получение и хранение данных. История и состояние
+// диалога относятся к чату, а данные пользователя (план, дневник, журнал)
+// хранятся по идентификатору пользователя, чтобы быть общими для всех
+// транспортов.
 type DataStorage interface {
 	ChatHistoryStorage
+	ChatStateStorage
 	UserStorage
+	PlanStorage
+	DiaryStorage
+	JournalStorage
+	RatingStorage
+	JobStorage
+}
+
+// Cache хранит временные данные между запросами (например, вопрос пользователя
+// до выбора анализа). Интерфейс совместим с *expirable.LRU[string, any].
+type Cache interface {
+	Add(key string, value any) (evicted bool)
+	Get(key string) (value any, ok bool)
+	Remove(key string) (present bool)
 }
 
+// Format определяет разметку текстовых ответов, которую отображает транспорт.
+type Format string
+
+// Поддерживаемые форматы ответов.
+const (
+	FormatHTML     Format = "html"     // HTML в подмножестве Telegram (по умолчанию).
+	FormatMarkdown Format = "markdown" // Markdown для веб- и мобильных клиентов.
+	FormatPlain    Format = "plain"    // Текст без разметки для терминала.
+)
+
+// Transport определяет транспорт, через который пришел запрос.
+type Transport string
+
+// Транспорты сервера.
+const (
+	TransportTelegram  Transport = "telegram"
+	TransportHTTP      Transport = "http"
+	TransportWebSocket Transport = "websocket"
+	TransportCLI       Transport = "cli"
+)
+
 // Request содержит входящее сообщение и сервисы для его обработки.
 type Request struct {
 	ChatID   int64
 	Incoming chat.Message
 	From     chat.User
 
+	// Format - разметка отчетов для транспорта. Пустое значение
+	// соответствует FormatHTML.
+	Format Format
+
+	// Transport - транспорт запроса. Уведомления планировщика доставляются
+	// только через транспорт, из которого они запланированы.
+	Transport Transport
+
 	Completer ChatCompleter
 	Storage   DataStorage
-	Cache     *expirable.LRU[string, any]
+	Cache     Cache
+
+	// Synthesizer озвучивает ответы, если транспорт поддерживает голосовые
+	// сообщения. Может быть nil.
+	Synthesizer Synthesizer
 
 	Log *log.Logger
 }
 
+// ErrNotifierUnavailable возвращает Notifier, пока транспорт не готов
+// отправлять сообщения (например, еще не подключился). Такое уведомление
+// можно повторить позже.
+var ErrNotifierUnavailable = errors.New("notifier unavailable")
+
+// Notifier отправляет сообщение в чат вне обработки входящего сообщения
+// (например, уведомление планировщика).
+type Notifier interface {
+	Notify(ctx context.Context, chatID int64, m chat.Message) error
+}
+
 // ResponseWriter записывает ответное сообщение.
 type ResponseWriter interface {
 	WriteResponse(chat.Message) error
@@ -0,0 +1,24 @@
+package httpapi
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// minChatID is the lower bound of chat IDs reserved for API clients.
+// Telegram user and chat IDs fit into 53 bits, so API chats never share
+// history, state or MyGenetics tokens with Telegram users.
+const minChatID = 1 << 62
+
+// ChatID maps a chat ID chosen by a client into the range reserved for API
+// chats. IDs are scoped by namespace (an API key or a WebSocket session), so
+// a client cannot reach chats of other keys or sessions.
+func ChatID(namespace, id string) int64 {
+	sum := sha256.Sum256([]byte(namespace + "\x00" + id))
+	return int64(binary.BigEndian.Uint64(sum[:8])>>2 | minChatID)
+}
+
+// IsChatID reports whether the chat ID belongs to an API client.
+func IsChatID(chatID int64) bool {
+	return chatID >= minChatID
+}
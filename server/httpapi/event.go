@@ -0,0 +1,136 @@
+package httpapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+)
+
+// Event types sent to API clients.
+const (
+	EventText     = "text"
+	EventSelect   = "select"
+	EventCommands = "commands"
+	EventTyping   = "typing"
+
+	// EventVoice carries a base64-encoded OGG/Opus voice note in audio.
+	EventVoice = "voice"
+
+	// EventDocument carries a base64-encoded file in file, its name in
+	// file_name and its content type in mime.
+	EventDocument = "document"
+
+	// EventKeyboard offers quick replies: pressing a button sends its text
+	// as a regular message.
+	EventKeyboard = "keyboard"
+	// EventRemoveKeyboard hides previously offered quick replies.
+	EventRemoveKeyboard = "remove_keyboard"
+)
+
+// Event is a JSON representation of a chat message.
+type Event struct {
+	Type        string     `json:"type"`
+	ID          string     `json:"id,omitempty"`
+	Role        string     `json:"role,omitempty"`
+	Text        string     `json:"text,omitempty"`
+	Header      string     `json:"header,omitempty"`
+	Items       []Item     `json:"items,omitempty"`
+	Columns     int        `json:"columns,omitempty"`
+	Buttons     [][]string `json:"buttons,omitempty"`
+	OneTime     bool       `json:"one_time,omitempty"`
+	Placeholder string     `json:"placeholder,omitempty"`
+	Audio       []byte     `json:"audio,omitempty"`
+	File        []byte     `json:"file,omitempty"`
+	FileName    string     `json:"file_name,omitempty"`
+	MIME        string     `json:"mime,omitempty"`
+	Commands    []Command  `json:"commands,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// Item is a selectable option of a select event.
+type Item struct {
+	Caption string `json:"caption"`
+	Data    string `json:"data,omitempty"`
+	URL     string `json:"url,omitempty"` // Link to open instead of posting data.
+}
+
+// Command is a bot command available to the client.
+type Command struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// NewEvent converts a chat message into an API event.
+func NewEvent(m chat.Message) (Event, error) {
+	e := Event{
+		ID:   m.ID,
+		Role: m.Sender.String(),
+	}
+
+	if !m.CreatedAt.IsZero() {
+		createdAt := m.CreatedAt
+		e.CreatedAt = &createdAt
+	}
+
+	switch msgContent := m.Content.(type) {
+	case string:
+		e.Type = EventText
+		e.Text = msgContent
+
+	case content.Select:
+		e.Type = EventSelect
+		e.Header = msgContent.Header
+		e.Columns = msgContent.Columns
+		e.Items = make([]Item, 0, len(msgContent.Items))
+		for _, item := range msgContent.Items {
+			e.Items = append(e.Items, Item{
+				Caption: item.Caption,
+				Data:    item.Data,
+				URL:     item.URL,
+			})
+		}
+
+	case content.Keyboard:
+		e.Type = EventKeyboard
+		e.Header = msgContent.Header
+		e.Buttons = msgContent.Buttons
+		e.OneTime = msgContent.OneTime
+		e.Placeholder = msgContent.Placeholder
+
+	case content.RemoveKeyboard:
+		e.Type = EventRemoveKeyboard
+		e.Text = msgContent.Text
+
+	case content.Voice:
+		e.Type = EventVoice
+		e.Text = msgContent.Caption
+		e.Audio = msgContent.Data
+
+	case content.Document:
+		e.Type = EventDocument
+		e.Text = msgContent.Caption
+		e.File = msgContent.Data
+		e.FileName = msgContent.Name
+		e.MIME = msgContent.MIME
+
+	case content.Commands:
+		e.Type = EventCommands
+		e.Commands = make([]Command, 0, len(msgContent.Items))
+		for _, item := range msgContent.Items {
+			e.Commands = append(e.Commands, Command{
+				Name:        item.Name,
+				Description: item.Description,
+			})
+		}
+
+	case content.Typing:
+		e.Type = EventTyping
+
+	default:
+		return Event{}, fmt.Errorf("%w: %T", ErrUnsupportedMessageType, m.Content)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+)
+
+func TestNewEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  chat.Message
+		want string
+	}{
+		{
+			name: "select",
+			msg: chat.MsgA(content.Select{
+				Header:  "Pick",
+				Columns: 2,
+				Items: []content.SelectItem{
+					{Caption: "One", Data: "1"},
+					{Caption: "Site", URL: "https://example.com"},
+				},
+			}),
+			want: `{"type":"select","role":"assistant","header":"Pick","items":[{"caption":"One","data":"1"},{"caption":"Site","url":"https://example.com"}],"columns":2}`,
+		},
+		{
+			name: "keyboard",
+			msg: chat.MsgA(content.Keyboard{
+				Header:      "Answer",
+				Buttons:     [][]string{{"Yes", "No"}},
+				OneTime:     true,
+				Resize:      true,
+				Placeholder: "Reply",
+			}),
+			want: `{"type":"keyboard","role":"assistant","header":"Answer","buttons":[["Yes","No"]],"one_time":true,"placeholder":"Reply"}`,
+		},
+		{
+			name: "remove keyboard",
+			msg:  chat.MsgA(content.RemoveKeyboard{Text: "Thanks"}),
+			want: `{"type":"remove_keyboard","role":"assistant","text":"Thanks"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEvent(tt.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.ID, e.CreatedAt = "", nil
+
+			got, err := json.Marshal(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("NewEvent()\n got: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
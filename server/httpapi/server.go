@@ -0,0 +1,416 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// Main HTTP API errors
+var (
+	ErrHandlerNotProvided     = errors.New("http api handler not provided")
+	ErrStorageNotProvided     = errors.New("http api storage not provided")
+	ErrCompleterNotProvided   = errors.New("http api llm not provided")
+	ErrUnsupportedMessageType = errors.New("http api unsupported message type")
+)
+
+// Default request cache TTL.
+const defaultCacheTTL = time.Hour * 24
+
+// Default number of messages returned by the history endpoint.
+const defaultHistoryLimit = 100
+
+// Server exposes the bot handlers as a REST API with JSON payloads.
+//
+// Endpoints (chat ID doubles as user ID, as in Telegram private chats):
+//
+//	POST /v1/chats/{chatID}/messages  {"text": "..."}
+//	POST /v1/chats/{chatID}/select    {"data": "...", "caption": "..."}
+//	POST /v1/chats/{chatID}/commands  {"name": "...", "args": "..."}
+//	GET  /v1/chats/{chatID}/history?limit=N
+//
+// Every request must carry one of APIKeys in the X-API-Key header
+// or as a bearer token. Chat IDs are scoped by the key (see ChatID): clients
+// of different keys and Telegram users never share chats.
+type Server struct {
+	Addr       string
+	APIKeys    []string
+	Handler    server.Handler
+	Completion server.ChatCompleter
+	Storage    server.DataStorage
+	Cache      server.Cache
+	Log        *log.Logger
+
+	// Format is the markup of reports sent to clients (HTML by default).
+	Format server.Format
+
+	initOnce sync.Once
+	mux      *http.ServeMux
+	cache    server.Cache
+	logger   *log.Logger
+}
+
+// UserInfo describes the API client's user, used when the user is seen first
+// time. The language code is applied on every request; the Accept-Language
+// header is used when it is empty.
+type UserInfo struct {
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	UserName     string `json:"username"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+type messageRequest struct {
+	Text string    `json:"text"`
+	User *UserInfo `json:"user,omitempty"`
+}
+
+type selectRequest struct {
+	Caption string    `json:"caption"`
+	Data    string    `json:"data"`
+	User    *UserInfo `json:"user,omitempty"`
+}
+
+type commandRequest struct {
+	Name string    `json:"name"`
+	Args string    `json:"args"`
+	User *UserInfo `json:"user,omitempty"`
+}
+
+type eventsResponse struct {
+	Messages []Event `json:"messages"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// ListenAndServe starts the HTTP server and stops it when ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Printf("Starting HTTP API server on %s", s.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http api server: %w", err)
+
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.logger.Printf("failed to shutdown http api server: %v", err)
+		}
+
+		return ctx.Err()
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.validate(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !s.authorized(apiKey(r)) {
+		writeError(w, http.StatusUnauthorized, errors.New("invalid api key"))
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// validate checks required fields and initializes defaults.
+func (s *Server) validate() error {
+	if s.Handler == nil {
+		return ErrHandlerNotProvided
+	}
+
+	if s.Storage == nil {
+		return ErrStorageNotProvided
+	}
+
+	if s.Completion == nil {
+		return ErrCompleterNotProvided
+	}
+
+	s.initOnce.Do(func() {
+		s.cache = s.Cache
+		if s.cache == nil {
+			s.cache = expirable.NewLRU[string, any](0, nil, defaultCacheTTL)
+		}
+
+		s.logger = s.Log
+		if s.logger == nil {
+			s.logger = log.Default()
+		}
+
+		s.mux = http.NewServeMux()
+		s.mux.HandleFunc("POST /v1/chats/{chatID}/messages", s.handleMessage)
+		s.mux.HandleFunc("POST /v1/chats/{chatID}/select", s.handleSelect)
+		s.mux.HandleFunc("POST /v1/chats/{chatID}/commands", s.handleCommand)
+		s.mux.HandleFunc("GET /v1/chats/{chatID}/history", s.handleHistory)
+	})
+
+	return nil
+}
+
+// apiKey returns the API key of the request.
+func apiKey(r *http.Request) string {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+
+	return key
+}
+
+// authorized checks the API key.
+func (s *Server) authorized(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, allowed := range s.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	var req messageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	if req.Text == "" {
+		writeError(w, http.StatusBadRequest, errors.New("text is required"))
+		return
+	}
+
+	s.serve(w, r, req.User, chat.MsgU(req.Text))
+}
+
+func (s *Server) handleSelect(w http.ResponseWriter, r *http.Request) {
+	var req selectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	if req.Data == "" {
+		writeError(w, http.StatusBadRequest, errors.New("data is required"))
+		return
+	}
+
+	s.serve(w, r, req.User, chat.MsgU(content.SelectItem{
+		Caption: req.Caption,
+		Data:    req.Data,
+	}))
+}
+
+func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	var req commandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	name := strings.TrimPrefix(req.Name, "/")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, errors.New("name is required"))
+		return
+	}
+
+	s.serve(w, r, req.User, chat.MsgU(content.Command{
+		Name: name,
+		Args: req.Args,
+	}))
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	chatID, err := requestChatID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid chat id: %w", err))
+		return
+	}
+
+	limit := uint64(defaultHistoryLimit)
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if limit, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %w", err))
+			return
+		}
+	}
+
+	msgs, err := s.Storage.GetChatHistory(r.Context(), chatID, limit)
+	if err != nil {
+		s.logger.Printf("failed to get chat history (chatID: %d): %v", chatID, err)
+		writeError(w, http.StatusInternalServerError, errors.New("failed to get chat history"))
+		return
+	}
+
+	resp := eventsResponse{Messages: make([]Event, 0, len(msgs))}
+	for _, msg := range msgs {
+		// System prompts are internal and never shown to clients.
+		if msg.Sender == chat.RoleSystem {
+			continue
+		}
+
+		e, err := NewEvent(msg)
+		if err != nil {
+			continue
+		}
+		resp.Messages = append(resp.Messages, e)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// serve runs the handler pipeline and writes collected responses.
+func (s *Server) serve(
+	w http.ResponseWriter,
+	r *http.Request,
+	info *UserInfo,
+	incoming chat.Message,
+) {
+	ctx := r.Context()
+
+	chatID, err := requestChatID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid chat id: %w", err))
+		return
+	}
+
+	from, err := s.Storage.GetUser(ctx, chatID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			s.logger.Printf("failed to get user (chatID: %d): %v", chatID, err)
+			writeError(w, http.StatusInternalServerError, errors.New("failed to get user"))
+			return
+		}
+
+		from = chat.User{ID: chatID}
+		if info != nil {
+			from.FirstName = info.FirstName
+			from.LastName = info.LastName
+			from.UserName = info.UserName
+		}
+	}
+
+	from.LanguageCode = r.Header.Get("Accept-Language")
+	if info != nil && info.LanguageCode != "" {
+		from.LanguageCode = info.LanguageCode
+	}
+
+	rw := &responseRecorder{}
+	s.Handler.Serve(ctx, rw, &server.Request{
+		ChatID:   chatID,
+		Incoming: incoming,
+		From:     from,
+		Format:   s.Format,
+
+		Transport: server.TransportHTTP,
+
+		Completer: s.Completion,
+		Storage:   s.Storage,
+		Cache:     s.cache,
+		Log:       s.logger,
+	})
+
+	writeJSON(w, http.StatusOK, eventsResponse{Messages: rw.close()})
+}
+
+// requestChatID returns the chat ID from the request path scoped by the API
+// key of the request.
+func requestChatID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("chatID"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return ChatID(apiKey(r), strconv.FormatInt(id, 10)), nil
+}
+
+// responseRecorder collects handler responses. Handlers may keep writing
+// from background goroutines (typing indicators), so writes after close
+// are dropped.
+type responseRecorder struct {
+	mu     sync.Mutex
+	events []Event
+	closed bool
+}
+
+func (rw *responseRecorder) WriteResponse(m chat.Message) error {
+	if m.IsEmpty() {
+		return nil
+	}
+
+	e, err := NewEvent(m)
+	if err != nil {
+		return err
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if !rw.closed {
+		rw.events = append(rw.events, e)
+	}
+
+	return nil
+}
+
+func (rw *responseRecorder) close() []Event {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.closed = true
+	if rw.events == nil {
+		return []Event{}
+	}
+
+	return rw.events
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,145 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/llm"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, server.DataStorage) {
+	t.Helper()
+
+	fs, err := storage.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		switch c := r.Incoming.Content.(type) {
+		case string:
+			w.WriteResponse(chat.MsgA(content.Typing{}))
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: "echo: " + c,
+				Items:  []content.SelectItem{{Caption: "A", Data: "a"}},
+			}))
+			r.Storage.SaveChatHistory(ctx, r.ChatID, []chat.Message{r.Incoming})
+
+		case content.SelectItem:
+			w.WriteResponse(chat.MsgAf("selected %s", c.Data))
+
+		case content.Command:
+			w.WriteResponse(chat.MsgA(content.Commands{
+				Items: []content.Command{{Name: c.Name, Description: "desc"}},
+			}))
+		}
+	})
+
+	srv := httptest.NewServer(&Server{
+		APIKeys:    []string{"secret", "other"},
+		Handler:    h,
+		Completion: &llm.Mock{},
+		Storage:    fs,
+	})
+	t.Cleanup(srv.Close)
+
+	return srv, fs
+}
+
+func do(t *testing.T, method, url, key, body string) (int, eventsResponse) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out eventsResponse
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	return resp.StatusCode, out
+}
+
+func TestServerAuth(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	status, _ := do(t, http.MethodPost, srv.URL+"/v1/chats/1/messages", "wrong", `{"text":"hi"}`)
+	if status != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", status, http.StatusUnauthorized)
+	}
+}
+
+func TestServerEndpoints(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	status, resp := do(t, http.MethodPost, srv.URL+"/v1/chats/1/messages", "secret", `{"text":"hi"}`)
+	if status != http.StatusOK {
+		t.Fatalf("messages status = %d", status)
+	}
+	if len(resp.Messages) != 2 ||
+		resp.Messages[0].Type != EventTyping ||
+		resp.Messages[1].Type != EventSelect ||
+		resp.Messages[1].Header != "echo: hi" ||
+		len(resp.Messages[1].Items) != 1 {
+		t.Fatalf("unexpected messages response: %+v", resp)
+	}
+
+	_, resp = do(t, http.MethodPost, srv.URL+"/v1/chats/1/select", "secret", `{"data":"a"}`)
+	if len(resp.Messages) != 1 || resp.Messages[0].Text != "selected a" {
+		t.Fatalf("unexpected select response: %+v", resp)
+	}
+
+	_, resp = do(t, http.MethodPost, srv.URL+"/v1/chats/1/commands", "secret", `{"name":"/start"}`)
+	if len(resp.Messages) != 1 ||
+		resp.Messages[0].Type != EventCommands ||
+		resp.Messages[0].Commands[0].Name != "start" {
+		t.Fatalf("unexpected commands response: %+v", resp)
+	}
+
+	_, resp = do(t, http.MethodGet, srv.URL+"/v1/chats/1/history", "secret", "")
+	if len(resp.Messages) != 1 || resp.Messages[0].Text != "hi" {
+		t.Fatalf("unexpected history response: %+v", resp)
+	}
+}
+
+func TestServerChatNamespaces(t *testing.T) {
+	srv, fs := newTestServer(t)
+
+	// A Telegram user with the same numeric ID.
+	if err := fs.SaveChatHistory(t.Context(), 1, []chat.Message{chat.MsgU("telegram")}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp := do(t, http.MethodGet, srv.URL+"/v1/chats/1/history", "secret", "")
+	if len(resp.Messages) != 0 {
+		t.Fatalf("history of the Telegram chat is exposed: %+v", resp)
+	}
+
+	do(t, http.MethodPost, srv.URL+"/v1/chats/1/messages", "secret", `{"text":"hi"}`)
+
+	_, resp = do(t, http.MethodGet, srv.URL+"/v1/chats/1/history", "other", "")
+	if len(resp.Messages) != 0 {
+		t.Fatalf("history of another key is exposed: %+v", resp)
+	}
+
+	if id := ChatID("secret", "1"); !IsChatID(id) || IsChatID(1<<53) {
+		t.Errorf("ChatID = %d, want an API chat ID", id)
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2025, time.March, 12, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.March, 12, 10, 31, 0, 0, time.UTC)},
+		{"0 10 * * *", time.Date(2025, time.March, 13, 10, 0, 0, 0, time.UTC)},
+		{"45 10 * * *", time.Date(2025, time.March, 12, 10, 45, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2025, time.March, 12, 10, 40, 0, 0, time.UTC)},
+		{"0 9-21/4 * * *", time.Date(2025, time.March, 12, 13, 0, 0, 0, time.UTC)},
+		{"0 12 * * 1", time.Date(2025, time.March, 17, 12, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2025, time.March, 16, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 8 1,15 * 5", time.Date(2025, time.March, 14, 8, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+
+		if got := schedule.Next(now); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextLocation(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	schedule, err := Parse("0 10 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, time.March, 12, 8, 0, 0, 0, time.UTC)
+	want := time.Date(2025, time.March, 13, 7, 0, 0, 0, time.UTC)
+
+	if got := schedule.Next(now.In(moscow)); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		if _, err := Parse(spec); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("Parse(%q) error = %v, want %v", spec, err, ErrInvalidSpec)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package scheduler sends scheduled notifications: it periodically runs
+// due jobs from the storage through the handler and delivers responses
+// with a server.Notifier.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/metrics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// Default interval between checks for due jobs.
+const defaultInterval = time.Minute
+
+// Scheduler runs jobs persisted in the storage, so they survive restarts.
+//
+// A job without the next run time is armed on the first check. Jobs
+// scheduled from other transports than the one of the Notifier are deleted,
+// since the Notifier cannot reach their chats. Jobs of kinds disabled by
+// the user are skipped; jobs due in the user's quiet hours are postponed
+// until they end. Quiet hours are in the user's time zone if it is set.
+type Scheduler struct {
+	Handler   server.Handler
+	Notifier  server.Notifier
+	Completer server.ChatCompleter
+	Storage   server.DataStorage
+	Cache     server.Cache
+	Format    server.Format
+	Log       *log.Logger
+
+	// Transport of the Notifier. Jobs without a transport, scheduled before
+	// it was recorded, are delivered too. All jobs are delivered if empty.
+	Transport server.Transport
+
+	// Location is the time zone of cron specifications and of quiet hours
+	// of users without their own time zone, time.Local if nil.
+	Location *time.Location
+
+	// Interval between checks for due jobs, one minute if zero.
+	Interval time.Duration
+}
+
+// Run checks for due jobs until the context is canceled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval == 0 {
+		interval = defaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunDue(ctx, chat.Now()); err != nil {
+			s.logger().Printf("failed to run scheduled jobs: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs jobs due at now. Errors of single jobs are logged, so they
+// do not stop other jobs.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) error {
+	jobs, err := s.Storage.GetJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("get jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := s.run(ctx, job, now); err != nil {
+			s.logger().Printf("failed to run job %s: %v", job.ID, err)
+			metrics.RecordSchedulerJob(string(job.Kind), "failed")
+		}
+	}
+
+	return nil
+}
+
+// run runs the job if it is due and saves it with the next run time.
+func (s *Scheduler) run(ctx context.Context, job chat.Job, now time.Time) error {
+	now = now.In(s.location())
+
+	if s.Transport != "" && job.Transport != "" && job.Transport != string(s.Transport) {
+		metrics.RecordSchedulerJob(string(job.Kind), "unreachable")
+		return s.Storage.DeleteJob(ctx, job.ID)
+	}
+
+	schedule, err := Parse(job.Spec)
+	if err != nil {
+		return err
+	}
+
+	if job.Next.IsZero() {
+		job.Next = schedule.Next(now)
+		return s.Storage.SaveJob(ctx, job)
+	}
+
+	if job.Next.After(now) {
+		return nil
+	}
+
+	user, err := s.Storage.GetUser(ctx, job.UserID)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		return s.Storage.DeleteJob(ctx, job.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	// Quiet hours are checked in the user's time zone.
+	local := now.In(user.Notifications.Location(s.location()))
+
+	switch {
+	case !user.Notifications.Enabled(job.Kind):
+		metrics.RecordSchedulerJob(string(job.Kind), "disabled")
+
+	case user.Notifications.QuietHours.Contains(local):
+		metrics.RecordSchedulerJob(string(job.Kind), "postponed")
+
+		job.Next = user.Notifications.QuietHours.End(local)
+		return s.Storage.SaveJob(ctx, job)
+
+	default:
+		w := &notifierWriter{ctx: ctx, chatID: job.ChatID, notifier: s.Notifier}
+		s.Handler.Serve(ctx, w, &server.Request{
+			ChatID: job.ChatID,
+			Incoming: chat.MsgU(content.Notification{
+				Kind: string(job.Kind),
+				Data: job.Data,
+			}),
+			From:   user,
+			Format: s.Format,
+
+			Transport: server.Transport(job.Transport),
+
+			Completer: s.Completer,
+			Storage:   s.Storage,
+			Cache:     s.Cache,
+			Log:       s.logger(),
+		})
+
+		// The job is retried only if the transport is not ready yet; other
+		// errors are not, since the chat may have blocked the bot.
+		if errors.Is(w.err, server.ErrNotifierUnavailable) {
+			return w.err
+		}
+		if w.err != nil {
+			s.logger().Printf("failed to notify chat %d (job %s): %v", job.ChatID, job.ID, w.err)
+			metrics.RecordSchedulerJob(string(job.Kind), "failed")
+		} else {
+			metrics.RecordSchedulerJob(string(job.Kind), "sent")
+		}
+
+		job.Last = now
+	}
+
+	job.Next = schedule.Next(now)
+	return s.Storage.SaveJob(ctx, job)
+}
+
+func (s *Scheduler) location() *time.Location {
+	if s.Location == nil {
+		return time.Local
+	}
+
+	return s.Location
+}
+
+func (s *Scheduler) logger() *log.Logger {
+	if s.Log == nil {
+		return log.Default()
+	}
+
+	return s.Log
+}
+
+// notifierWriter delivers handler responses with the notifier and keeps
+// the first error.
+type notifierWriter struct {
+	ctx      context.Context
+	chatID   int64
+	notifier server.Notifier
+	err      error
+}
+
+func (w *notifierWriter) WriteResponse(m chat.Message) error {
+	err := w.notifier.Notify(w.ctx, w.chatID, m)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+
+	return err
+}
@@ -0,0 +1,270 @@
+package scheduler_test
+
+import (
+	"context"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/server"
+	"github.com/muzykantov/health-gpt/server/scheduler"
+	"github.com/muzykantov/health-gpt/server/servertest"
+)
+
+// notifier records notifications or fails with err.
+type notifier struct {
+	mu   sync.Mutex
+	sent map[int64][]string
+	err  error
+}
+
+func (n *notifier) Notify(ctx context.Context, chatID int64, m chat.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.err != nil {
+		return n.err
+	}
+
+	if n.sent == nil {
+		n.sent = make(map[int64][]string)
+	}
+	text, _ := m.Content.(string)
+	n.sent[chatID] = append(n.sent[chatID], text)
+
+	return nil
+}
+
+func (n *notifier) texts(chatID int64) []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.sent[chatID]
+}
+
+// echo responds to notifications with their kind and data.
+var echo = server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+	n := r.Incoming.Content.(content.Notification)
+	w.WriteResponse(chat.MsgA(n.Kind + ":" + n.Data))
+})
+
+func newScheduler(t *testing.T, users ...chat.User) (*scheduler.Scheduler, *notifier) {
+	t.Helper()
+
+	store := servertest.NewStorage()
+	for _, user := range users {
+		if err := store.SaveUser(t.Context(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n := &notifier{}
+
+	return &scheduler.Scheduler{
+		Handler:  echo,
+		Notifier: n,
+		Storage:  store,
+		Log:      log.New(testWriter{t}, "", 0),
+		Location: time.UTC,
+	}, n
+}
+
+func saveJob(t *testing.T, s *scheduler.Scheduler, job chat.Job) {
+	t.Helper()
+
+	if err := s.Storage.SaveJob(t.Context(), job); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func job(t *testing.T, s *scheduler.Scheduler, id string) (chat.Job, bool) {
+	t.Helper()
+
+	jobs, err := s.Storage.GetJobs(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, j := range jobs {
+		if j.ID == id {
+			return j, true
+		}
+	}
+
+	return chat.Job{}, false
+}
+
+func runDue(t *testing.T, s *scheduler.Scheduler, now time.Time) {
+	t.Helper()
+
+	if err := s.RunDue(t.Context(), now); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSchedulerRecurringJob(t *testing.T) {
+	s, n := newScheduler(t, chat.User{ID: 1})
+
+	reminder := chat.Job{
+		ID:     chat.JobID(1, chat.JobReminder, ""),
+		ChatID: 1,
+		UserID: 1,
+		Kind:   chat.JobReminder,
+		Spec:   "0 10 * * *",
+	}
+	saveJob(t, s, reminder)
+
+	// A new job is armed, not run.
+	now := time.Date(2025, time.March, 12, 10, 30, 0, 0, time.UTC)
+	runDue(t, s, now)
+
+	armed, _ := job(t, s, reminder.ID)
+	if want := time.Date(2025, time.March, 13, 10, 0, 0, 0, time.UTC); !armed.Next.Equal(want) {
+		t.Fatalf("armed job next = %v, want %v", armed.Next, want)
+	}
+	if got := n.texts(1); len(got) != 0 {
+		t.Fatalf("armed job sent %q", got)
+	}
+
+	runDue(t, s, armed.Next.Add(-time.Second))
+	if got := n.texts(1); len(got) != 0 {
+		t.Fatalf("job sent before it is due: %q", got)
+	}
+
+	// The job runs once, even if the scheduler was stopped for days.
+	runDue(t, s, armed.Next.Add(72*time.Hour))
+	if got := n.texts(1); len(got) != 1 || got[0] != "reminder:" {
+		t.Fatalf("sent %q, want one reminder", got)
+	}
+
+	done, _ := job(t, s, reminder.ID)
+	if want := time.Date(2025, time.March, 17, 10, 0, 0, 0, time.UTC); !done.Next.Equal(want) {
+		t.Errorf("job next = %v, want %v", done.Next, want)
+	}
+	if done.Last.IsZero() {
+		t.Errorf("job last run is not set")
+	}
+}
+
+func TestSchedulerOptOutAndQuietHours(t *testing.T) {
+	s, n := newScheduler(t,
+		chat.User{ID: 1, Notifications: chat.Notifications{Disabled: []chat.JobKind{chat.JobTip}}},
+		chat.User{ID: 2, Notifications: chat.Notifications{QuietHours: chat.QuietHours{From: 22, To: 8}}},
+	)
+
+	now := time.Date(2025, time.March, 12, 23, 0, 0, 0, time.UTC)
+
+	saveJob(t, s, chat.Job{ID: "1:tip", ChatID: 1, UserID: 1, Kind: chat.JobTip, Spec: "0 23 * * *", Next: now})
+	saveJob(t, s, chat.Job{ID: "2:tip", ChatID: 2, UserID: 2, Kind: chat.JobTip, Spec: "0 23 * * *", Next: now})
+
+	runDue(t, s, now)
+
+	// The opted out user gets nothing, but the job is kept for the case
+	// the user opts in again.
+	if got := n.texts(1); len(got) != 0 {
+		t.Errorf("disabled job sent %q", got)
+	}
+	if disabled, _ := job(t, s, "1:tip"); !disabled.Next.Equal(now.AddDate(0, 0, 1)) {
+		t.Errorf("disabled job next = %v, want next day", disabled.Next)
+	}
+
+	// The job in quiet hours is postponed until they end.
+	if got := n.texts(2); len(got) != 0 {
+		t.Errorf("job sent in quiet hours: %q", got)
+	}
+
+	postponed, _ := job(t, s, "2:tip")
+	morning := time.Date(2025, time.March, 13, 8, 0, 0, 0, time.UTC)
+	if !postponed.Next.Equal(morning) {
+		t.Fatalf("postponed job next = %v, want %v", postponed.Next, morning)
+	}
+
+	runDue(t, s, morning)
+	if got := n.texts(2); len(got) != 1 {
+		t.Errorf("sent %q after quiet hours, want one tip", got)
+	}
+}
+
+func TestSchedulerUserTimeZone(t *testing.T) {
+	// 23:00 UTC is 02:00 in UTC+3, within the user's quiet hours.
+	s, n := newScheduler(t, chat.User{ID: 1, Notifications: chat.Notifications{
+		QuietHours: chat.QuietHours{From: 0, To: 8},
+		TimeZone:   "UTC+3",
+	}})
+
+	now := time.Date(2025, time.March, 12, 23, 0, 0, 0, time.UTC)
+	saveJob(t, s, chat.Job{ID: "1:tip", ChatID: 1, UserID: 1, Kind: chat.JobTip, Spec: "0 23 * * *", Next: now})
+
+	runDue(t, s, now)
+
+	if got := n.texts(1); len(got) != 0 {
+		t.Errorf("job sent in quiet hours: %q", got)
+	}
+
+	// Quiet hours end at 08:00 in UTC+3, 05:00 UTC.
+	postponed, _ := job(t, s, "1:tip")
+	if want := time.Date(2025, time.March, 13, 5, 0, 0, 0, time.UTC); !postponed.Next.Equal(want) {
+		t.Errorf("postponed job next = %v, want %v", postponed.Next, want)
+	}
+}
+
+func TestSchedulerNotifierUnavailable(t *testing.T) {
+	s, n := newScheduler(t, chat.User{ID: 1})
+	n.err = server.ErrNotifierUnavailable
+
+	now := time.Date(2025, time.March, 12, 10, 30, 0, 0, time.UTC)
+	saveJob(t, s, chat.Job{ID: "1:codelab", ChatID: 1, UserID: 1, Kind: chat.JobCodelab, Spec: "*/30 * * * *", Next: now})
+
+	runDue(t, s, now)
+
+	if j, ok := job(t, s, "1:codelab"); !ok || !j.Next.Equal(now) {
+		t.Fatalf("job = %+v is not retried while the notifier is unavailable", j)
+	}
+
+	n.err = nil
+	runDue(t, s, now.Add(time.Minute))
+
+	if got := n.texts(1); len(got) != 1 {
+		t.Errorf("sent %q after the notifier is available, want one notice", got)
+	}
+}
+
+func TestSchedulerTransport(t *testing.T) {
+	s, n := newScheduler(t, chat.User{ID: 1}, chat.User{ID: 2}, chat.User{ID: 3})
+	s.Transport = server.TransportTelegram
+
+	now := time.Date(2025, time.March, 12, 10, 0, 0, 0, time.UTC)
+	saveJob(t, s, chat.Job{ID: "1:tip", ChatID: 1, UserID: 1, Kind: chat.JobTip, Spec: "0 10 * * *", Next: now, Transport: "telegram"})
+	saveJob(t, s, chat.Job{ID: "2:tip", ChatID: 2, UserID: 2, Kind: chat.JobTip, Spec: "0 10 * * *", Next: now, Transport: "websocket"})
+	saveJob(t, s, chat.Job{ID: "3:tip", ChatID: 3, UserID: 3, Kind: chat.JobTip, Spec: "0 10 * * *", Next: now})
+
+	runDue(t, s, now)
+
+	// Jobs of chats the notifier cannot reach are deleted.
+	if got := n.texts(2); len(got) != 0 {
+		t.Errorf("websocket job sent %q", got)
+	}
+	if _, ok := job(t, s, "2:tip"); ok {
+		t.Errorf("websocket job is not deleted")
+	}
+
+	// Jobs scheduled before the transport was recorded are delivered.
+	for _, chatID := range []int64{1, 3} {
+		if got := n.texts(chatID); len(got) != 1 {
+			t.Errorf("chat %d: sent %q, want one tip", chatID, got)
+		}
+	}
+}
+
+// testWriter redirects scheduler logs to the test log.
+type testWriter struct {
+	t testing.TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(p))
+	return len(p), nil
+}
@@ -0,0 +1,165 @@
+package servertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/muzykantov/health-gpt/chat"
+)
+
+// ErrUnexpectedCompletion is returned when the completer has no expectations left.
+var ErrUnexpectedCompletion = errors.New("unexpected completion request")
+
+// PromptCheck validates the messages received by the completer.
+type PromptCheck func(msgs []chat.Message) error
+
+// Expectation is a single scripted completion.
+type Expectation struct {
+	checks []PromptCheck
+	reply  chat.Message
+	err    error
+}
+
+// Reply sets the text returned for this completion.
+func (e *Expectation) Reply(text string) *Expectation {
+	e.reply = chat.MsgA(text)
+	return e
+}
+
+// Fail makes this completion return err.
+func (e *Expectation) Fail(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Completer is a scriptable server.ChatCompleter that asserts on received prompts.
+type Completer struct {
+	t testing.TB
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        [][]chat.Message
+}
+
+// NewCompleter creates a completer bound to the test.
+func NewCompleter(t testing.TB) *Completer {
+	c := &Completer{t: t}
+	t.Cleanup(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if len(c.expectations) > 0 {
+			t.Errorf("servertest: %d completion expectations were not met", len(c.expectations))
+		}
+	})
+
+	return c
+}
+
+// Expect adds the next expected completion with the given prompt checks.
+func (c *Completer) Expect(checks ...PromptCheck) *Expectation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &Expectation{checks: checks}
+	c.expectations = append(c.expectations, e)
+
+	return e
+}
+
+// Calls returns the messages of every completion request.
+func (c *Completer) Calls() [][]chat.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([][]chat.Message(nil), c.calls...)
+}
+
+// ModelName returns LLM's model name.
+func (c *Completer) ModelName() string {
+	return "servertest"
+}
+
+// SupportsImages reports that photos can be sent in user messages.
+func (c *Completer) SupportsImages() bool {
+	return true
+}
+
+// CompleteChat checks the prompt against the next expectation and returns its reply.
+func (c *Completer) CompleteChat(ctx context.Context, msgs []chat.Message) (chat.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, msgs)
+
+	if len(c.expectations) == 0 {
+		c.t.Errorf("servertest: unexpected completion request: %v", msgs)
+		return chat.EmptyMessage, ErrUnexpectedCompletion
+	}
+
+	e := c.expectations[0]
+	c.expectations = c.expectations[1:]
+
+	for _, check := range e.checks {
+		if err := check(msgs); err != nil {
+			c.t.Errorf("servertest: completion prompt check failed: %v", err)
+		}
+	}
+
+	if e.err != nil {
+		return chat.EmptyMessage, e.err
+	}
+
+	return e.reply, nil
+}
+
+// SystemPromptContains checks that the first message is a system prompt containing substr.
+func SystemPromptContains(substr string) PromptCheck {
+	return func(msgs []chat.Message) error {
+		if len(msgs) == 0 || msgs[0].Sender != chat.RoleSystem {
+			return errors.New("no system prompt")
+		}
+
+		if text, _ := msgs[0].Content.(string); !strings.Contains(text, substr) {
+			return fmt.Errorf("system prompt does not contain %q", substr)
+		}
+
+		return nil
+	}
+}
+
+// PromptContains checks that any message contains substr.
+func PromptContains(substr string) PromptCheck {
+	return func(msgs []chat.Message) error {
+		for _, msg := range msgs {
+			if text, ok := msg.Content.(string); ok && strings.Contains(text, substr) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("prompt does not contain %q", substr)
+	}
+}
+
+// LastUserMessage checks the text of the last message sent by the user.
+func LastUserMessage(text string) PromptCheck {
+	return func(msgs []chat.Message) error {
+		for i := len(msgs) - 1; i >= 0; i-- {
+			if msgs[i].Sender != chat.RoleUser {
+				continue
+			}
+
+			if msgs[i].Content != text {
+				return fmt.Errorf("last user message = %v, want %q", msgs[i].Content, text)
+			}
+
+			return nil
+		}
+
+		return errors.New("no user messages")
+	}
+}
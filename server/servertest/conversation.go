@@ -0,0 +1,265 @@
+package servertest
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// Conversation drives a handler turn by turn, like a transport would:
+//
+//	conv.Send("Привет").ExpectTextContaining("email")
+//	conv.Command("mygenetics").ExpectSelect(2).Press(2).ExpectTextContaining("Признак")
+type Conversation struct {
+	T         testing.TB
+	ChatID    int64
+	Handler   server.Handler
+	Completer server.ChatCompleter
+	Storage   server.DataStorage
+	Cache     server.Cache
+	Log       *log.Logger
+
+	// Synthesizer is passed to the handler if set.
+	Synthesizer server.Synthesizer
+
+	// Format is the markup of reports requested by the transport.
+	Format server.Format
+
+	// Transport is the transport of requests.
+	Transport server.Transport
+
+	// User is used when the user is not found in the storage.
+	User chat.User
+}
+
+// NewConversation creates a conversation with in-memory storage and cache.
+func NewConversation(t testing.TB, h server.Handler, completer server.ChatCompleter) *Conversation {
+	return &Conversation{
+		T:         t,
+		ChatID:    1,
+		Handler:   h,
+		Completer: completer,
+		Storage:   NewStorage(),
+		Cache:     expirable.NewLRU[string, any](0, nil, time.Hour),
+		Log:       log.New(testWriter{t}, "", 0),
+		User:      chat.User{ID: 1, FirstName: "Test"},
+	}
+}
+
+// Send sends a text message.
+func (c *Conversation) Send(text string) *Turn {
+	c.T.Helper()
+	return c.Serve(chat.MsgU(text))
+}
+
+// Command sends a command (without leading slash).
+func (c *Conversation) Command(name string, args ...string) *Turn {
+	c.T.Helper()
+	return c.Serve(chat.MsgU(content.Command{
+		Name: name,
+		Args: strings.Join(args, " "),
+	}))
+}
+
+// Serve runs the handler for the incoming message and records the responses.
+func (c *Conversation) Serve(incoming chat.Message) *Turn {
+	c.T.Helper()
+
+	ctx := context.Background()
+
+	from, err := c.Storage.GetUser(ctx, c.User.ID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			c.T.Fatalf("servertest: failed to get user: %v", err)
+		}
+		from = c.User
+	}
+
+	rec := &Recorder{}
+	c.Handler.Serve(ctx, rec, &server.Request{
+		ChatID:   c.ChatID,
+		Incoming: incoming,
+		From:     from,
+		Format:   c.Format,
+
+		Transport: c.Transport,
+
+		Completer: c.Completer,
+		Storage:   c.Storage,
+		Cache:     c.Cache,
+		Log:       c.Log,
+
+		Synthesizer: c.Synthesizer,
+	})
+
+	return &Turn{conv: c, msgs: rec.Messages()}
+}
+
+// Turn holds the responses to a single incoming message.
+type Turn struct {
+	conv     *Conversation
+	msgs     []chat.Message
+	selected *content.Select
+}
+
+// Messages returns all responses of the turn.
+func (t *Turn) Messages() []chat.Message {
+	return t.msgs
+}
+
+// Texts returns text responses of the turn, including select headers.
+func (t *Turn) Texts() []string {
+	var out []string
+	for _, msg := range t.msgs {
+		switch msgContent := msg.Content.(type) {
+		case string:
+			out = append(out, msgContent)
+		case content.Select:
+			out = append(out, msgContent.Header)
+		}
+	}
+
+	return out
+}
+
+// Documents returns document responses of the turn.
+func (t *Turn) Documents() []content.Document {
+	var out []content.Document
+	for _, msg := range t.msgs {
+		if document, ok := msg.Content.(content.Document); ok {
+			out = append(out, document)
+		}
+	}
+
+	return out
+}
+
+// Voices returns voice responses of the turn.
+func (t *Turn) Voices() []content.Voice {
+	var out []content.Voice
+	for _, msg := range t.msgs {
+		if voice, ok := msg.Content.(content.Voice); ok {
+			out = append(out, voice)
+		}
+	}
+
+	return out
+}
+
+// ExpectTextContaining fails the test if no text response contains substr.
+func (t *Turn) ExpectTextContaining(substr string) *Turn {
+	t.conv.T.Helper()
+
+	for _, text := range t.Texts() {
+		if strings.Contains(text, substr) {
+			return t
+		}
+	}
+
+	t.conv.T.Fatalf("servertest: no text response contains %q, got: %q", substr, t.Texts())
+	return t
+}
+
+// ExpectNoTextContaining fails the test if any text response contains substr.
+func (t *Turn) ExpectNoTextContaining(substr string) *Turn {
+	t.conv.T.Helper()
+
+	for _, text := range t.Texts() {
+		if strings.Contains(text, substr) {
+			t.conv.T.Fatalf("servertest: unexpected text response %q", text)
+		}
+	}
+
+	return t
+}
+
+// ExpectCommands fails the test if the commands list was not updated.
+func (t *Turn) ExpectCommands(names ...string) *Turn {
+	t.conv.T.Helper()
+
+	for _, msg := range t.msgs {
+		commands, ok := msg.Content.(content.Commands)
+		if !ok {
+			continue
+		}
+
+		got := make(map[string]bool, len(commands.Items))
+		for _, item := range commands.Items {
+			got[item.Name] = true
+		}
+
+		missing := false
+		for _, name := range names {
+			if !got[name] {
+				missing = true
+			}
+		}
+
+		if !missing {
+			return t
+		}
+	}
+
+	t.conv.T.Fatalf("servertest: no commands response with %v", names)
+	return t
+}
+
+// ExpectSelect finds the first select with n items; it is used by Press.
+func (t *Turn) ExpectSelect(n int) *Turn {
+	t.conv.T.Helper()
+	return t.ExpectSelectWith(func(s content.Select) bool { return len(s.Items) == n })
+}
+
+// ExpectSelectWith finds the first select matching the predicate; it is used by Press.
+func (t *Turn) ExpectSelectWith(match func(content.Select) bool) *Turn {
+	t.conv.T.Helper()
+
+	for _, msg := range t.msgs {
+		if s, ok := msg.Content.(content.Select); ok && match(s) {
+			t.selected = &s
+			return t
+		}
+	}
+
+	t.conv.T.Fatalf("servertest: no matching select in responses: %v", t.msgs)
+	return t
+}
+
+// Press presses item i (1-based) of the select found by ExpectSelect.
+func (t *Turn) Press(i int) *Turn {
+	t.conv.T.Helper()
+
+	if t.selected == nil {
+		t.conv.T.Fatalf("servertest: Press called before ExpectSelect")
+	}
+
+	if i < 1 || i > len(t.selected.Items) {
+		t.conv.T.Fatalf("servertest: select has %d items, cannot press %d", len(t.selected.Items), i)
+	}
+
+	item := t.selected.Items[i-1]
+	if item.URL != "" {
+		t.conv.T.Fatalf("servertest: item %d is a link to %s and cannot be pressed", i, item.URL)
+	}
+
+	return t.conv.Serve(chat.MsgU(item))
+}
+
+// testWriter redirects handler logs to the test log.
+type testWriter struct {
+	t testing.TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
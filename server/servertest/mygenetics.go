@@ -0,0 +1,279 @@
+package servertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muzykantov/health-gpt/genetics"
+	"github.com/muzykantov/health-gpt/mygenetics"
+)
+
+// tokenFormat is the expires format of MyGenetics cookies.
+const tokenFormat = "Mon, 02 Jan 2006 15:04:05 MST"
+
+// MyGenetics is a fake MyGenetics API serving predefined codelabs.
+type MyGenetics struct {
+	*httptest.Server
+
+	Email    string
+	Password string
+
+	mu       sync.Mutex
+	codelabs []mygenetics.Codelab
+	features map[string]genetics.FeatureSet
+}
+
+// NewMyGenetics starts a fake MyGenetics API accepting the given credentials.
+func NewMyGenetics(email, password string) *MyGenetics {
+	m := &MyGenetics{
+		Email:    email,
+		Password: password,
+		features: make(map[string]genetics.FeatureSet),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/auth/login", m.login)
+	mux.HandleFunc("GET /api/v2/auth/renew", m.renew)
+	mux.HandleFunc("GET /api/v2/tests/", m.tests)
+	mux.HandleFunc("GET /api/v2/codelabs/{code}", m.codelab)
+	m.Server = httptest.NewServer(mux)
+
+	return m
+}
+
+// AddCodelab registers an activated codelab with its features and
+// the "done" status.
+func (m *MyGenetics) AddCodelab(code, name string, features genetics.FeatureSet) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.codelabs = append(m.codelabs, mygenetics.Codelab{
+		Code:      code,
+		Name:      name,
+		Status:    "done",
+		Activated: true,
+	})
+	m.features[code] = features
+}
+
+// SetStatus changes the status and activation of a registered codelab.
+func (m *MyGenetics) SetStatus(code, status string, activated bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, codelab := range m.codelabs {
+		if codelab.Code == code {
+			m.codelabs[i].Status = status
+			m.codelabs[i].Activated = activated
+		}
+	}
+}
+
+// Client returns a client talking to the fake API.
+func (m *MyGenetics) Client() *mygenetics.Client {
+	return &mygenetics.Client{Client: m.Server.Client(), BaseURL: m.URL}
+}
+
+// Tokens returns valid tokens as if the user has logged in.
+func (m *MyGenetics) Tokens() []mygenetics.Token {
+	expires := time.Now().Add(time.Hour).UTC().Format(tokenFormat)
+	return []mygenetics.Token{
+		mygenetics.Token("accessToken=access; expires=" + expires + "; path=/"),
+		mygenetics.Token("refreshToken=refresh; expires=" + expires + "; path=/"),
+	}
+}
+
+// ExpiredTokens returns an expired access token and a valid refresh token,
+// as if the user has logged in long ago.
+func (m *MyGenetics) ExpiredTokens() []mygenetics.Token {
+	expired := time.Now().Add(-time.Hour).UTC().Format(tokenFormat)
+	expires := time.Now().Add(time.Hour).UTC().Format(tokenFormat)
+	return []mygenetics.Token{
+		mygenetics.Token("accessToken=expired; expires=" + expired + "; path=/"),
+		mygenetics.Token("refreshToken=refresh; expires=" + expires + "; path=/"),
+	}
+}
+
+// Install replaces mygenetics.DefaultClient for the duration of the test.
+func (m *MyGenetics) Install(t testing.TB) {
+	prev := mygenetics.DefaultClient
+	mygenetics.DefaultClient = m.Client()
+
+	t.Cleanup(func() {
+		mygenetics.DefaultClient = prev
+		m.Close()
+	})
+}
+
+func (m *MyGenetics) login(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Email != m.Email || req.Password != m.Password {
+		http.Error(w, `{"code":"invalid_credentials"}`, http.StatusUnauthorized)
+		return
+	}
+
+	m.writeTokens(w)
+}
+
+func (m *MyGenetics) renew(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Cookie"), "refreshToken=") {
+		http.Error(w, `{"code":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	m.writeTokens(w)
+}
+
+func (m *MyGenetics) writeTokens(w http.ResponseWriter) {
+	for _, token := range m.Tokens() {
+		w.Header().Add("Set-Cookie", string(token))
+	}
+
+	writeSuccess(w, struct{}{})
+}
+
+func (m *MyGenetics) tests(w http.ResponseWriter, r *http.Request) {
+	if !authorized(r) {
+		http.Error(w, `{"code":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type profile struct {
+		Name string `json:"name"`
+	}
+	type test struct {
+		ID           int     `json:"id"`
+		CodeLab      string  `json:"codeLab"`
+		Activated    bool    `json:"activated"`
+		SystemStatus string  `json:"systemStatus"`
+		Profile      profile `json:"profile"`
+	}
+
+	out := make([]test, 0, len(m.codelabs))
+	for i, codelab := range m.codelabs {
+		out = append(out, test{
+			ID:           i + 1,
+			CodeLab:      codelab.Code,
+			Activated:    codelab.Activated,
+			SystemStatus: codelab.Status,
+			Profile:      profile{Name: codelab.Name},
+		})
+	}
+
+	writeSuccess(w, out)
+}
+
+func (m *MyGenetics) codelab(w http.ResponseWriter, r *http.Request) {
+	if !authorized(r) {
+		http.Error(w, `{"code":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	m.mu.Lock()
+	features, ok := m.features[r.PathValue("code")]
+	m.mu.Unlock()
+
+	if !ok {
+		http.Error(w, `{"code":"not_found"}`, http.StatusNotFound)
+		return
+	}
+
+	// Payload layout understood by the "with conclusion" parser.
+	signs := make(map[string]map[string]any, len(features))
+	for _, feature := range features {
+		genes := make(map[string]any, len(feature.Genes))
+		for _, gene := range feature.Genes {
+			genes[gene.Name] = withOptional(map[string]any{
+				"interpretation": gene.Interpretations,
+			}, map[string]string{
+				"rsid":     gene.RSID,
+				"genotype": gene.Genotype,
+				"effect":   string(gene.Effect),
+				"risk":     gene.Risk.String(),
+				"evidence": string(gene.Evidence),
+			})
+		}
+
+		categories := make([]string, 0, len(feature.Categories))
+		for _, category := range feature.Categories {
+			categories = append(categories, string(category))
+		}
+
+		signs[feature.Name] = map[string]any{
+			"conclusion": withOptional(map[string]any{
+				"conclusion": map[string]any{
+					"conclusion": nonNil(feature.Conclusions),
+				},
+				"genes": genes,
+				"recommendation": map[string]any{
+					"nutrition":  nonNil(feature.Nutrition),
+					"additional": nonNil(feature.Additional),
+					"checklist":  nonNil(feature.Checklist),
+				},
+			}, map[string]string{
+				"risk":     feature.Risk.String(),
+				"category": strings.Join(categories, ","),
+			}),
+		}
+	}
+
+	writeSuccess(w, map[string]any{
+		"files": map[string]any{
+			"payload": map[string]any{
+				"signs": signs,
+			},
+		},
+	})
+}
+
+func authorized(r *http.Request) bool {
+	cookie := r.Header.Get("Cookie")
+	return strings.Contains(cookie, "accessToken=") && !strings.Contains(cookie, "accessToken=expired")
+}
+
+func writeSuccess(w http.ResponseWriter, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"code":"success","data":%s}`, raw)
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+
+	return items
+}
+
+// withOptional adds non-empty optional fields to a payload object.
+func withOptional(value map[string]any, optional map[string]string) map[string]any {
+	for key, v := range optional {
+		if v != "" {
+			value[key] = v
+		}
+	}
+
+	return value
+}
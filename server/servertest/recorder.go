@@ -0,0 +1,43 @@
+// Package servertest provides utilities for testing server.Handler implementations.
+package servertest
+
+import (
+	"sync"
+
+	"github.com/muzykantov/health-gpt/chat"
+)
+
+// Recorder is a server.ResponseWriter that records all written messages.
+type Recorder struct {
+	mu   sync.Mutex
+	msgs []chat.Message
+}
+
+// WriteResponse records the message.
+func (r *Recorder) WriteResponse(m chat.Message) error {
+	if m.IsEmpty() {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.msgs = append(r.msgs, m)
+	return nil
+}
+
+// Messages returns a copy of recorded messages.
+func (r *Recorder) Messages() []chat.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]chat.Message(nil), r.msgs...)
+}
+
+// Reset forgets recorded messages.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.msgs = nil
+}
@@ -0,0 +1,229 @@
+package servertest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/storage"
+)
+
+// Storage is an in-memory server.DataStorage.
+type Storage struct {
+	mu        sync.RWMutex
+	histories map[int64][]chat.Message
+	states    map[int64]chat.State
+	users     map[int64]chat.User
+	plans     map[int64]chat.Plan
+	diaries   map[string]chat.Diary
+	journals  map[int64]chat.Journal
+	ratings   map[string]chat.Rating
+	jobs      map[string]chat.Job
+}
+
+// NewStorage creates an empty in-memory storage.
+func NewStorage() *Storage {
+	return &Storage{
+		histories: make(map[int64][]chat.Message),
+		states:    make(map[int64]chat.State),
+		users:     make(map[int64]chat.User),
+		plans:     make(map[int64]chat.Plan),
+		diaries:   make(map[string]chat.Diary),
+		journals:  make(map[int64]chat.Journal),
+		ratings:   make(map[string]chat.Rating),
+		jobs:      make(map[string]chat.Job),
+	}
+}
+
+// GetChatHistory returns the last limit messages (all if limit is 0).
+func (s *Storage) GetChatHistory(
+	ctx context.Context,
+	chatID int64,
+	limit uint64,
+) ([]chat.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	msgs := append([]chat.Message{}, s.histories[chatID]...)
+	if limit == 0 || uint64(len(msgs)) <= limit {
+		return msgs, nil
+	}
+
+	return msgs[uint64(len(msgs))-limit:], nil
+}
+
+// SaveChatHistory replaces the chat history.
+func (s *Storage) SaveChatHistory(
+	ctx context.Context,
+	chatID int64,
+	msgs []chat.Message,
+) error {
+	for _, msg := range msgs {
+		if _, ok := msg.Content.(string); !ok {
+			return storage.ErrUnsupportedContentType
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.histories[chatID] = append([]chat.Message{}, msgs...)
+	return nil
+}
+
+// GetChatState returns the chat state.
+func (s *Storage) GetChatState(ctx context.Context, chatID int64) (chat.State, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.states[chatID], nil
+}
+
+// SaveChatState saves the chat state.
+func (s *Storage) SaveChatState(ctx context.Context, chatID int64, state chat.State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[chatID] = state
+	return nil
+}
+
+// GetUser returns the user or storage.ErrUserNotFound.
+func (s *Storage) GetUser(ctx context.Context, userID int64) (chat.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return chat.User{}, storage.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// SaveUser saves the user.
+func (s *Storage) SaveUser(ctx context.Context, user chat.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[user.ID] = user
+	return nil
+}
+
+// GetPlan returns the action plan.
+func (s *Storage) GetPlan(ctx context.Context, userID int64) (chat.Plan, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.plans[userID], nil
+}
+
+// SavePlan saves the action plan.
+func (s *Storage) SavePlan(ctx context.Context, userID int64, plan chat.Plan) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.plans[userID] = plan
+	return nil
+}
+
+// GetDiary returns the food diary of the day.
+func (s *Storage) GetDiary(ctx context.Context, userID int64, date string) (chat.Diary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	diary, ok := s.diaries[fmt.Sprintf("%d:%s", userID, date)]
+	if !ok {
+		return chat.Diary{Date: date}, nil
+	}
+
+	return diary, nil
+}
+
+// SaveDiary saves the food diary of the day.
+func (s *Storage) SaveDiary(ctx context.Context, userID int64, diary chat.Diary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.diaries[fmt.Sprintf("%d:%s", userID, diary.Date)] = diary
+	return nil
+}
+
+// GetJournal returns the wellbeing journal of the user.
+func (s *Storage) GetJournal(ctx context.Context, userID int64) (chat.Journal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.journals[userID], nil
+}
+
+// SaveJournal saves the wellbeing journal of the user.
+func (s *Storage) SaveJournal(ctx context.Context, userID int64, journal chat.Journal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.journals[userID] = journal
+	return nil
+}
+
+// GetRating returns the rating of the answer with the given ID.
+func (s *Storage) GetRating(ctx context.Context, id string) (chat.Rating, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ratings[id], nil
+}
+
+// GetRatings returns all answer ratings.
+func (s *Storage) GetRatings(ctx context.Context) ([]chat.Rating, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ratings := make([]chat.Rating, 0, len(s.ratings))
+	for _, rating := range s.ratings {
+		ratings = append(ratings, rating)
+	}
+
+	return ratings, nil
+}
+
+// SaveRating saves the rating of an answer.
+func (s *Storage) SaveRating(ctx context.Context, rating chat.Rating) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ratings[rating.ID] = rating
+	return nil
+}
+
+// GetJobs returns all scheduler jobs.
+func (s *Storage) GetJobs(ctx context.Context) ([]chat.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]chat.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// SaveJob saves the scheduler job.
+func (s *Storage) SaveJob(ctx context.Context, job chat.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// DeleteJob deletes the scheduler job.
+func (s *Storage) DeleteJob(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.jobs, id)
+	return nil
+}
@@ -0,0 +1,55 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/muzykantov/health-gpt/chat/content"
+)
+
+// maxPhotoSize limits the size of downloaded photos.
+const maxPhotoSize = 10 << 20
+
+// messagePhoto returns the ID of the largest size of the photo attached to
+// the message, or an empty string.
+func messagePhoto(m *tgbotapi.Message) string {
+	var (
+		id   string
+		area int
+	)
+	for _, size := range m.Photo {
+		if size.Width*size.Height > area {
+			id, area = size.FileID, size.Width*size.Height
+		}
+	}
+
+	return id
+}
+
+// downloadPhoto downloads the photo from Telegram. Telegram recompresses
+// photos to JPEG.
+func downloadPhoto(
+	ctx context.Context,
+	bot *tgbotapi.BotAPI,
+	fileEndpoint string,
+	fileID string,
+	caption string,
+) (content.Photo, error) {
+	body, err := download(ctx, bot, fileEndpoint, fileID)
+	if err != nil {
+		return content.Photo{}, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxPhotoSize+1))
+	if err != nil {
+		return content.Photo{}, fmt.Errorf("read photo: %w", err)
+	}
+	if len(data) > maxPhotoSize {
+		return content.Photo{}, fmt.Errorf("photo is larger than %d bytes", maxPhotoSize)
+	}
+
+	return content.Photo{Data: data, MIME: "image/jpeg", Caption: caption}, nil
+}
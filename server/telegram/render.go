@@ -0,0 +1,395 @@
+package telegram
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is the Telegram limit for text message length.
+const MaxMessageLength = 4096
+
+// allowedTags lists HTML tags supported by Telegram, mapped to their canonical names.
+var allowedTags = map[string]string{
+	"b":          "b",
+	"strong":     "b",
+	"i":          "i",
+	"em":         "i",
+	"u":          "u",
+	"ins":        "u",
+	"s":          "s",
+	"strike":     "s",
+	"del":        "s",
+	"code":       "code",
+	"pre":        "pre",
+	"a":          "a",
+	"blockquote": "blockquote",
+	"tg-spoiler": "tg-spoiler",
+}
+
+var (
+	tagPattern    = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9-]*)((?:\s+[^<>]*)?)\s*/?>`)
+	hrefPattern   = regexp.MustCompile(`(?i)href\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+	entityPattern = regexp.MustCompile(`^&(?:[a-zA-Z]+|#[0-9]+|#x[0-9a-fA-F]+);`)
+
+	mdHeading = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t]*#*[ \t]*$`)
+	mdBullet  = regexp.MustCompile(`(?m)^([ \t]*)[-*+][ \t]+`)
+	mdLink    = regexp.MustCompile(`\[([^\[\]\n]+)\]\((https?://[^\s()]+)\)`)
+	mdBold    = regexp.MustCompile(`\*\*([^*\n]+?)\*\*|__([^_\n]+?)__`)
+	mdItalic  = regexp.MustCompile(`(^|[\s(])\*([^\s*](?:[^*\n]*[^\s*])?)\*([\s).,:;!?]|$)`)
+	mdItalicU = regexp.MustCompile(`(^|[\s(])_([^\s_](?:[^_\n]*[^\s_])?)_([\s).,:;!?]|$)`)
+	mdStrike  = regexp.MustCompile(`~~([^~\n]+?)~~`)
+)
+
+// htmlToken is a piece of Telegram HTML: a tag, an entity or a text run.
+type htmlToken struct {
+	text    string
+	tag     string // Canonical tag name for tags.
+	closing bool
+}
+
+// RenderHTML converts model output (Markdown, possibly mixed with HTML)
+// into Telegram-safe HTML: supported tags are kept, everything else
+// is escaped, Markdown markup is converted and tags are balanced.
+func RenderHTML(s string) string {
+	var sb strings.Builder
+
+	for _, tok := range sanitize(s) {
+		switch {
+		case tok.tag != "":
+			sb.WriteString(tok.text)
+		default:
+			sb.WriteString(renderMarkdown(tok.text))
+		}
+	}
+
+	return balance(sb.String())
+}
+
+// PlainText strips HTML markup, used when Telegram rejects the markup.
+func PlainText(s string) string {
+	var sb strings.Builder
+	for _, tok := range tokenize(s) {
+		if tok.tag == "" {
+			sb.WriteString(tok.text)
+		}
+	}
+
+	return html.UnescapeString(sb.String())
+}
+
+// SplitHTML splits Telegram HTML into messages of at most limit characters,
+// preferring paragraph, then line, then word boundaries. Tags open at a cut
+// are closed at the end of the chunk and reopened in the next one.
+func SplitHTML(s string, limit int) []string {
+	if utf8.RuneCountInString(s) <= limit {
+		return []string{s}
+	}
+
+	var (
+		tokens = tokenize(s)
+		chunks []string
+		stack  []htmlToken // Tags open at the start of the current chunk.
+		start  int
+	)
+
+	for start < len(tokens) {
+		var (
+			current  = append([]htmlToken(nil), stack...)
+			length   = openLength(current)
+			cut      = -1
+			cutStack []htmlToken
+			cutRank  int
+		)
+
+		i := start
+		for ; i < len(tokens); i++ {
+			tok := tokens[i]
+
+			next := current
+			switch {
+			case tok.tag != "" && tok.closing:
+				next = popTag(current, tok.tag)
+			case tok.tag != "":
+				next = append(append([]htmlToken(nil), current...), tok)
+			}
+
+			tokLen := utf8.RuneCountInString(tok.text)
+			if length+tokLen+closeLength(next) > limit && i > start {
+				break
+			}
+
+			// Remember the best place to cut before this token.
+			if rank := breakRank(tokens, i); rank > 0 && i > start && rank >= cutRank {
+				cut, cutStack, cutRank = i, current, rank
+			}
+
+			current = next
+			length += tokLen
+		}
+
+		if i >= len(tokens) {
+			cut, cutStack = len(tokens), current
+		} else if cut == -1 {
+			cut, cutStack = i, current
+		}
+
+		var sb strings.Builder
+		for _, tok := range stack {
+			sb.WriteString(tok.text)
+		}
+		for _, tok := range tokens[start:cut] {
+			sb.WriteString(tok.text)
+		}
+		for j := len(cutStack) - 1; j >= 0; j-- {
+			sb.WriteString("</" + cutStack[j].tag + ">")
+		}
+
+		if chunk := strings.TrimSpace(sb.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+
+		start, stack = cut, cutStack
+	}
+
+	return chunks
+}
+
+// sanitize splits the input into allowed tags and text. Unknown tags and
+// stray angle brackets are left in text and escaped later.
+func sanitize(s string) []htmlToken {
+	var (
+		out  []htmlToken
+		text strings.Builder
+	)
+
+	flush := func() {
+		if text.Len() > 0 {
+			out = append(out, htmlToken{text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] == '<' {
+			if m := tagPattern.FindStringSubmatch(s[i:]); m != nil {
+				if name, ok := allowedTags[strings.ToLower(m[2])]; ok {
+					flush()
+					out = append(out, newTag(name, m[1] == "/", m[3]))
+					i += len(m[0])
+					continue
+				}
+			}
+		}
+
+		if s[i] == '&' {
+			if m := entityPattern.FindString(s[i:]); m != "" {
+				// Keep known entities decoded, they are escaped back with the text.
+				text.WriteString(html.UnescapeString(m))
+				i += len(m)
+				continue
+			}
+		}
+
+		text.WriteByte(s[i])
+		i++
+	}
+	flush()
+
+	return out
+}
+
+// newTag creates a canonical tag token, keeping only href of links.
+func newTag(name string, closing bool, attrs string) htmlToken {
+	if closing {
+		return htmlToken{text: "</" + name + ">", tag: name, closing: true}
+	}
+
+	if name == "a" {
+		href := ""
+		if m := hrefPattern.FindStringSubmatch(attrs); m != nil {
+			href = strings.Trim(m[1], `"'`)
+		}
+		return htmlToken{
+			text: `<a href="` + html.EscapeString(html.UnescapeString(href)) + `">`,
+			tag:  name,
+		}
+	}
+
+	return htmlToken{text: "<" + name + ">", tag: name}
+}
+
+// renderMarkdown escapes text and converts common Markdown markup to HTML.
+func renderMarkdown(s string) string {
+	var sb strings.Builder
+
+	// Fenced code blocks are escaped verbatim.
+	parts := strings.Split(s, "```")
+	for i, part := range parts {
+		if i%2 == 1 && i < len(parts)-1 {
+			// Drop the language name on the opening fence line.
+			if nl := strings.IndexByte(part, '\n'); nl != -1 && !strings.ContainsAny(part[:nl], " \t") {
+				part = part[nl+1:]
+			}
+			sb.WriteString("<pre>" + html.EscapeString(strings.TrimRight(part, "\n")) + "</pre>")
+			continue
+		}
+
+		if i%2 == 1 {
+			// Unterminated fence: keep backticks as text.
+			part = "```" + part
+		}
+
+		// Inline code spans are escaped verbatim.
+		spans := strings.Split(part, "`")
+		for j, span := range spans {
+			if j%2 == 1 && j < len(spans)-1 {
+				sb.WriteString("<code>" + html.EscapeString(span) + "</code>")
+				continue
+			}
+
+			if j%2 == 1 {
+				span = "`" + span
+			}
+			sb.WriteString(renderInline(html.EscapeString(span)))
+		}
+	}
+
+	return sb.String()
+}
+
+// renderInline converts inline Markdown of already escaped text.
+func renderInline(s string) string {
+	s = mdHeading.ReplaceAllString(s, "<b>$1</b>")
+	s = mdBullet.ReplaceAllString(s, "$1• ")
+	s = mdLink.ReplaceAllString(s, `<a href="$2">$1</a>`)
+	s = mdBold.ReplaceAllString(s, "<b>$1$2</b>")
+	s = mdStrike.ReplaceAllString(s, "<s>$1</s>")
+	s = mdItalic.ReplaceAllString(s, "$1<i>$2</i>$3")
+	s = mdItalicU.ReplaceAllString(s, "$1<i>$2</i>$3")
+
+	return s
+}
+
+// balance fixes tag nesting: unmatched closing tags are dropped, tags closed
+// out of order close the tags opened inside them, open tags are closed at the end.
+func balance(s string) string {
+	var (
+		sb    strings.Builder
+		stack []htmlToken
+	)
+
+	for _, tok := range tokenize(s) {
+		switch {
+		case tok.tag == "":
+			sb.WriteString(tok.text)
+
+		case !tok.closing:
+			stack = append(stack, tok)
+			sb.WriteString(tok.text)
+
+		default:
+			pos := -1
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j].tag == tok.tag {
+					pos = j
+					break
+				}
+			}
+
+			if pos == -1 {
+				continue
+			}
+
+			for j := len(stack) - 1; j >= pos; j-- {
+				sb.WriteString("</" + stack[j].tag + ">")
+			}
+			stack = stack[:pos]
+		}
+	}
+
+	for j := len(stack) - 1; j >= 0; j-- {
+		sb.WriteString("</" + stack[j].tag + ">")
+	}
+
+	return sb.String()
+}
+
+// tokenize splits rendered Telegram HTML into tags, entities and single runes.
+func tokenize(s string) []htmlToken {
+	var out []htmlToken
+
+	for i := 0; i < len(s); {
+		if s[i] == '<' {
+			if m := tagPattern.FindStringSubmatch(s[i:]); m != nil {
+				name := allowedTags[strings.ToLower(m[2])]
+				out = append(out, htmlToken{text: m[0], tag: name, closing: m[1] == "/"})
+				i += len(m[0])
+				continue
+			}
+		}
+
+		if s[i] == '&' {
+			if m := entityPattern.FindString(s[i:]); m != "" {
+				out = append(out, htmlToken{text: m})
+				i += len(m)
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		out = append(out, htmlToken{text: s[i : i+size]})
+		i += size
+	}
+
+	return out
+}
+
+// breakRank ranks the position before tokens[i] as a cut point:
+// 3 - paragraph, 2 - line, 1 - word, 0 - not a boundary.
+func breakRank(tokens []htmlToken, i int) int {
+	if i == 0 || tokens[i-1].tag != "" {
+		return 0
+	}
+
+	prev := tokens[i-1].text
+	switch {
+	case prev == "\n" && i >= 2 && tokens[i-2].text == "\n":
+		return 3
+	case prev == "\n":
+		return 2
+	case prev == " ":
+		return 1
+	default:
+		return 0
+	}
+}
+
+func popTag(stack []htmlToken, tag string) []htmlToken {
+	for j := len(stack) - 1; j >= 0; j-- {
+		if stack[j].tag == tag {
+			return append([]htmlToken(nil), stack[:j]...)
+		}
+	}
+
+	return stack
+}
+
+func openLength(stack []htmlToken) int {
+	n := 0
+	for _, tok := range stack {
+		n += utf8.RuneCountInString(tok.text)
+	}
+
+	return n
+}
+
+func closeLength(stack []htmlToken) int {
+	n := 0
+	for _, tok := range stack {
+		n += len("</>") + len(tok.tag)
+	}
+
+	return n
+}
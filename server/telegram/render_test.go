@@ -0,0 +1,153 @@
+package telegram_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/muzykantov/health-gpt/server/telegram"
+)
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "stray angle brackets",
+			in:   "a < b && c > d",
+			want: "a &lt; b &amp;&amp; c &gt; d",
+		},
+		{
+			name: "allowed tags",
+			in:   "<b>bold</b> <strong>strong</strong> <a href=\"https://example.com\" target=\"_blank\">link</a>",
+			want: "<b>bold</b> <b>strong</b> <a href=\"https://example.com\">link</a>",
+		},
+		{
+			name: "unknown tags",
+			in:   "<div>text</div><br/>",
+			want: "&lt;div&gt;text&lt;/div&gt;&lt;br/&gt;",
+		},
+		{
+			name: "entities",
+			in:   "Tom &amp; Jerry &lt;3",
+			want: "Tom &amp; Jerry &lt;3",
+		},
+		{
+			name: "markdown",
+			in:   "## Итог\n- **Кофе**: *умеренно*\n- `CYP1A2` ~~быстро~~",
+			want: "<b>Итог</b>\n• <b>Кофе</b>: <i>умеренно</i>\n• <code>CYP1A2</code> <s>быстро</s>",
+		},
+		{
+			name: "markdown link",
+			in:   "[MyGenetics](https://mygenetics.ru)",
+			want: "<a href=\"https://mygenetics.ru\">MyGenetics</a>",
+		},
+		{
+			name: "code block",
+			in:   "```go\nif a < b {\n}\n```",
+			want: "<pre>if a &lt; b {\n}</pre>",
+		},
+		{
+			name: "snake case",
+			in:   "snake_case_name and 2*3*4",
+			want: "snake_case_name and 2*3*4",
+		},
+		{
+			name: "unclosed tags",
+			in:   "<b>bold <i>italic",
+			want: "<b>bold <i>italic</i></b>",
+		},
+		{
+			name: "misnested tags",
+			in:   "<b>bold <i>both</b> italic</i>",
+			want: "<b>bold <i>both</i></b> italic",
+		},
+		{
+			name: "unmatched closing tag",
+			in:   "text</b>",
+			want: "text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := telegram.RenderHTML(tt.in); got != tt.want {
+				t.Errorf("RenderHTML(%q)\n got: %q\nwant: %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := telegram.PlainText("<b>a &lt; b</b> <a href=\"https://example.com\">link</a>")
+	if want := "a < b link"; got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitHTML(t *testing.T) {
+	t.Run("short", func(t *testing.T) {
+		got := telegram.SplitHTML("<b>short</b>", 100)
+		if len(got) != 1 || got[0] != "<b>short</b>" {
+			t.Errorf("SplitHTML() = %q", got)
+		}
+	})
+
+	t.Run("paragraphs", func(t *testing.T) {
+		paragraph := strings.Repeat("слово ", 10)
+		in := strings.TrimSpace(paragraph) + "\n\n" + strings.TrimSpace(paragraph) + "\n\n" + strings.TrimSpace(paragraph)
+
+		got := telegram.SplitHTML(in, 130)
+		if len(got) != 2 {
+			t.Fatalf("SplitHTML() returned %d chunks: %q", len(got), got)
+		}
+
+		for _, chunk := range got {
+			if strings.HasPrefix(chunk, "\n") || strings.HasSuffix(chunk, "\n") {
+				t.Errorf("chunk is not trimmed: %q", chunk)
+			}
+		}
+
+		if strings.Join(got, "\n\n") != in {
+			t.Errorf("chunks were not cut at a paragraph boundary: %q", got)
+		}
+	})
+
+	t.Run("balanced tags", func(t *testing.T) {
+		in := "<b>" + strings.Repeat("жирный текст ", 50) + "</b> <i>" + strings.Repeat("курсив ", 50) + "</i>"
+
+		const limit = 100
+		got := telegram.SplitHTML(in, limit)
+		if len(got) < 2 {
+			t.Fatalf("SplitHTML() returned %d chunks", len(got))
+		}
+
+		var plain strings.Builder
+		for i, chunk := range got {
+			if n := utf8.RuneCountInString(chunk); n > limit {
+				t.Errorf("chunk %d has %d characters, limit %d", i, n, limit)
+			}
+
+			if telegram.RenderHTML(chunk) != chunk {
+				t.Errorf("chunk %d is not balanced: %q", i, chunk)
+			}
+
+			plain.WriteString(telegram.PlainText(chunk) + " ")
+		}
+
+		if strings.Join(strings.Fields(plain.String()), " ") != strings.Join(strings.Fields(telegram.PlainText(in)), " ") {
+			t.Error("text was lost while splitting")
+		}
+	})
+
+	t.Run("no boundaries", func(t *testing.T) {
+		in := strings.Repeat("a", 250)
+
+		got := telegram.SplitHTML(in, 100)
+		if len(got) != 3 || strings.Join(got, "") != in {
+			t.Errorf("SplitHTML() = %q", got)
+		}
+	})
+}
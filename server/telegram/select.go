@@ -0,0 +1,216 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/metrics"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// Callbacks with these data are handled by the server and never reach the
+// handler: pageCallbackPrefix marks navigation buttons of paginated selects,
+// selectedCallback marks the chosen item left by markSelected.
+const (
+	pageCallbackPrefix = "tg_page:"
+	selectedCallback   = "tg_selected"
+)
+
+// selectCachePrefix prefixes cache keys of paginated selects.
+const selectCachePrefix = "tg_select:"
+
+// selectPager sends selects with more than size items page by page.
+// Selects are kept in the request cache, so navigation survives restarts
+// when the cache is persistent.
+type selectPager struct {
+	size  int
+	cache server.Cache
+}
+
+func newSelectPager(size int, cache server.Cache) *selectPager {
+	return &selectPager{size: size, cache: cache}
+}
+
+// paginated reports whether the select does not fit on a single page.
+func (p *selectPager) paginated(s content.Select) bool {
+	return p != nil && p.size > 0 && len(s.Items) > p.size
+}
+
+// send sends the first page of the select and remembers it for navigation.
+func (p *selectPager) send(sender *tgbotapi.BotAPI, chatID int64, s content.Select) error {
+	sent, err := sendSelect(sender, chatID, s.Header, selectMarkup(s, 0, p.size))
+	if err != nil {
+		metrics.RecordTelegramError("send_select")
+		return err
+	}
+
+	// The select is stored as JSON: a persistent cache does not preserve
+	// the types of stored values.
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode select: %w", err)
+	}
+
+	p.cache.Add(selectKey(chatID, sent.MessageID), string(data))
+	metrics.TelegramMessagesTotal.WithLabelValues("sent_select").Inc()
+
+	return nil
+}
+
+// turn shows the page requested by a navigation callback.
+func (p *selectPager) turn(sender *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery) error {
+	page, err := strconv.Atoi(strings.TrimPrefix(query.Data, pageCallbackPrefix))
+	if err != nil {
+		return fmt.Errorf("invalid page callback %q: %w", query.Data, err)
+	}
+
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+
+	s, ok := p.get(chatID, messageID)
+	if !ok {
+		// The select has expired: remove stale buttons.
+		return removeKeyboard(sender, chatID, messageID)
+	}
+
+	_, err = sender.Request(tgbotapi.NewEditMessageReplyMarkup(
+		chatID,
+		messageID,
+		selectMarkup(s, page, p.size),
+	))
+
+	return err
+}
+
+// get returns the select sent in the message, if it is still cached.
+func (p *selectPager) get(chatID int64, messageID int) (content.Select, bool) {
+	value, ok := p.cache.Get(selectKey(chatID, messageID))
+	if !ok {
+		return content.Select{}, false
+	}
+
+	data, ok := value.(string)
+	if !ok {
+		return content.Select{}, false
+	}
+
+	var s content.Select
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return content.Select{}, false
+	}
+
+	return s, true
+}
+
+// selectMarkup builds the inline keyboard for a page of items laid out in
+// the select columns. A page size of zero puts all items on a single page
+// without navigation.
+func selectMarkup(s content.Select, page, size int) tgbotapi.InlineKeyboardMarkup {
+	items := s.Items
+	if size <= 0 || len(items) <= size {
+		page, size = 0, len(items)
+	}
+
+	pages := 1
+	if size > 0 {
+		pages = (len(items) + size - 1) / size
+	}
+	page = max(0, min(page, pages-1))
+
+	from := page * size
+	to := min(from+size, len(items))
+
+	columns := max(s.Columns, 1)
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, (to-from)/columns+2)
+	for i, item := range items[from:to] {
+		if i%columns == 0 {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow())
+		}
+
+		button := tgbotapi.NewInlineKeyboardButtonData(item.Caption, item.Data)
+		if item.URL != "" {
+			button = tgbotapi.NewInlineKeyboardButtonURL(item.Caption, item.URL)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], button)
+	}
+
+	if pages > 1 {
+		nav := make([]tgbotapi.InlineKeyboardButton, 0, 3)
+		if page > 0 {
+			nav = append(nav, pageButton("◀️", page-1))
+		}
+		nav = append(nav, pageButton(fmt.Sprintf("%d/%d", page+1, pages), page))
+		if page < pages-1 {
+			nav = append(nav, pageButton("▶️", page+1))
+		}
+		rows = append(rows, nav)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// keyboardMarkup builds a reply keyboard.
+func keyboardMarkup(k content.Keyboard) tgbotapi.ReplyKeyboardMarkup {
+	rows := make([][]tgbotapi.KeyboardButton, 0, len(k.Buttons))
+	for _, row := range k.Buttons {
+		buttons := make([]tgbotapi.KeyboardButton, 0, len(row))
+		for _, text := range row {
+			buttons = append(buttons, tgbotapi.NewKeyboardButton(text))
+		}
+		rows = append(rows, buttons)
+	}
+
+	markup := tgbotapi.NewReplyKeyboard(rows...)
+	markup.OneTimeKeyboard = k.OneTime
+	markup.ResizeKeyboard = k.Resize
+	markup.InputFieldPlaceholder = k.Placeholder
+
+	return markup
+}
+
+func pageButton(text string, page int) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, pageCallbackPrefix+strconv.Itoa(page))
+}
+
+func isPageCallback(data string) bool {
+	return strings.HasPrefix(data, pageCallbackPrefix)
+}
+
+// markSelected edits the keyboard of the callback message: it is replaced
+// with a single inactive button showing the chosen item. The text is left
+// untouched to keep its formatting.
+func markSelected(sender *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery, caption string) error {
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+
+	if caption == "" {
+		return removeKeyboard(sender, chatID, messageID)
+	}
+
+	_, err := sender.Request(tgbotapi.NewEditMessageReplyMarkup(
+		chatID,
+		messageID,
+		tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ "+caption, selectedCallback),
+		)),
+	))
+
+	return err
+}
+
+func removeKeyboard(sender *tgbotapi.BotAPI, chatID int64, messageID int) error {
+	_, err := sender.Request(tgbotapi.NewEditMessageReplyMarkup(
+		chatID,
+		messageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}},
+	))
+
+	return err
+}
+
+func selectKey(chatID int64, messageID int) string {
+	return selectCachePrefix + strconv.FormatInt(chatID, 10) + ":" + strconv.Itoa(messageID)
+}
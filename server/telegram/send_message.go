@@ -1,12 +1,18 @@
 package telegram
 
 import (
+	"strings"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/muzykantov/health-gpt/chat"
 	"github.com/muzykantov/health-gpt/chat/content"
 	"github.com/muzykantov/health-gpt/metrics"
 )
 
+// emptyText replaces the empty text of messages that only carry a keyboard:
+// Telegram does not send messages without text.
+const emptyText = "⌨️"
+
 // SendMessage sends a message via Telegram API
 func SendMessage(sender *tgbotapi.BotAPI, chatID int64, m chat.Message) (err error) {
 	if m.IsEmpty() {
@@ -15,37 +21,73 @@ func SendMessage(sender *tgbotapi.BotAPI, chatID int64, m chat.Message) (err err
 
 	switch msgContent := m.Content.(type) {
 	case string:
-		msg := tgbotapi.NewMessage(chatID, msgContent)
-		msg.ParseMode = tgbotapi.ModeHTML
+		for _, part := range SplitHTML(RenderHTML(msgContent), MaxMessageLength) {
+			if err = sendText(sender, chatID, part); err != nil {
+				break
+			}
+		}
 
-		_, err = sender.Send(msg)
+	case content.Select:
+		_, err = sendSelect(sender, chatID, msgContent.Header, selectMarkup(msgContent, 0, 0))
 		if err == nil {
-			// Increment sent text messages counter
-			metrics.TelegramMessagesTotal.WithLabelValues("sent_text").Inc()
+			// Increment sent selection messages counter
+			metrics.TelegramMessagesTotal.WithLabelValues("sent_select").Inc()
 		} else {
-			metrics.RecordTelegramError("send_text")
+			metrics.RecordTelegramError("send_select")
 		}
 
-	case content.Select:
-		var buttons = make([][]tgbotapi.InlineKeyboardButton, 0, len(msgContent.Items))
-		for _, item := range msgContent.Items {
-			buttons = append(
-				buttons,
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData(item.Caption, item.Data),
-				),
-			)
+	case content.Keyboard:
+		msg := tgbotapi.NewMessage(chatID, messageText(msgContent.Header))
+		msg.ReplyMarkup = keyboardMarkup(msgContent)
+
+		_, err = sender.Send(msg)
+		if err == nil {
+			// Increment sent keyboard messages counter
+			metrics.TelegramMessagesTotal.WithLabelValues("sent_keyboard").Inc()
+		} else {
+			metrics.RecordTelegramError("send_keyboard")
 		}
 
-		msg := tgbotapi.NewMessage(chatID, msgContent.Header)
-		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons...)
+	case content.RemoveKeyboard:
+		msg := tgbotapi.NewMessage(chatID, messageText(msgContent.Text))
+		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
 
 		_, err = sender.Send(msg)
 		if err == nil {
-			// Increment sent selection messages counter
-			metrics.TelegramMessagesTotal.WithLabelValues("sent_select").Inc()
+			// Increment sent keyboard removal counter
+			metrics.TelegramMessagesTotal.WithLabelValues("sent_remove_keyboard").Inc()
 		} else {
-			metrics.RecordTelegramError("send_select")
+			metrics.RecordTelegramError("send_remove_keyboard")
+		}
+
+	case content.Voice:
+		voice := tgbotapi.NewVoice(chatID, tgbotapi.FileBytes{
+			Name:  "voice.ogg",
+			Bytes: msgContent.Data,
+		})
+		voice.Caption = msgContent.Caption
+
+		_, err = sender.Send(voice)
+		if err == nil {
+			// Increment sent voice messages counter
+			metrics.TelegramMessagesTotal.WithLabelValues("sent_voice").Inc()
+		} else {
+			metrics.RecordTelegramError("send_voice")
+		}
+
+	case content.Document:
+		document := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+			Name:  msgContent.Name,
+			Bytes: msgContent.Data,
+		})
+		document.Caption = msgContent.Caption
+
+		_, err = sender.Send(document)
+		if err == nil {
+			// Increment sent documents counter
+			metrics.TelegramMessagesTotal.WithLabelValues("sent_document").Inc()
+		} else {
+			metrics.RecordTelegramError("send_document")
 		}
 
 	case content.Commands:
@@ -85,3 +127,64 @@ func SendMessage(sender *tgbotapi.BotAPI, chatID int64, m chat.Message) (err err
 
 	return
 }
+
+// messageText returns the text, or emptyText if it is blank.
+func messageText(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return emptyText
+	}
+
+	return text
+}
+
+// sendSelect sends the select header as HTML with the keyboard attached to
+// its last part, so a long header such as an assistant answer is split like
+// a text message. It returns the message carrying the keyboard.
+func sendSelect(sender *tgbotapi.BotAPI, chatID int64, header string, markup tgbotapi.InlineKeyboardMarkup) (tgbotapi.Message, error) {
+	parts := SplitHTML(RenderHTML(header), MaxMessageLength)
+	for _, part := range parts[:len(parts)-1] {
+		if err := sendText(sender, chatID, part); err != nil {
+			return tgbotapi.Message{}, err
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, messageText(parts[len(parts)-1]))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = markup
+
+	sent, err := sender.Send(msg)
+	if err != nil && strings.Contains(err.Error(), "can't parse entities") {
+		metrics.RecordTelegramError("parse_html")
+
+		msg.Text = messageText(PlainText(msg.Text))
+		msg.ParseMode = ""
+		sent, err = sender.Send(msg)
+	}
+
+	return sent, err
+}
+
+// sendText sends a part of a text message as HTML. If Telegram rejects
+// the markup, the part is resent as plain text.
+func sendText(sender *tgbotapi.BotAPI, chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+
+	_, err := sender.Send(msg)
+	if err != nil && strings.Contains(err.Error(), "can't parse entities") {
+		metrics.RecordTelegramError("parse_html")
+
+		msg = tgbotapi.NewMessage(chatID, PlainText(text))
+		_, err = sender.Send(msg)
+	}
+
+	if err != nil {
+		metrics.RecordTelegramError("send_text")
+		return err
+	}
+
+	// Increment sent text messages counter
+	metrics.TelegramMessagesTotal.WithLabelValues("sent_text").Inc()
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
 	"log"
 	"sync"
 	"time"
@@ -24,24 +25,53 @@ var (
 	ErrTelegramLLMNotProvided         = errors.New("telegram llm not provided")
 	ErrTelegramUnsupportedMessageType = errors.New("telegram unsupported message type")
 	ErrTelegramInvalidMessageContent  = errors.New("telegram invalid message content")
+	ErrTelegramNotStarted             = fmt.Errorf("telegram server not started: %w", server.ErrNotifierUnavailable)
 )
 
-// Chat history TTL.
+// Default request cache TTL.
 const defaultCacheTTL = time.Hour * 24
 
 // Server manages interaction with Telegram Bot API
 type Server struct {
 	Token               string
+	APIEndpoint         string // Bot API endpoint format, tgbotapi.APIEndpoint if empty
+	FileEndpoint        string // File download endpoint format, tgbotapi.FileEndpoint if empty
 	Handler             server.Handler
 	Completion          server.ChatCompleter
 	Storage             server.DataStorage
+	Cache               server.Cache
 	Debug               bool
-	UnsupportedResponse func() chat.Message
+	UnsupportedResponse func(user chat.User) chat.Message
 	Log                 *log.Logger
 
+	// Transcriber converts voice and audio messages to text questions.
+	// Such messages are unsupported if it is nil.
+	Transcriber server.Transcriber
+
+	// TranscriptionFailedResponse is sent when a voice message
+	// cannot be downloaded or recognized.
+	TranscriptionFailedResponse func(user chat.User) chat.Message
+
+	// Synthesizer voices assistant answers for users who enabled voice
+	// replies. Answers are sent as text if it is nil.
+	Synthesizer server.Synthesizer
+
+	// EditSelected replaces the keyboard of the original select message
+	// with the chosen item, so stale buttons cannot be pressed.
+	EditSelected bool
+
+	// SelectPageSize splits selects with more items into pages with
+	// navigation buttons. Zero disables pagination.
+	SelectPageSize int
+
 	// For tracking active users
 	activeUsers   map[int64]bool
 	activeUsersMu sync.Mutex
+
+	// Bot and pager of the running server, used by Notify
+	bot   *tgbotapi.BotAPI
+	pager *selectPager
+	botMu sync.RWMutex
 }
 
 // ListenAndServe starts the main message processing loop
@@ -65,7 +95,10 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 		}
 	}
 
-	cache := expirable.NewLRU[string, any](0, nil, defaultCacheTTL)
+	var cache server.Cache = expirable.NewLRU[string, any](0, nil, defaultCacheTTL)
+	if t.Cache != nil {
+		cache = t.Cache
+	}
 
 	dataStorage := t.Storage
 	if dataStorage == nil {
@@ -74,21 +107,74 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 
 	logger := t.Log
 	if logger == nil {
-		t.Log = log.Default()
+		logger = log.Default()
 	}
 
-	bot, err := tgbotapi.NewBotAPI(t.Token)
+	apiEndpoint := t.APIEndpoint
+	if apiEndpoint == "" {
+		apiEndpoint = tgbotapi.APIEndpoint
+	}
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint(t.Token, apiEndpoint)
 	if err != nil {
 		return fmt.Errorf("failed to create bot: %w", err)
 	}
 	bot.Debug = t.Debug
 
-	unsupported := func(chatID int64) {
+	fileEndpoint := t.FileEndpoint
+	if fileEndpoint == "" {
+		fileEndpoint = tgbotapi.FileEndpoint
+	}
+
+	pager := newSelectPager(t.SelectPageSize, cache)
+
+	t.botMu.Lock()
+	t.bot, t.pager = bot, pager
+	t.botMu.Unlock()
+
+	defer func() {
+		t.botMu.Lock()
+		t.bot, t.pager = nil, nil
+		t.botMu.Unlock()
+	}()
+
+	// answer acknowledges a callback query, so the client stops the spinner.
+	answer := func(query *tgbotapi.CallbackQuery) {
+		if _, err := bot.Request(tgbotapi.NewCallback(query.ID, "")); err != nil {
+			logger.Printf("failed to answer callback query: %v", err)
+			metrics.RecordTelegramError("answer_callback")
+		}
+	}
+
+	// getUser returns the stored user, or a new one built from the sender
+	// if the user is not found or cannot be read.
+	getUser := func(sender *tgbotapi.User) (chat.User, error) {
+		user, err := dataStorage.GetUser(ctx, sender.ID)
+		if err != nil {
+			user = chat.User{
+				ID:        sender.ID,
+				FirstName: sender.FirstName,
+				LastName:  sender.LastName,
+				UserName:  sender.UserName,
+			}
+
+			if errors.Is(err, storage.ErrUserNotFound) {
+				err = nil
+			}
+		}
+
+		// The client language is refreshed on every update.
+		user.LanguageCode = sender.LanguageCode
+
+		return user, err
+	}
+
+	unsupported := func(chatID int64, user chat.User) {
 		if t.UnsupportedResponse != nil {
 			if err := SendMessage(
 				bot,
 				chatID,
-				t.UnsupportedResponse(),
+				t.UnsupportedResponse(user),
 			); err != nil {
 				logger.Printf("failed to send unsupported message response: %v", err)
 				metrics.RecordTelegramError("unsupported_response")
@@ -100,6 +186,8 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 	u.Timeout = 60
 
 	updates := bot.GetUpdatesChan(u)
+	defer bot.StopReceivingUpdates()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -114,6 +202,9 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 				incoming    chat.Message
 				chatID      int64
 				sender      *tgbotapi.User
+				callback    *tgbotapi.CallbackQuery
+				audio       *audioFile
+				photo       string
 				err         error
 				messageType string
 			)
@@ -123,13 +214,30 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 				sender = update.Message.From
 				chatID = update.Message.Chat.ID
 
-				if update.Message.Text == "" {
-					unsupported(update.Message.Chat.ID)
+				if t.Transcriber != nil {
+					audio = messageAudio(update.Message)
+				}
+				photo = messagePhoto(update.Message)
+
+				switch {
+				case audio != nil:
+					// The text is known after transcription.
+					messageType = "voice"
+					metrics.RecordTelegramMessage("voice")
+
+				case photo != "":
+					// The photo is downloaded with the other work of the update.
+					messageType = "photo"
+					metrics.RecordTelegramMessage("photo")
+
+				case update.Message.Text == "":
+					// The user is only needed for the language of the response.
+					user, _ := getUser(sender)
+					unsupported(update.Message.Chat.ID, user)
 					metrics.RecordTelegramMessage("unsupported")
 					continue
-				}
 
-				if update.Message.IsCommand() {
+				case update.Message.IsCommand():
 					messageType = "command"
 					metrics.RecordTelegramMessage("command")
 					incoming = chat.MsgU(
@@ -138,7 +246,8 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 							Args: update.Message.CommandArguments(),
 						},
 					)
-				} else {
+
+				default:
 					messageType = "text"
 					metrics.RecordTelegramMessage("text")
 					incoming = chat.MsgU(update.Message.Text)
@@ -147,8 +256,27 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 			case update.CallbackQuery != nil &&
 				update.CallbackQuery.Message != nil &&
 				update.CallbackQuery.Message.Chat != nil:
-				sender = update.CallbackQuery.From
-				chatID = update.CallbackQuery.Message.Chat.ID
+				callback = update.CallbackQuery
+				sender = callback.From
+				chatID = callback.Message.Chat.ID
+
+				if callback.Data == selectedCallback {
+					go answer(callback)
+					continue
+				}
+
+				if isPageCallback(callback.Data) {
+					metrics.RecordTelegramMessage("page")
+					go func() {
+						answer(callback)
+						if err := pager.turn(bot, callback); err != nil {
+							logger.Printf("failed to switch select page: %v", err)
+							metrics.RecordTelegramError("select_page")
+						}
+					}()
+					continue
+				}
+
 				messageType = "callback"
 				metrics.RecordTelegramMessage("callback")
 
@@ -197,20 +325,11 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 			// Record user session
 			metrics.RecordUserSession(isNewUser)
 
-			from, err := dataStorage.GetUser(ctx, sender.ID)
+			from, err := getUser(sender)
 			if err != nil {
-				if !errors.Is(err, storage.ErrUserNotFound) {
-					logger.Printf("failed to get user: %v", err)
-					metrics.RecordTelegramError("get_user")
-					continue
-				}
-
-				from = chat.User{
-					ID:        sender.ID,
-					FirstName: sender.FirstName,
-					LastName:  sender.LastName,
-					UserName:  sender.UserName,
-				}
+				logger.Printf("failed to get user: %v", err)
+				metrics.RecordTelegramError("get_user")
+				continue
 			}
 
 			go func() {
@@ -223,11 +342,59 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 
 				start := time.Now()
 
+				if audio != nil {
+					text, err := transcribe(ctx, bot, fileEndpoint, t.Transcriber, audio)
+					if err != nil {
+						logger.Printf("failed to transcribe voice message: %v", err)
+						metrics.RecordTelegramError("transcribe")
+
+						if t.TranscriptionFailedResponse != nil {
+							if err := SendMessage(bot, chatID, t.TranscriptionFailedResponse(from)); err != nil {
+								logger.Printf("failed to send transcription failure response: %v", err)
+							}
+						}
+						return
+					}
+
+					// Echo the transcript so the user can check what was recognized.
+					if err := SendMessage(bot, chatID, chat.MsgA("🎙 <i>"+html.EscapeString(text)+"</i>")); err != nil {
+						logger.Printf("failed to send transcript: %v", err)
+						metrics.RecordTelegramError("send_transcript")
+					}
+
+					incoming = chat.MsgU(text)
+				}
+
+				if photo != "" {
+					p, err := downloadPhoto(ctx, bot, fileEndpoint, photo, update.Message.Caption)
+					if err != nil {
+						logger.Printf("failed to download photo: %v", err)
+						metrics.RecordTelegramError("download_photo")
+						unsupported(chatID, from)
+						return
+					}
+
+					incoming = chat.MsgU(p)
+				}
+
+				if callback != nil {
+					answer(callback)
+
+					if t.EditSelected {
+						caption := incoming.Content.(content.SelectItem).Caption
+						if err := markSelected(bot, callback, caption); err != nil {
+							logger.Printf("failed to mark selected item: %v", err)
+							metrics.RecordTelegramError("mark_selected")
+						}
+					}
+				}
+
 				t.Handler.Serve(
 					ctx,
 					&telegramResponseWriter{
 						chatID:      chatID,
 						sender:      bot,
+						pager:       pager,
 						log:         logger,
 						messageType: messageType,
 						startTime:   start,
@@ -236,11 +403,16 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 						ChatID:   chatID,
 						Incoming: incoming,
 						From:     from,
+						Format:   server.FormatHTML,
+
+						Transport: server.TransportTelegram,
 
 						Completer: chatCompletion,
 						Storage:   dataStorage,
 						Cache:     cache,
 						Log:       logger,
+
+						Synthesizer: t.Synthesizer,
 					})
 			}()
 		}
@@ -251,6 +423,7 @@ func (t *Server) ListenAndServe(ctx context.Context) error {
 type telegramResponseWriter struct {
 	chatID      int64
 	sender      *tgbotapi.BotAPI
+	pager       *selectPager
 	log         *log.Logger
 	messageType string
 	startTime   time.Time
@@ -261,7 +434,7 @@ func (w *telegramResponseWriter) WriteResponse(m chat.Message) error {
 		return nil
 	}
 
-	if err := SendMessage(w.sender, w.chatID, m); err != nil {
+	if err := send(w.sender, w.pager, w.chatID, m); err != nil {
 		w.log.Printf("failed to send message to chatID %d: %v", w.chatID, err)
 		metrics.RecordTelegramError("send_message")
 		return err
@@ -274,6 +447,38 @@ func (w *telegramResponseWriter) WriteResponse(m chat.Message) error {
 	return nil
 }
 
+// Notify sends a message to the chat outside of update processing, e.g. a
+// scheduled notification. It fails if the server is not running.
+func (t *Server) Notify(ctx context.Context, chatID int64, m chat.Message) error {
+	t.botMu.RLock()
+	bot, pager := t.bot, t.pager
+	t.botMu.RUnlock()
+
+	if bot == nil {
+		return ErrTelegramNotStarted
+	}
+
+	if m.IsEmpty() {
+		return nil
+	}
+
+	if err := send(bot, pager, chatID, m); err != nil {
+		metrics.RecordTelegramError("notify")
+		return err
+	}
+
+	return nil
+}
+
+// send sends the message, splitting long selects into pages.
+func send(bot *tgbotapi.BotAPI, pager *selectPager, chatID int64, m chat.Message) error {
+	if s, ok := m.Content.(content.Select); ok && pager.paginated(s) {
+		return pager.send(bot, chatID, s)
+	}
+
+	return SendMessage(bot, chatID, m)
+}
+
 // unimplementedDataStorage provides an empty implementation of the history interface
 type unimplementedDataStorage struct{}
 
@@ -293,6 +498,21 @@ func (unimplementedDataStorage) SaveChatHistory(
 	return nil
 }
 
+func (unimplementedDataStorage) GetChatState(
+	ctx context.Context,
+	chatID int64,
+) (chat.State, error) {
+	return chat.State{}, nil
+}
+
+func (unimplementedDataStorage) SaveChatState(
+	ctx context.Context,
+	chatID int64,
+	state chat.State,
+) error {
+	return nil
+}
+
 func (unimplementedDataStorage) GetUser(ctx context.Context, userID int64) (chat.User, error) {
 	return chat.User{}, errors.New("user not found")
 }
@@ -300,3 +520,51 @@ func (unimplementedDataStorage) GetUser(ctx context.Context, userID int64) (chat
 func (unimplementedDataStorage) SaveUser(ctx context.Context, user chat.User) error {
 	return nil
 }
+
+func (unimplementedDataStorage) GetPlan(ctx context.Context, userID int64) (chat.Plan, error) {
+	return chat.Plan{}, nil
+}
+
+func (unimplementedDataStorage) SavePlan(ctx context.Context, userID int64, plan chat.Plan) error {
+	return nil
+}
+
+func (unimplementedDataStorage) GetDiary(ctx context.Context, userID int64, date string) (chat.Diary, error) {
+	return chat.Diary{Date: date}, nil
+}
+
+func (unimplementedDataStorage) SaveDiary(ctx context.Context, userID int64, diary chat.Diary) error {
+	return nil
+}
+
+func (unimplementedDataStorage) GetJournal(ctx context.Context, userID int64) (chat.Journal, error) {
+	return chat.Journal{}, nil
+}
+
+func (unimplementedDataStorage) SaveJournal(ctx context.Context, userID int64, journal chat.Journal) error {
+	return nil
+}
+
+func (unimplementedDataStorage) GetRating(ctx context.Context, id string) (chat.Rating, error) {
+	return chat.Rating{}, nil
+}
+
+func (unimplementedDataStorage) GetRatings(ctx context.Context) ([]chat.Rating, error) {
+	return nil, nil
+}
+
+func (unimplementedDataStorage) SaveRating(ctx context.Context, rating chat.Rating) error {
+	return nil
+}
+
+func (unimplementedDataStorage) GetJobs(ctx context.Context) ([]chat.Job, error) {
+	return nil, nil
+}
+
+func (unimplementedDataStorage) SaveJob(ctx context.Context, job chat.Job) error {
+	return nil
+}
+
+func (unimplementedDataStorage) DeleteJob(ctx context.Context, id string) error {
+	return nil
+}
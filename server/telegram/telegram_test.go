@@ -0,0 +1,626 @@
+package telegram_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/handler"
+	"github.com/muzykantov/health-gpt/llm"
+	"github.com/muzykantov/health-gpt/server"
+	"github.com/muzykantov/health-gpt/server/telegram"
+	"github.com/muzykantov/health-gpt/server/telegram/telegramtest"
+)
+
+const waitTimeout = 5 * time.Second
+
+var testUser = tgbotapi.User{ID: 100, FirstName: "Иван", UserName: "ivan"}
+
+// startServer runs the Telegram server against the fake Bot API.
+func startServer(
+	t *testing.T,
+	h server.Handler,
+	completer server.ChatCompleter,
+	opts ...func(*telegram.Server),
+) *telegramtest.Server {
+	t.Helper()
+
+	api := telegramtest.NewServer()
+
+	fs, err := storage.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	srv := &telegram.Server{
+		Token:        "test-token",
+		APIEndpoint:  api.APIEndpoint(),
+		FileEndpoint: api.FileEndpoint(),
+		Handler:      h,
+		Completion:   completer,
+		Storage:      fs,
+		UnsupportedResponse: func(user chat.User) chat.Message {
+			return chat.MsgA("unsupported")
+		},
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.ListenAndServe(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		api.Close()
+	})
+
+	return api
+}
+
+func TestServerStartConversation(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []chat.Message
+	)
+	completer := &llm.Mock{
+		CompleteChatFn: func(ctx context.Context, msgs []chat.Message) (chat.Message, error) {
+			mu.Lock()
+			received = msgs
+			mu.Unlock()
+			return chat.MsgA("Пожалуйста, введите email и пароль."), nil
+		},
+	}
+
+	api := startServer(t, handler.Start(), completer)
+	api.SendText(testUser.ID, testUser, "Привет")
+
+	commands, err := api.WaitCalls("setMyCommands", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(commands[0].Params.Get("commands"), `"command":"start"`) {
+		t.Errorf("unexpected commands: %s", commands[0].Params.Get("commands"))
+	}
+
+	sent, err := api.WaitCalls("sendMessage", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sent[0].Params.Get("text"); got != "Пожалуйста, введите email и пароль." {
+		t.Errorf("sent text = %q", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received) != 2 || received[0].Sender != chat.RoleSystem || received[1].Content != "Привет" {
+		t.Errorf("unexpected completion context: %v", received)
+	}
+}
+
+// selectHandler responds to any command with a select of the given items
+// and echoes the chosen item.
+func selectHandler(items ...content.SelectItem) server.Handler {
+	return server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		switch c := r.Incoming.Content.(type) {
+		case content.Command:
+			w.WriteResponse(chat.MsgA(content.Select{
+				Header: "Выберите:",
+				Items:  items,
+			}))
+
+		case content.SelectItem:
+			w.WriteResponse(chat.MsgAf("%s=%s", c.Caption, c.Data))
+		}
+	})
+}
+
+func TestServerSelectCallback(t *testing.T) {
+	h := selectHandler(
+		content.SelectItem{Caption: "Первый", Data: "first"},
+		content.SelectItem{Caption: "Второй", Data: "second"},
+	)
+
+	api := startServer(t, h, &llm.Mock{})
+	api.SendText(testUser.ID, testUser, "/start")
+
+	sent, err := api.WaitCalls("sendMessage", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sent[0].Params.Get("reply_markup"), `"callback_data":"second"`) {
+		t.Fatalf("unexpected markup: %s", sent[0].Params.Get("reply_markup"))
+	}
+
+	// The fake assigns message IDs sequentially: 1 is the user's command.
+	if _, err := api.PressButton(testUser, 2, "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent, err = api.WaitCalls("sendMessage", 2, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if got := sent[1].Params.Get("text"); got != "Второй=second" {
+		t.Errorf("sent text = %q", got)
+	}
+
+	answers, err := api.WaitCalls("answerCallbackQuery", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := answers[0].Params.Get("callback_query_id"); got != "2:second" {
+		t.Errorf("answered callback = %q", got)
+	}
+
+	// The original message is left untouched unless EditSelected is set.
+	if calls := api.Calls("editMessageText", "editMessageReplyMarkup"); len(calls) != 0 {
+		t.Errorf("unexpected edits: %v", calls)
+	}
+}
+
+func TestServerEditSelected(t *testing.T) {
+	h := selectHandler(
+		content.SelectItem{Caption: "Первый", Data: "first"},
+		content.SelectItem{Caption: "Второй", Data: "second"},
+	)
+
+	api := startServer(t, h, &llm.Mock{}, func(s *telegram.Server) {
+		s.EditSelected = true
+	})
+	api.SendText(testUser.ID, testUser, "/start")
+
+	if _, err := api.WaitCalls("sendMessage", 1, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.PressButton(testUser, 2, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.WaitCalls("editMessageReplyMarkup", 1, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the keyboard is edited: the text keeps its formatting.
+	if calls := api.Calls("editMessageText"); len(calls) != 0 {
+		t.Errorf("unexpected text edits: %v", calls)
+	}
+
+	msg, _ := api.Message(2)
+	if msg.Text != "Выберите:" {
+		t.Errorf("edited text = %q", msg.Text)
+	}
+	if got := buttons(msg); got != "✅ Первый" {
+		t.Errorf("keyboard = %q", got)
+	}
+
+	// The chosen item is inactive: the handler is not called again.
+	if _, err := api.PressButton(testUser, 2, "tg_selected"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.WaitCalls("answerCallbackQuery", 2, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if calls := api.Calls("sendMessage"); len(calls) != 2 {
+		t.Errorf("sendMessage calls = %d, want 2", len(calls))
+	}
+}
+
+// addedCache signals every value added to the cache.
+type addedCache struct {
+	server.Cache
+	added chan struct{}
+}
+
+func (c *addedCache) Add(key string, value any) bool {
+	evicted := c.Cache.Add(key, value)
+	select {
+	case c.added <- struct{}{}:
+	default:
+	}
+	return evicted
+}
+
+func TestServerSelectPagination(t *testing.T) {
+	items := make([]content.SelectItem, 5)
+	for i := range items {
+		items[i] = content.SelectItem{
+			Caption: fmt.Sprintf("Анализ %d", i+1),
+			Data:    fmt.Sprintf("codelab:%d", i+1),
+		}
+	}
+
+	// Pages are kept in the request cache; a persistent cache returns them
+	// decoded from JSON.
+	bolt, err := storage.NewBoltCache(t.TempDir()+"/cache.db", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+	cache := &addedCache{Cache: bolt, added: make(chan struct{}, 1)}
+
+	api := startServer(t, selectHandler(items...), &llm.Mock{}, func(s *telegram.Server) {
+		s.SelectPageSize = 2
+		s.Cache = cache
+	})
+	api.SendText(testUser.ID, testUser, "/start")
+
+	if _, err := api.WaitCalls("sendMessage", 1, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	// The select is cached after Telegram returns its message ID.
+	select {
+	case <-cache.added:
+	case <-time.After(waitTimeout):
+		t.Fatal("select was not cached")
+	}
+
+	msg, _ := api.Message(2)
+	if got := buttons(msg); got != "Анализ 1|Анализ 2|1/3,▶️" {
+		t.Fatalf("first page = %q", got)
+	}
+
+	// Navigation is handled by the server: the handler is not called.
+	if _, err := api.PressButton(testUser, 2, "tg_page:2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.WaitCalls("editMessageReplyMarkup", 1, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.WaitCalls("answerCallbackQuery", 1, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, _ = api.Message(2)
+	if got := buttons(msg); got != "Анализ 5|◀️,3/3" {
+		t.Fatalf("last page = %q", got)
+	}
+
+	if _, err := api.PressButton(testUser, 2, "codelab:5"); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := api.WaitCalls("sendMessage", 2, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sent[1].Params.Get("text"); got != "Анализ 5=codelab:5" {
+		t.Errorf("sent text = %q", got)
+	}
+}
+
+// buttons describes an inline keyboard: rows are separated by "|",
+// buttons in a row by ",".
+func buttons(msg tgbotapi.Message) string {
+	if msg.ReplyMarkup == nil {
+		return ""
+	}
+
+	rows := make([]string, 0, len(msg.ReplyMarkup.InlineKeyboard))
+	for _, row := range msg.ReplyMarkup.InlineKeyboard {
+		texts := make([]string, 0, len(row))
+		for _, button := range row {
+			texts = append(texts, button.Text)
+		}
+		rows = append(rows, strings.Join(texts, ","))
+	}
+
+	return strings.Join(rows, "|")
+}
+
+func TestServerUnsupportedMessage(t *testing.T) {
+	api := startServer(t, handler.Chat(), &llm.Mock{})
+	api.SendUpdate(tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 1000,
+		From:      &testUser,
+		Chat:      &tgbotapi.Chat{ID: testUser.ID},
+		Sticker:   &tgbotapi.Sticker{FileID: "sticker"},
+	}})
+
+	sent, err := api.WaitCalls("sendMessage", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sent[0].Params.Get("text"); got != "unsupported" {
+		t.Errorf("sent text = %q", got)
+	}
+}
+
+func TestServerRichContent(t *testing.T) {
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		w.WriteResponse(chat.MsgA(content.Select{
+			Header:  "Выберите:",
+			Columns: 2,
+			Items: []content.SelectItem{
+				{Caption: "Первый", Data: "first"},
+				{Caption: "Второй", Data: "second"},
+				{Caption: "Сайт", URL: "https://mygenetics.ru"},
+			},
+		}))
+		w.WriteResponse(chat.MsgA(content.Keyboard{
+			Header:      "Как самочувствие?",
+			Buttons:     [][]string{{"Хорошо", "Плохо"}},
+			OneTime:     true,
+			Resize:      true,
+			Placeholder: "Ответ",
+		}))
+		w.WriteResponse(chat.MsgA(content.RemoveKeyboard{Text: "Спасибо!"}))
+	})
+
+	api := startServer(t, h, &llm.Mock{})
+	api.SendText(testUser.ID, testUser, "/start")
+
+	sent, err := api.WaitCalls("sendMessage", 3, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, _ := api.Message(2)
+	if got := buttons(msg); got != "Первый,Второй|Сайт" {
+		t.Errorf("select layout = %q", got)
+	}
+	if url := msg.ReplyMarkup.InlineKeyboard[1][0].URL; url == nil || *url != "https://mygenetics.ru" {
+		t.Errorf("url button = %v", url)
+	}
+
+	var keyboard tgbotapi.ReplyKeyboardMarkup
+	if err := json.Unmarshal([]byte(sent[1].Params.Get("reply_markup")), &keyboard); err != nil {
+		t.Fatal(err)
+	}
+	if len(keyboard.Keyboard) != 1 || len(keyboard.Keyboard[0]) != 2 || keyboard.Keyboard[0][1].Text != "Плохо" ||
+		!keyboard.OneTimeKeyboard || !keyboard.ResizeKeyboard || keyboard.InputFieldPlaceholder != "Ответ" {
+		t.Errorf("reply keyboard = %+v", keyboard)
+	}
+
+	var remove tgbotapi.ReplyKeyboardRemove
+	if err := json.Unmarshal([]byte(sent[2].Params.Get("reply_markup")), &remove); err != nil {
+		t.Fatal(err)
+	}
+	if !remove.RemoveKeyboard || sent[2].Params.Get("text") != "Спасибо!" {
+		t.Errorf("remove keyboard = %+v, text %q", remove, sent[2].Params.Get("text"))
+	}
+}
+
+func TestServerEmptyKeyboardText(t *testing.T) {
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		w.WriteResponse(chat.MsgA(content.Keyboard{Buttons: [][]string{{"Да", "Нет"}}}))
+		w.WriteResponse(chat.MsgA(content.RemoveKeyboard{}))
+	})
+
+	api := startServer(t, h, &llm.Mock{})
+	api.SendText(testUser.ID, testUser, "/start")
+
+	sent, err := api.WaitCalls("sendMessage", 2, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fake rejects empty text like Telegram: both messages are stored.
+	for i, id := range []int{2, 3} {
+		msg, ok := api.Message(id)
+		if !ok || msg.Text == "" {
+			t.Errorf("message %d was not sent: %q", i, sent[i].Params.Get("text"))
+		}
+	}
+}
+
+func TestServerLongSelectHeader(t *testing.T) {
+	answer := "**Ответ**\n\n" + strings.Repeat("Кофеин выводится медленно. ", 200)
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		w.WriteResponse(chat.MsgA(content.Select{
+			Header: answer,
+			Items:  []content.SelectItem{{Caption: "👍", Data: "rate:up:1"}},
+		}))
+	})
+
+	api := startServer(t, h, &llm.Mock{})
+	api.SendText(testUser.ID, testUser, "/start")
+
+	// The header is split like a text message; the buttons are attached to
+	// its last part.
+	sent, err := api.WaitCalls("sendMessage", 3, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, last := sent[0].Params, sent[2].Params
+	if !strings.HasPrefix(first.Get("text"), "<b>Ответ</b>") || first.Get("parse_mode") != "HTML" || first.Get("reply_markup") != "" {
+		t.Errorf("first part = %q (%s), markup %q", first.Get("text"), first.Get("parse_mode"), first.Get("reply_markup"))
+	}
+	if last.Get("parse_mode") != "HTML" || !strings.Contains(last.Get("reply_markup"), "rate:up:1") {
+		t.Errorf("last part parse mode = %q, markup %q", last.Get("parse_mode"), last.Get("reply_markup"))
+	}
+}
+
+// transcriberFunc adapts a function to the server.Transcriber interface.
+type transcriberFunc func(ctx context.Context, audio io.Reader, filename string) (string, error)
+
+func (f transcriberFunc) Transcribe(ctx context.Context, audio io.Reader, filename string) (string, error) {
+	return f(ctx, audio, filename)
+}
+
+func TestServerVoiceMessage(t *testing.T) {
+	transcriber := transcriberFunc(func(ctx context.Context, audio io.Reader, filename string) (string, error) {
+		data, err := io.ReadAll(audio)
+		if err != nil {
+			return "", err
+		}
+		if string(data) != "OggS" || filename != "voice.ogg" {
+			return "", fmt.Errorf("unexpected audio %q in %s", data, filename)
+		}
+		return "Можно ли мне кофе?", nil
+	})
+
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		w.WriteResponse(chat.MsgAf("question: %v", r.Incoming.Content))
+	})
+
+	api := startServer(t, h, &llm.Mock{}, func(s *telegram.Server) {
+		s.Transcriber = transcriber
+	})
+	api.AddFile("voice-1", []byte("OggS"))
+	api.SendVoice(testUser.ID, testUser, "voice-1")
+
+	sent, err := api.WaitCalls("sendMessage", 2, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sent[0].Params.Get("text"); got != "🎙 <i>Можно ли мне кофе?</i>" {
+		t.Errorf("transcript echo = %q", got)
+	}
+	if got := sent[1].Params.Get("text"); got != "question: Можно ли мне кофе?" {
+		t.Errorf("sent text = %q", got)
+	}
+}
+
+func TestServerVoiceMessageFailed(t *testing.T) {
+	transcriber := transcriberFunc(func(ctx context.Context, audio io.Reader, filename string) (string, error) {
+		return "", errors.New("no speech")
+	})
+
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		t.Errorf("handler called with %v", r.Incoming.Content)
+	})
+
+	api := startServer(t, h, &llm.Mock{}, func(s *telegram.Server) {
+		s.Transcriber = transcriber
+		s.TranscriptionFailedResponse = func(user chat.User) chat.Message {
+			return chat.MsgA("не удалось распознать: " + user.FirstName)
+		}
+	})
+	api.AddFile("voice-1", []byte("OggS"))
+	api.SendVoice(testUser.ID, testUser, "voice-1")
+
+	sent, err := api.WaitCalls("sendMessage", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sent[0].Params.Get("text"); got != "не удалось распознать: Иван" {
+		t.Errorf("sent text = %q", got)
+	}
+}
+
+func TestServerPhotoMessage(t *testing.T) {
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		photo, ok := r.Incoming.Content.(content.Photo)
+		if !ok {
+			t.Errorf("incoming content = %T, want content.Photo", r.Incoming.Content)
+			return
+		}
+
+		w.WriteResponse(chat.MsgAf("photo: %s %s %s", photo.Data, photo.MIME, photo.Caption))
+	})
+
+	api := startServer(t, h, &llm.Mock{})
+	api.AddFile("photo-1", []byte("JPEG"))
+	api.SendPhoto(testUser.ID, testUser, "photo-1", "Обед")
+
+	sent, err := api.WaitCalls("sendMessage", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The largest size is downloaded.
+	if got := sent[0].Params.Get("text"); got != "photo: JPEG image/jpeg Обед" {
+		t.Errorf("sent text = %q", got)
+	}
+}
+
+func TestServerSendVoice(t *testing.T) {
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		w.WriteResponse(chat.MsgA(content.Voice{Data: []byte("OggS"), Caption: "🔊 1/2"}))
+	})
+
+	api := startServer(t, h, &llm.Mock{})
+	api.SendText(testUser.ID, testUser, "/start")
+
+	sent, err := api.WaitCalls("sendVoice", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(sent[0].Files["voice"]); got != "OggS" {
+		t.Errorf("uploaded voice = %q", got)
+	}
+	if got := sent[0].Params.Get("caption"); got != "🔊 1/2" {
+		t.Errorf("caption = %q", got)
+	}
+}
+
+func TestServerSendDocument(t *testing.T) {
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		w.WriteResponse(chat.MsgA(content.Document{
+			Name:    "report.md",
+			MIME:    "text/markdown",
+			Data:    []byte("# Report"),
+			Caption: "📄 Report",
+		}))
+	})
+
+	api := startServer(t, h, &llm.Mock{})
+	api.SendText(testUser.ID, testUser, "/start")
+
+	sent, err := api.WaitCalls("sendDocument", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(sent[0].Files["document"]); got != "# Report" {
+		t.Errorf("uploaded document = %q", got)
+	}
+	if got := sent[0].Params.Get("caption"); got != "📄 Report" {
+		t.Errorf("caption = %q", got)
+	}
+}
+
+func TestServerNotify(t *testing.T) {
+	if err := (&telegram.Server{}).Notify(t.Context(), testUser.ID, chat.MsgA("⏰")); !errors.Is(err, server.ErrNotifierUnavailable) {
+		t.Fatalf("Notify before start error = %v, want %v", err, server.ErrNotifierUnavailable)
+	}
+
+	var srv *telegram.Server
+	api := startServer(t, selectHandler(), &llm.Mock{}, func(s *telegram.Server) { srv = s })
+
+	// The bot is created asynchronously.
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		err := srv.Notify(t.Context(), testUser.ID, chat.MsgA("⏰ Напоминание"))
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, server.ErrNotifierUnavailable) || time.Now().After(deadline) {
+			t.Fatalf("Notify: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sent, err := api.WaitCalls("sendMessage", 1, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sent[0].Params.Get("chat_id"); got != strconv.FormatInt(testUser.ID, 10) {
+		t.Errorf("chat_id = %q", got)
+	}
+	if got := sent[0].Params.Get("text"); got != "⏰ Напоминание" {
+		t.Errorf("sent text = %q", got)
+	}
+}
@@ -0,0 +1,484 @@
+// Package telegramtest provides a fake Telegram Bot API server for tests.
+package telegramtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// BotUser is the bot account returned by getMe.
+var BotUser = tgbotapi.User{
+	ID:        1,
+	IsBot:     true,
+	FirstName: "Test",
+	UserName:  "test_bot",
+}
+
+// maxPollWait limits how long getUpdates waits for new updates.
+const maxPollWait = 200 * time.Millisecond
+
+// Call is a recorded Bot API request.
+type Call struct {
+	Method string
+	Params url.Values
+	Files  map[string][]byte // Uploaded files by field name.
+}
+
+// Server is a fake Bot API: it records outgoing calls and serves injected updates.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	calls         []Call
+	updates       []tgbotapi.Update
+	messages      map[int]tgbotapi.Message
+	files         map[string][]byte
+	nextUpdateID  int
+	nextMessageID int
+	changed       chan struct{}
+}
+
+// NewServer starts a fake Bot API server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		messages:      make(map[int]tgbotapi.Message),
+		files:         make(map[string][]byte),
+		nextUpdateID:  1,
+		nextMessageID: 1,
+		changed:       make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// APIEndpoint returns the endpoint format for tgbotapi.NewBotAPIWithAPIEndpoint.
+func (s *Server) APIEndpoint() string {
+	return s.URL + "/bot%s/%s"
+}
+
+// FileEndpoint returns the file download endpoint format for telegram.Server.
+func (s *Server) FileEndpoint() string {
+	return s.URL + "/file/bot%s/%s"
+}
+
+// AddFile makes a file available via getFile and the file endpoint.
+func (s *Server) AddFile(fileID string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[fileID] = data
+}
+
+// SendUpdate queues an update for getUpdates and returns its ID.
+func (s *Server) SendUpdate(u tgbotapi.Update) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u.UpdateID = s.nextUpdateID
+	s.nextUpdateID++
+	s.updates = append(s.updates, u)
+	s.notifyLocked()
+
+	return u.UpdateID
+}
+
+// SendText injects a text message from the user. Text starting with "/"
+// is marked as a bot command.
+func (s *Server) SendText(chatID int64, from tgbotapi.User, text string) int {
+	msg := s.newMessage(chatID, &from, text)
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		msg.Entities = []tgbotapi.MessageEntity{{
+			Type:   "bot_command",
+			Offset: 0,
+			Length: len(command),
+		}}
+	}
+
+	return s.SendUpdate(tgbotapi.Update{Message: msg})
+}
+
+// SendVoice injects a voice message from the user. The audio must be
+// added with AddFile.
+func (s *Server) SendVoice(chatID int64, from tgbotapi.User, fileID string) int {
+	msg := s.newMessage(chatID, &from, "")
+	msg.Voice = &tgbotapi.Voice{
+		FileID:   fileID,
+		Duration: 1,
+		MimeType: "audio/ogg",
+	}
+
+	return s.SendUpdate(tgbotapi.Update{Message: msg})
+}
+
+// SendPhoto injects a photo message from the user. The image must be
+// added with AddFile.
+func (s *Server) SendPhoto(chatID int64, from tgbotapi.User, fileID, caption string) int {
+	msg := s.newMessage(chatID, &from, "")
+	msg.Caption = caption
+	msg.Photo = []tgbotapi.PhotoSize{
+		{FileID: fileID + "-small", Width: 90, Height: 67},
+		{FileID: fileID, Width: 1280, Height: 960},
+	}
+
+	return s.SendUpdate(tgbotapi.Update{Message: msg})
+}
+
+// PressButton injects a callback query for an inline button of a message
+// previously sent by the bot.
+func (s *Server) PressButton(from tgbotapi.User, messageID int, data string) (int, error) {
+	s.mu.Lock()
+	msg, ok := s.messages[messageID]
+	s.mu.Unlock()
+
+	if !ok {
+		return 0, fmt.Errorf("message %d not found", messageID)
+	}
+
+	return s.SendUpdate(tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      strconv.Itoa(messageID) + ":" + data,
+			From:    &from,
+			Message: &msg,
+			Data:    data,
+		},
+	}), nil
+}
+
+// Calls returns recorded calls, optionally filtered by method.
+func (s *Server) Calls(methods ...string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Call
+	for _, c := range s.calls {
+		if len(methods) == 0 || contains(methods, c.Method) {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+// WaitCalls waits until at least n calls of the given method were recorded.
+func (s *Server) WaitCalls(method string, n int, timeout time.Duration) ([]Call, error) {
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+
+		if calls := s.Calls(method); len(calls) >= n {
+			return calls, nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return s.Calls(method), fmt.Errorf(
+				"timeout waiting for %d %s calls, got %d",
+				n, method, len(s.Calls(method)),
+			)
+		}
+	}
+}
+
+// Message returns a message sent or edited by the bot.
+func (s *Server) Message(messageID int) (tgbotapi.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, ok := s.messages[messageID]
+	return msg, ok
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	// Path: /file/bot<token>/<file path>
+	if strings.HasPrefix(r.URL.Path, "/file/") {
+		s.download(w, r)
+		return
+	}
+
+	// Path: /bot<token>/<method>
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "bot") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	files, err := parseRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	method := parts[1]
+	if method != "getUpdates" {
+		// Calls are recorded after handling, so waiters see their effects.
+		defer s.record(Call{Method: method, Params: r.PostForm, Files: files})
+	}
+
+	switch method {
+	case "getMe":
+		writeResult(w, BotUser)
+
+	case "getUpdates":
+		writeResult(w, s.poll(r.PostForm))
+
+	case "sendMessage":
+		// Like Telegram, messages must have text.
+		if strings.TrimSpace(r.PostForm.Get("text")) == "" {
+			writeError(w, http.StatusBadRequest, "Bad Request: message text is empty")
+			return
+		}
+
+		chatID, _ := strconv.ParseInt(r.PostForm.Get("chat_id"), 10, 64)
+		msg := s.newMessage(chatID, &BotUser, r.PostForm.Get("text"))
+		msg.ReplyMarkup = parseMarkup(r.PostForm.Get("reply_markup"))
+		s.store(*msg)
+		writeResult(w, msg)
+
+	case "sendVoice":
+		chatID, _ := strconv.ParseInt(r.PostForm.Get("chat_id"), 10, 64)
+		msg := s.newMessage(chatID, &BotUser, "")
+		msg.Caption = r.PostForm.Get("caption")
+		msg.Voice = &tgbotapi.Voice{
+			FileID:   "voice-" + strconv.Itoa(msg.MessageID),
+			FileSize: len(files["voice"]),
+		}
+		s.store(*msg)
+		writeResult(w, msg)
+
+	case "sendDocument":
+		chatID, _ := strconv.ParseInt(r.PostForm.Get("chat_id"), 10, 64)
+		msg := s.newMessage(chatID, &BotUser, "")
+		msg.Caption = r.PostForm.Get("caption")
+		msg.Document = &tgbotapi.Document{
+			FileID:   "document-" + strconv.Itoa(msg.MessageID),
+			FileName: uploadName(r, "document"),
+			FileSize: len(files["document"]),
+		}
+		s.store(*msg)
+		writeResult(w, msg)
+
+	case "editMessageText":
+		messageID, _ := strconv.Atoi(r.PostForm.Get("message_id"))
+		msg, ok := s.Message(messageID)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Bad Request: message to edit not found")
+			return
+		}
+		msg.Text = r.PostForm.Get("text")
+		msg.ReplyMarkup = parseMarkup(r.PostForm.Get("reply_markup"))
+		s.store(msg)
+		writeResult(w, msg)
+
+	case "editMessageReplyMarkup":
+		messageID, _ := strconv.Atoi(r.PostForm.Get("message_id"))
+		msg, ok := s.Message(messageID)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Bad Request: message to edit not found")
+			return
+		}
+		msg.ReplyMarkup = parseMarkup(r.PostForm.Get("reply_markup"))
+		s.store(msg)
+		writeResult(w, msg)
+
+	case "getFile":
+		fileID := r.PostForm.Get("file_id")
+
+		s.mu.Lock()
+		data, ok := s.files[fileID]
+		s.mu.Unlock()
+
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Bad Request: invalid file_id")
+			return
+		}
+		writeResult(w, tgbotapi.File{
+			FileID:   fileID,
+			FileSize: len(data),
+			FilePath: "files/" + fileID,
+		})
+
+	case "setMyCommands", "sendChatAction", "answerCallbackQuery":
+		writeResult(w, true)
+
+	default:
+		writeError(w, http.StatusNotFound, "Not Found: method "+method)
+	}
+}
+
+// download serves files added with AddFile.
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	_, filePath, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/file/"), "/")
+
+	s.mu.Lock()
+	data, ok := s.files[strings.TrimPrefix(filePath, "files/")]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Write(data)
+}
+
+// poll returns updates starting from offset, waiting briefly if there are none.
+func (s *Server) poll(params url.Values) []tgbotapi.Update {
+	offset, _ := strconv.Atoi(params.Get("offset"))
+	deadline := time.After(maxPollWait)
+
+	for {
+		s.mu.Lock()
+		var out []tgbotapi.Update
+		for _, u := range s.updates {
+			if u.UpdateID >= offset {
+				out = append(out, u)
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		if len(out) > 0 {
+			return out
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return []tgbotapi.Update{}
+		}
+	}
+}
+
+func (s *Server) record(c Call) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, c)
+	s.notifyLocked()
+}
+
+func (s *Server) store(msg tgbotapi.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages[msg.MessageID] = msg
+	s.notifyLocked()
+}
+
+func (s *Server) newMessage(chatID int64, from *tgbotapi.User, text string) *tgbotapi.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := &tgbotapi.Message{
+		MessageID: s.nextMessageID,
+		From:      from,
+		Date:      int(time.Now().Unix()),
+		Chat:      &tgbotapi.Chat{ID: chatID, Type: "private"},
+		Text:      text,
+	}
+	s.nextMessageID++
+
+	return msg
+}
+
+// notifyLocked wakes up waiters. Must be called with mu held.
+func (s *Server) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// parseRequest parses form values into r.PostForm and returns uploaded files.
+func parseRequest(r *http.Request) (map[string][]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return nil, r.ParseForm()
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(r.MultipartForm.File))
+	for field, headers := range r.MultipartForm.File {
+		f, err := headers[0].Open()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		files[field] = data
+	}
+
+	return files, nil
+}
+
+// uploadName returns the file name of an uploaded multipart file.
+func uploadName(r *http.Request, field string) string {
+	if r.MultipartForm == nil || len(r.MultipartForm.File[field]) == 0 {
+		return ""
+	}
+
+	return r.MultipartForm.File[field][0].Filename
+}
+
+func parseMarkup(raw string) *tgbotapi.InlineKeyboardMarkup {
+	if raw == "" {
+		return nil
+	}
+
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(raw), &markup); err != nil || markup.InlineKeyboard == nil {
+		return nil
+	}
+
+	return &markup
+}
+
+func writeResult(w http.ResponseWriter, result any) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: data})
+}
+
+func writeError(w http.ResponseWriter, code int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{
+		Ok:          false,
+		ErrorCode:   code,
+		Description: description,
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/muzykantov/health-gpt/server"
+)
+
+// audioFile is a voice note or an audio file attached to a message.
+type audioFile struct {
+	ID   string
+	Name string // File name with an extension telling the audio format.
+}
+
+// messageAudio returns the audio attached to the message, if any.
+func messageAudio(m *tgbotapi.Message) *audioFile {
+	switch {
+	case m.Voice != nil:
+		// Voice notes are always OGG/Opus.
+		return &audioFile{ID: m.Voice.FileID, Name: "voice.ogg"}
+
+	case m.Audio != nil:
+		name := m.Audio.FileName
+		if name == "" {
+			ext := ".mp3"
+			if exts, _ := mime.ExtensionsByType(m.Audio.MimeType); len(exts) > 0 {
+				ext = exts[0]
+			}
+			name = "audio" + ext
+		}
+		return &audioFile{ID: m.Audio.FileID, Name: name}
+
+	default:
+		return nil
+	}
+}
+
+// download downloads the file from Telegram. The caller closes the body.
+func download(
+	ctx context.Context,
+	bot *tgbotapi.BotAPI,
+	fileEndpoint string,
+	fileID string,
+) (io.ReadCloser, error) {
+	file, err := bot.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("get file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf(fileEndpoint, bot.Token, file.FilePath),
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("download file: %w", err)
+	}
+
+	resp, err := bot.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download file: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download file: unexpected status %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// transcribe downloads the audio from Telegram and converts it to text.
+func transcribe(
+	ctx context.Context,
+	bot *tgbotapi.BotAPI,
+	fileEndpoint string,
+	transcriber server.Transcriber,
+	audio *audioFile,
+) (string, error) {
+	body, err := download(ctx, bot, fileEndpoint, audio.ID)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	text, err := transcriber.Transcribe(ctx, body, audio.Name)
+	if err != nil {
+		return "", fmt.Errorf("transcribe: %w", err)
+	}
+
+	return text, nil
+}
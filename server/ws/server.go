@@ -0,0 +1,377 @@
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/server"
+	"github.com/muzykantov/health-gpt/server/httpapi"
+	"golang.org/x/net/websocket"
+)
+
+// Main WebSocket transport errors
+var (
+	ErrHandlerNotProvided   = errors.New("websocket handler not provided")
+	ErrStorageNotProvided   = errors.New("websocket storage not provided")
+	ErrCompleterNotProvided = errors.New("websocket llm not provided")
+	ErrInvalidFrame         = errors.New("websocket invalid frame")
+	ErrUnauthorized         = errors.New("websocket unauthorized")
+)
+
+// Event types specific to the WebSocket transport.
+const (
+	EventSession = "session" // Session token, sent first after connecting.
+	EventDone    = "done"    // Handler finished processing an inbound frame.
+	EventError   = "error"   // Inbound frame was rejected.
+)
+
+// Frame types accepted from clients.
+const (
+	FrameText    = "text"
+	FrameSelect  = "select"
+	FrameCommand = "command"
+)
+
+// Default request cache TTL.
+const defaultCacheTTL = time.Hour * 24
+
+// Session tokens are random hex strings. Shorter tokens from clients are
+// rejected, so that sessions cannot be guessed.
+const (
+	sessionTokenSize = 16
+	minSessionToken  = 2 * sessionTokenSize
+	maxSessionToken  = 128
+)
+
+// Server keeps a WebSocket per session and streams every handler response
+// (including typing indicators) to the client as httpapi.Event JSON objects.
+//
+// Clients connect to /v1/ws and receive {"type": "session", "text": "..."}
+// with a session token. The chat of the session is derived from the token
+// (see httpapi.ChatID), so it never overlaps Telegram chats; to continue the
+// chat after reconnecting, clients connect to /v1/ws?session=TOKEN.
+//
+// Server clients authorize with one of APIKeys in the X-API-Key header.
+// Browsers cannot set headers, so connections without a key are accepted
+// only from AllowedOrigins (the sites embedding the chat widget).
+//
+// Clients send frames like:
+//
+//	{"type": "text", "text": "..."}
+//	{"type": "select", "data": "...", "caption": "..."}
+//	{"type": "command", "name": "...", "args": "..."}
+//
+// After a frame is processed the server sends {"type": "done", "id": "..."}
+// where id is the frame ID (if provided by the client).
+type Server struct {
+	Addr           string
+	APIKeys        []string
+	AllowedOrigins []string // Origins of web pages allowed to connect without a key.
+	Handler        server.Handler
+	Completion     server.ChatCompleter
+	Storage        server.DataStorage
+	Cache          server.Cache
+	Log            *log.Logger
+
+	// Format is the markup of reports sent to clients (HTML by default).
+	Format server.Format
+
+	initOnce sync.Once
+	cache    server.Cache
+	logger   *log.Logger
+}
+
+// Frame is an inbound client message.
+type Frame struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Text    string `json:"text,omitempty"`
+	Caption string `json:"caption,omitempty"`
+	Data    string `json:"data,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Args    string `json:"args,omitempty"`
+}
+
+// message converts the frame into an incoming chat message.
+func (f Frame) message() (chat.Message, error) {
+	switch f.Type {
+	case FrameText:
+		if f.Text == "" {
+			return chat.EmptyMessage, fmt.Errorf("%w: empty text", ErrInvalidFrame)
+		}
+		return chat.MsgU(f.Text), nil
+
+	case FrameSelect:
+		if f.Data == "" {
+			return chat.EmptyMessage, fmt.Errorf("%w: empty data", ErrInvalidFrame)
+		}
+		return chat.MsgU(content.SelectItem{Caption: f.Caption, Data: f.Data}), nil
+
+	case FrameCommand:
+		name := strings.TrimPrefix(f.Name, "/")
+		if name == "" {
+			return chat.EmptyMessage, fmt.Errorf("%w: empty command", ErrInvalidFrame)
+		}
+		return chat.MsgU(content.Command{Name: name, Args: f.Args}), nil
+
+	default:
+		return chat.EmptyMessage, fmt.Errorf("%w: unknown type %q", ErrInvalidFrame, f.Type)
+	}
+}
+
+// ListenAndServe starts the WebSocket server and stops it when ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/ws", s)
+
+	srv := &http.Server{
+		Addr:              s.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Printf("Starting WebSocket server on %s", s.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("websocket server: %w", err)
+
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.logger.Printf("failed to shutdown websocket server: %v", err)
+		}
+
+		return ctx.Err()
+	}
+}
+
+// ServeHTTP authorizes the client and upgrades the connection.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	token := r.URL.Query().Get("session")
+	switch {
+	case token == "":
+		token = newSessionToken()
+	case len(token) < minSessionToken || len(token) > maxSessionToken:
+		http.Error(w, "invalid session", http.StatusBadRequest)
+		return
+	}
+
+	websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			return s.handshake(r)
+		},
+		Handler: func(conn *websocket.Conn) {
+			s.session(conn, token)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// handshake authorizes the connection: by the API key in the header or,
+// for browsers, by the origin of the page.
+func (s *Server) handshake(r *http.Request) error {
+	if s.authorized(r.Header.Get("X-API-Key")) {
+		return nil
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin != "" && slices.Contains(s.AllowedOrigins, origin) {
+		return nil
+	}
+
+	s.logger.Printf("rejected websocket connection (origin: %q)", origin)
+	return ErrUnauthorized
+}
+
+// newSessionToken returns a random session token.
+func newSessionToken() string {
+	b := make([]byte, sessionTokenSize)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validate checks required fields and initializes defaults.
+func (s *Server) validate() error {
+	if s.Handler == nil {
+		return ErrHandlerNotProvided
+	}
+
+	if s.Storage == nil {
+		return ErrStorageNotProvided
+	}
+
+	if s.Completion == nil {
+		return ErrCompleterNotProvided
+	}
+
+	s.initOnce.Do(func() {
+		s.cache = s.Cache
+		if s.cache == nil {
+			s.cache = expirable.NewLRU[string, any](0, nil, defaultCacheTTL)
+		}
+
+		s.logger = s.Log
+		if s.logger == nil {
+			s.logger = log.Default()
+		}
+	})
+
+	return nil
+}
+
+// authorized checks the API key.
+func (s *Server) authorized(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, allowed := range s.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// session reads frames until the client disconnects. Each frame is processed
+// in its own goroutine, like updates in the Telegram transport.
+func (s *Server) session(conn *websocket.Conn, token string) {
+	ctx, cancel := context.WithCancel(conn.Request().Context())
+	defer cancel()
+
+	chatID := httpapi.ChatID(EventSession, token)
+
+	w := &socketWriter{conn: conn}
+	if err := w.send(httpapi.Event{Type: EventSession, Text: token}); err != nil {
+		s.logger.Printf("failed to send session (chatID: %d): %v", chatID, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		var raw string
+		if err := websocket.Message.Receive(conn, &raw); err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.logger.Printf("failed to read frame (chatID: %d): %v", chatID, err)
+			}
+			return
+		}
+
+		var f Frame
+		if err := json.Unmarshal([]byte(raw), &f); err != nil {
+			w.send(httpapi.Event{Type: EventError, Text: fmt.Sprintf("%v: %v", ErrInvalidFrame, err)})
+			continue
+		}
+
+		incoming, err := f.message()
+		if err != nil {
+			w.send(httpapi.Event{Type: EventError, ID: f.ID, Text: err.Error()})
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.Printf("recovered from panic: %v", r)
+				}
+			}()
+
+			s.serve(ctx, w, chatID, incoming)
+			w.send(httpapi.Event{Type: EventDone, ID: f.ID})
+		}()
+	}
+}
+
+// serve runs the handler pipeline for a single inbound frame.
+func (s *Server) serve(ctx context.Context, w *socketWriter, chatID int64, incoming chat.Message) {
+	from, err := s.Storage.GetUser(ctx, chatID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			s.logger.Printf("failed to get user (chatID: %d): %v", chatID, err)
+			w.send(httpapi.Event{Type: EventError, Text: "failed to get user"})
+			return
+		}
+
+		from = chat.User{ID: chatID}
+	}
+
+	from.LanguageCode = w.conn.Request().Header.Get("Accept-Language")
+
+	s.Handler.Serve(ctx, w, &server.Request{
+		ChatID:   chatID,
+		Incoming: incoming,
+		From:     from,
+		Format:   s.Format,
+
+		Transport: server.TransportWebSocket,
+
+		Completer: s.Completion,
+		Storage:   s.Storage,
+		Cache:     s.cache,
+		Log:       s.logger,
+	})
+}
+
+// socketWriter streams handler responses to the socket.
+type socketWriter struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (w *socketWriter) WriteResponse(m chat.Message) error {
+	if m.IsEmpty() {
+		return nil
+	}
+
+	e, err := httpapi.NewEvent(m)
+	if err != nil {
+		return err
+	}
+
+	return w.send(e)
+}
+
+func (w *socketWriter) send(e httpapi.Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return websocket.JSON.Send(w.conn, e)
+}
@@ -0,0 +1,185 @@
+package ws
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muzykantov/health-gpt/chat"
+	"github.com/muzykantov/health-gpt/chat/content"
+	"github.com/muzykantov/health-gpt/chat/storage"
+	"github.com/muzykantov/health-gpt/llm"
+	"github.com/muzykantov/health-gpt/server"
+	"github.com/muzykantov/health-gpt/server/httpapi"
+	"golang.org/x/net/websocket"
+)
+
+// dial connects to the server with the API key header (if set) and the
+// origin, and returns the session token.
+func dial(t *testing.T, srv *httptest.Server, query, key, origin string) (*websocket.Conn, string) {
+	t.Helper()
+
+	config, err := websocket.NewConfig("ws"+strings.TrimPrefix(srv.URL, "http")+"/v1/ws"+query, origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		config.Header.Set("X-API-Key", key)
+	}
+
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var session httpapi.Event
+	if err := websocket.JSON.Receive(conn, &session); err != nil {
+		t.Fatal(err)
+	}
+	if session.Type != EventSession || len(session.Text) < minSessionToken {
+		t.Fatalf("event = %+v, want session", session)
+	}
+
+	return conn, session.Text
+}
+
+func newTestServer(t *testing.T, h server.Handler) *httptest.Server {
+	t.Helper()
+
+	fs, err := storage.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(&Server{
+		APIKeys:        []string{"secret"},
+		AllowedOrigins: []string{"https://example.com"},
+		Handler:        h,
+		Completion:     &llm.Mock{},
+		Storage:        fs,
+	})
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestServerStreamsResponses(t *testing.T) {
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		w.WriteResponse(chat.MsgA(content.Typing{}))
+		w.WriteResponse(chat.MsgAf("api chat %v: %v", httpapi.IsChatID(r.ChatID), r.Incoming.Content))
+	})
+
+	srv := newTestServer(t, h)
+	conn, _ := dial(t, srv, "", "secret", srv.URL)
+
+	if err := websocket.JSON.Send(conn, Frame{ID: "1", Type: FrameText, Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []httpapi.Event{
+		{Type: httpapi.EventTyping},
+		{Type: httpapi.EventText, Text: "api chat true: hi"},
+		{Type: EventDone, ID: "1"},
+	}
+	for _, w := range want {
+		var got httpapi.Event
+		if err := websocket.JSON.Receive(conn, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Type != w.Type || got.Text != w.Text || (w.ID != "" && got.ID != w.ID) {
+			t.Fatalf("event = %+v, want %+v", got, w)
+		}
+	}
+
+	if err := websocket.JSON.Send(conn, Frame{Type: "unknown"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got httpapi.Event
+	if err := websocket.JSON.Receive(conn, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != EventError {
+		t.Fatalf("event = %+v, want error", got)
+	}
+}
+
+func TestServerSessions(t *testing.T) {
+	h := server.HandlerFunc(func(ctx context.Context, w server.ResponseWriter, r *server.Request) {
+		w.WriteResponse(chat.MsgAf("%d", r.ChatID))
+	})
+
+	srv := newTestServer(t, h)
+
+	chatID := func(conn *websocket.Conn) string {
+		t.Helper()
+
+		if err := websocket.JSON.Send(conn, Frame{Type: FrameText, Text: "hi"}); err != nil {
+			t.Fatal(err)
+		}
+
+		var got, done httpapi.Event
+		if err := websocket.JSON.Receive(conn, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := websocket.JSON.Receive(conn, &done); err != nil || done.Type != EventDone {
+			t.Fatalf("event = %+v, %v, want done", done, err)
+		}
+
+		return got.Text
+	}
+
+	// Browsers connect without a key from allowed origins.
+	first, token := dial(t, srv, "", "", "https://example.com")
+	second, _ := dial(t, srv, "", "", "https://example.com")
+	resumed, resumedToken := dial(t, srv, "?session="+token, "", "https://example.com")
+
+	if resumedToken != token {
+		t.Errorf("resumed session = %q, want %q", resumedToken, token)
+	}
+	firstChat := chatID(first)
+	if got := chatID(second); got == firstChat {
+		t.Errorf("sessions share chat %s", got)
+	}
+	if got := chatID(resumed); got != firstChat {
+		t.Errorf("resumed chat = %s, want %s", got, firstChat)
+	}
+}
+
+func TestServerRejectsUnauthorized(t *testing.T) {
+	srv := newTestServer(t, server.HandlerFunc(func(context.Context, server.ResponseWriter, *server.Request) {}))
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/ws"
+	for name, dial := range map[string]func() error{
+		"invalid key": func() error {
+			config, _ := websocket.NewConfig(url, srv.URL)
+			config.Header.Set("X-API-Key", "wrong")
+			_, err := websocket.DialConfig(config)
+			return err
+		},
+		"key in query": func() error {
+			_, err := websocket.Dial(url+"?key=secret", "", srv.URL)
+			return err
+		},
+		"foreign origin": func() error {
+			_, err := websocket.Dial(url, "", "https://evil.example")
+			return err
+		},
+		"short session": func() error {
+			config, _ := websocket.NewConfig(url+"?session=42", srv.URL)
+			config.Header.Set("X-API-Key", "secret")
+			_, err := websocket.DialConfig(config)
+			return err
+		},
+	} {
+		if err := dial(); err == nil {
+			t.Errorf("%s: dial succeeded", name)
+		}
+	}
+}
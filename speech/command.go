@@ -0,0 +1,51 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Command synthesizes speech with a local program, e.g. Piper piped into
+// ffmpeg. The text is written to the program's stdin, the audio is read
+// from its stdout and must be OGG/Opus to be sent as a Telegram voice note:
+//
+//	sh -c "piper --model ru_RU-irina-medium.onnx --output_file - |
+//	       ffmpeg -loglevel error -i - -c:a libopus -f ogg -"
+type Command struct {
+	Name string
+	Args []string
+}
+
+// NewCommand creates a synthesizer running the program with the arguments.
+func NewCommand(name string, args ...string) *Command {
+	return &Command{Name: name, Args: args}
+}
+
+// Synthesize converts text to speech.
+func (c *Command) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf(
+			"%w: %s: %w: %s",
+			ErrSynthesisFailed,
+			c.Name,
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("%w: %s: no audio", ErrSynthesisFailed, c.Name)
+	}
+
+	return stdout.Bytes(), nil
+}
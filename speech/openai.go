@@ -0,0 +1,117 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// OpenAIOption defines a configuration function for the client.
+type OpenAIOption func(*OpenAI)
+
+// OpenAI transcribes audio with the OpenAI speech-to-text API (Whisper).
+type OpenAI struct {
+	client openai.Client
+
+	// Configuration parameters.
+	model      openai.AudioModel // Model to use.
+	language   string            // ISO-639-1 language of the audio, detected if empty.
+	socksProxy string            // SOCKS proxy address.
+	baseURL    string            // Base API URL.
+}
+
+// OpenAIWithModel sets the model to use.
+func OpenAIWithModel(model string) OpenAIOption {
+	return func(c *OpenAI) {
+		if model != "" {
+			c.model = model
+		}
+	}
+}
+
+// OpenAIWithLanguage sets the language of the audio.
+func OpenAIWithLanguage(language string) OpenAIOption {
+	return func(c *OpenAI) {
+		if language != "" {
+			c.language = language
+		}
+	}
+}
+
+// OpenAIWithSocksProxy sets the SOCKS proxy.
+func OpenAIWithSocksProxy(socksProxy string) OpenAIOption {
+	return func(c *OpenAI) {
+		if socksProxy != "" {
+			c.socksProxy = socksProxy
+		}
+	}
+}
+
+// OpenAIWithBaseURL sets the base API URL.
+func OpenAIWithBaseURL(baseURL string) OpenAIOption {
+	return func(c *OpenAI) {
+		if baseURL != "" {
+			c.baseURL = baseURL
+		}
+	}
+}
+
+// NewOpenAI creates a new client instance with the given options.
+func NewOpenAI(apiKey string, opts ...OpenAIOption) (*OpenAI, error) {
+	c := &OpenAI{
+		model: openai.AudioModelWhisper1,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	openaiOpts := []option.RequestOption{
+		option.WithAPIKey(apiKey),
+	}
+
+	if c.baseURL != "" {
+		openaiOpts = append(openaiOpts, option.WithBaseURL(c.baseURL))
+	}
+
+	if c.socksProxy != "" {
+		client, err := socksClient(c.socksProxy)
+		if err != nil {
+			return nil, err
+		}
+
+		openaiOpts = append(openaiOpts, option.WithHTTPClient(client))
+	}
+
+	c.client = openai.NewClient(openaiOpts...)
+
+	return c, nil
+}
+
+// Transcribe converts speech to text. The file name extension tells the API
+// the audio format (e.g. voice.ogg).
+func (c *OpenAI) Transcribe(ctx context.Context, audio io.Reader, filename string) (string, error) {
+	params := openai.AudioTranscriptionNewParams{
+		File:  openai.File(audio, filename, ""),
+		Model: c.model,
+	}
+	if c.language != "" {
+		params.Language = openai.String(c.language)
+	}
+
+	transcription, err := c.client.Audio.Transcriptions.New(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("%w: openai request failed: %w", ErrTranscriptionFailed, err)
+	}
+
+	text := strings.TrimSpace(transcription.Text)
+	if text == "" {
+		return "", ErrEmptyTranscript
+	}
+
+	return text, nil
+}
@@ -0,0 +1,115 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// OpenAITTSOption defines a configuration function for the client.
+type OpenAITTSOption func(*OpenAITTS)
+
+// OpenAITTS synthesizes speech with the OpenAI text-to-speech API.
+// Audio is returned as OGG/Opus, the format of Telegram voice notes.
+type OpenAITTS struct {
+	client openai.Client
+
+	// Configuration parameters.
+	model      openai.SpeechModel               // Model to use.
+	voice      openai.AudioSpeechNewParamsVoice // Voice to use.
+	socksProxy string                           // SOCKS proxy address.
+	baseURL    string                           // Base API URL.
+}
+
+// OpenAITTSWithModel sets the model to use.
+func OpenAITTSWithModel(model string) OpenAITTSOption {
+	return func(c *OpenAITTS) {
+		if model != "" {
+			c.model = model
+		}
+	}
+}
+
+// OpenAITTSWithVoice sets the voice to use.
+func OpenAITTSWithVoice(voice string) OpenAITTSOption {
+	return func(c *OpenAITTS) {
+		if voice != "" {
+			c.voice = openai.AudioSpeechNewParamsVoice(voice)
+		}
+	}
+}
+
+// OpenAITTSWithSocksProxy sets the SOCKS proxy.
+func OpenAITTSWithSocksProxy(socksProxy string) OpenAITTSOption {
+	return func(c *OpenAITTS) {
+		if socksProxy != "" {
+			c.socksProxy = socksProxy
+		}
+	}
+}
+
+// OpenAITTSWithBaseURL sets the base API URL.
+func OpenAITTSWithBaseURL(baseURL string) OpenAITTSOption {
+	return func(c *OpenAITTS) {
+		if baseURL != "" {
+			c.baseURL = baseURL
+		}
+	}
+}
+
+// NewOpenAITTS creates a new client instance with the given options.
+func NewOpenAITTS(apiKey string, opts ...OpenAITTSOption) (*OpenAITTS, error) {
+	c := &OpenAITTS{
+		model: openai.SpeechModelTTS1,
+		voice: openai.AudioSpeechNewParamsVoiceAlloy,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	openaiOpts := []option.RequestOption{
+		option.WithAPIKey(apiKey),
+	}
+
+	if c.baseURL != "" {
+		openaiOpts = append(openaiOpts, option.WithBaseURL(c.baseURL))
+	}
+
+	if c.socksProxy != "" {
+		client, err := socksClient(c.socksProxy)
+		if err != nil {
+			return nil, err
+		}
+
+		openaiOpts = append(openaiOpts, option.WithHTTPClient(client))
+	}
+
+	c.client = openai.NewClient(openaiOpts...)
+
+	return c, nil
+}
+
+// Synthesize converts text to OGG/Opus speech.
+func (c *OpenAITTS) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	resp, err := c.client.Audio.Speech.New(ctx, openai.AudioSpeechNewParams{
+		Input:          text,
+		Model:          c.model,
+		Voice:          c.voice,
+		ResponseFormat: openai.AudioSpeechNewParamsResponseFormatOpus,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: openai request failed: %w", ErrSynthesisFailed, err)
+	}
+	defer resp.Body.Close()
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: read audio: %w", ErrSynthesisFailed, err)
+	}
+
+	return audio, nil
+}
@@ -0,0 +1,39 @@
+// Package speech provides speech-to-text clients used to transcribe voice
+// messages and text-to-speech clients used to answer with voice notes.
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/proxy"
+)
+
+// Speech errors.
+var (
+	ErrTranscriptionFailed = errors.New("transcription failed")
+	ErrEmptyTranscript     = errors.New("empty transcript")
+	ErrSynthesisFailed     = errors.New("synthesis failed")
+)
+
+// socksClient creates an HTTP client connecting through the SOCKS5 proxy.
+func socksClient(socksProxy string) (*http.Client, error) {
+	dialer, err := proxy.SOCKS5("tcp", socksProxy, nil, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SOCKS5 dialer: %w", err)
+	}
+
+	dialContext := func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialer.Dial(network, address)
+	}
+
+	transport := &http.Transport{
+		DialContext:       dialContext,
+		DisableKeepAlives: true,
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,168 @@
+package speech
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// transcriptionServer serves transcription requests, checking the uploaded file.
+func transcriptionServer(t *testing.T, path, response string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		data, _ := io.ReadAll(file)
+		if string(data) != "audio" || header.Filename != "voice.ogg" {
+			http.Error(w, "unexpected file", http.StatusBadRequest)
+			return
+		}
+
+		if lang := r.FormValue("language"); lang != "ru" {
+			http.Error(w, "unexpected language "+lang, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestWhisperCPPTranscribe(t *testing.T) {
+	srv := transcriptionServer(t, "/inference", `{"text":" Можно ли мне кофе? \n"}`)
+
+	c, err := NewWhisperCPP(srv.URL, WhisperCPPWithLanguage("ru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := c.Transcribe(context.Background(), strings.NewReader("audio"), "voice.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text != "Можно ли мне кофе?" {
+		t.Errorf("Transcribe() = %q", text)
+	}
+}
+
+func TestWhisperCPPErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     error
+	}{
+		{name: "empty", response: `{"text":"  "}`, want: ErrEmptyTranscript},
+		{name: "error", response: `{"error":"failed to read audio"}`, want: ErrTranscriptionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := transcriptionServer(t, "/inference", tt.response)
+
+			c, err := NewWhisperCPP(srv.URL, WhisperCPPWithLanguage("ru"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.Transcribe(context.Background(), strings.NewReader("audio"), "voice.ogg")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Transcribe() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAITranscribe(t *testing.T) {
+	srv := transcriptionServer(t, "/audio/transcriptions", `{"text":"Можно ли мне кофе?"}`)
+
+	c, err := NewOpenAI("test", OpenAIWithBaseURL(srv.URL+"/"), OpenAIWithLanguage("ru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := c.Transcribe(context.Background(), strings.NewReader("audio"), "voice.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text != "Можно ли мне кофе?" {
+		t.Errorf("Transcribe() = %q", text)
+	}
+}
+
+func TestOpenAITTSSynthesize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input          string `json:"input"`
+			Voice          string `json:"voice"`
+			ResponseFormat string `json:"response_format"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Path != "/audio/speech" || req.ResponseFormat != "opus" || req.Voice != "nova" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "audio/ogg")
+		io.WriteString(w, "OggS:"+req.Input)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewOpenAITTS("test", OpenAITTSWithBaseURL(srv.URL+"/"), OpenAITTSWithVoice("nova"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audio, err := c.Synthesize(context.Background(), "Привет")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(audio) != "OggS:Привет" {
+		t.Errorf("Synthesize() = %q", audio)
+	}
+}
+
+func TestCommandSynthesize(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat is not available")
+	}
+
+	audio, err := NewCommand("cat").Synthesize(context.Background(), "Привет")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(audio) != "Привет" {
+		t.Errorf("Synthesize() = %q", audio)
+	}
+
+	if _, err := NewCommand("false").Synthesize(context.Background(), "Привет"); !errors.Is(err, ErrSynthesisFailed) {
+		t.Errorf("Synthesize() error = %v, want %v", err, ErrSynthesisFailed)
+	}
+}
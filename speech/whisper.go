@@ -0,0 +1,145 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// whisperInferencePath is the transcription endpoint of the whisper.cpp server.
+const whisperInferencePath = "/inference"
+
+// WhisperCPPOption defines a configuration function for the client.
+type WhisperCPPOption func(*WhisperCPP)
+
+// WhisperCPP transcribes audio with a local whisper.cpp server
+// (examples/server) or any server compatible with its /inference endpoint.
+// Telegram voice notes are OGG/Opus, so the server must be started with
+// --convert to accept formats other than WAV.
+type WhisperCPP struct {
+	client *http.Client
+
+	// Configuration parameters.
+	endpoint string // Inference endpoint URL.
+	language string // Language of the audio, detected if empty.
+}
+
+// WhisperCPPWithLanguage sets the language of the audio.
+func WhisperCPPWithLanguage(language string) WhisperCPPOption {
+	return func(c *WhisperCPP) {
+		if language != "" {
+			c.language = language
+		}
+	}
+}
+
+// WhisperCPPWithHTTPClient sets the HTTP client.
+func WhisperCPPWithHTTPClient(client *http.Client) WhisperCPPOption {
+	return func(c *WhisperCPP) {
+		if client != nil {
+			c.client = client
+		}
+	}
+}
+
+// NewWhisperCPP creates a client for the server at baseURL, e.g.
+// http://localhost:8080. The /inference path is used unless baseURL has a path.
+func NewWhisperCPP(baseURL string, opts ...WhisperCPPOption) (*WhisperCPP, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid whisper.cpp url %q", baseURL)
+	}
+
+	if u.Path == "" || u.Path == "/" {
+		u.Path = whisperInferencePath
+	}
+
+	c := &WhisperCPP{
+		client:   http.DefaultClient,
+		endpoint: u.String(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// Transcribe converts speech to text.
+func (c *WhisperCPP) Transcribe(ctx context.Context, audio io.Reader, filename string) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	file, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTranscriptionFailed, err)
+	}
+	if _, err := io.Copy(file, audio); err != nil {
+		return "", fmt.Errorf("%w: read audio: %w", ErrTranscriptionFailed, err)
+	}
+
+	fields := map[string]string{
+		"response_format": "json",
+		"temperature":     "0.0",
+	}
+	if c.language != "" {
+		fields["language"] = c.language
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrTranscriptionFailed, err)
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTranscriptionFailed, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, &body)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTranscriptionFailed, err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: whisper.cpp request failed: %w", ErrTranscriptionFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf(
+			"%w: whisper.cpp status %d: %s",
+			ErrTranscriptionFailed,
+			resp.StatusCode,
+			strings.TrimSpace(string(msg)),
+		)
+	}
+
+	var result struct {
+		Text  string `json:"text"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("%w: decode response: %w", ErrTranscriptionFailed, err)
+	}
+
+	if result.Error != "" {
+		return "", fmt.Errorf("%w: whisper.cpp: %s", ErrTranscriptionFailed, result.Error)
+	}
+
+	text := strings.TrimSpace(result.Text)
+	if text == "" {
+		return "", ErrEmptyTranscript
+	}
+
+	return text, nil
+}